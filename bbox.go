@@ -93,8 +93,8 @@ func (e *Extent) Max() [2]float64 {
 	return [2]float64{e[2], e[3]}
 }
 
-// XSpan is the distance of the Extent in X or inf
-// TODO (gdey): look at how to have this function take into account the dpi.
+// XSpan is the distance of the Extent in X or inf. The distance is in
+// the units of the Extent; see slippy.Resolution for converting to pixels.
 func (e *Extent) XSpan() float64 {
 	if e == nil {
 		return math.Inf(1)
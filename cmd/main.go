@@ -12,8 +12,6 @@ import (
 
 	"github.com/hahaking119/geom/winding"
 
-	"github.com/hahaking119/geom/planar/clip"
-
 	"github.com/hahaking119/geom/cmp"
 	"github.com/hahaking119/geom/planar"
 
@@ -83,6 +81,16 @@ func (off *outfilefile) WriteWKTGeom(geos ...geom.Geometry) *outfilefile {
 	return off
 }
 
+// tileGeoms returns the geometries of the features of the tile, in pixels
+func tileGeoms(t *mvt.Tile) (geos []geom.Geometry) {
+	for _, l := range t.Layers() {
+		for _, f := range l.Features() {
+			geos = append(geos, f.Geometry)
+		}
+	}
+	return geos
+}
+
 func newOutFile(tile *slippy.Tile, tag string) outfile {
 	path := fmt.Sprintf("%v/%v/%v", tile.Z, tile.X, tile.Y)
 	if tag != "" {
@@ -144,16 +152,19 @@ func main() {
 	order := winding.Order{}
 	grid3857, _ := slippy.NewGrid(3857)
 
+	// size of a pixel of the mvt tile in meters
+	pixelSize, _ := slippy.Resolution(grid3857, tile.Z, *mvtExtent)
+
 	var clipRegion *geom.Extent
 	{
-		webs := slippy.PixelsToNative(grid3857, tile.Z, uint(*buffer))
+		webs := float64(*buffer) * pixelSize
 		ext, _ := slippy.Extent(grid3857, tile)
 		clipRegion = ext.ExpandBy(webs)
 	}
 
 	if *simplifyGeo {
 		simp := simplify.DouglasPeucker{
-			Tolerance: slippy.PixelsToNative(grid3857, tile.Z, 10.0),
+			Tolerance: 10.0 * pixelSize,
 		}
 
 		var err error
@@ -177,26 +188,19 @@ func main() {
 	}
 
 	{
-		mv := makevalid.Makevalid{
-			Hitmap:  hm,
-			Clipper: clip.Default,
-			Order:   order,
+		layer := mvt.SourceLayer{
+			Name:     "original",
+			Features: []mvt.Feature{{Geometry: geo}},
+			Extent:   uint32(*mvtExtent),
+			Buffer:   uint(*buffer),
 		}
-
-		mkvgeo, _, err := mv.Makevalid(ctx, geo, clipRegion)
+		mvtTile, err := mvt.BuildTile(ctx, grid3857, tile, layer)
 		if err != nil {
-			log.Printf("Got error using original makevalid: %v", err)
-
-		} else {
-			mvgeoFile := fileTemplate.NewFile("original_makevalid")
-			mvgeoFile.WriteWKTGeom(mkvgeo)
-			mvgeoFile.Close()
-			ext, _ := slippy.Extent(grid3857, tile)
-			if mvtgeo := mvt.PrepareGeo(mkvgeo, ext, *mvtExtent); mvtgeo != nil {
-				mvtgeof := fileTemplate.NewFile("original_mvt_geo")
-				mvtgeof.WriteWKTGeom(mvtgeo)
-				mvtgeof.Close()
-			}
+			log.Printf("Got error building the tile: %v", err)
+		} else if geos := tileGeoms(mvtTile); len(geos) > 0 {
+			mvtgeof := fileTemplate.NewFile("original_mvt_geo")
+			mvtgeof.WriteWKTGeom(geos...)
+			mvtgeof.Close()
 		}
 	}
 
@@ -295,11 +299,20 @@ func main() {
 	polygonFile.Close()
 
 	{
-		ext, _ := slippy.Extent(grid3857, tile)
-		mvtgeo := mvt.PrepareGeo(newMp, ext, *mvtExtent)
-		if mvtgeo != nil {
+		// the polygons are already clipped and valid
+		layer := mvt.SourceLayer{
+			Name:          "walked",
+			Features:      []mvt.Feature{{Geometry: newMp}},
+			Extent:        uint32(*mvtExtent),
+			DontClip:      true,
+			DontMakeValid: true,
+		}
+		mvtTile, err := mvt.BuildTile(ctx, grid3857, tile, layer)
+		if err != nil {
+			log.Printf("Got error building the tile: %v", err)
+		} else if geos := tileGeoms(mvtTile); len(geos) > 0 {
 			mvtgeof := fileTemplate.NewFile("mvt_geo")
-			mvtgeof.WriteWKTGeom(mvtgeo)
+			mvtgeof.WriteWKTGeom(geos...)
 			mvtgeof.Close()
 		}
 	}
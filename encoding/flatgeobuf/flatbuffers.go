@@ -0,0 +1,378 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// This file has the small part of FlatBuffers needed for the FlatGeobuf schema:
+// tables of scalars, strings, vectors of scalars, vectors of tables and tables.
+
+// fbTable is a table to be built, the index of a value is its field id and
+// nil values are left out.
+type fbTable []fbValue
+
+// fbValue is one of fbScalar, fbString, fbVector, fbTables or fbTable
+type fbValue interface{}
+
+// fbScalar is the little endian bytes of a scalar
+type fbScalar []byte
+
+// fbString is a string
+type fbString string
+
+// fbVector is a vector of scalars, data is the little endian bytes of the elements
+type fbVector struct {
+	elemSize int
+	data     []byte
+}
+
+// fbTables is a vector of tables
+type fbTables []fbTable
+
+func fbUint8(v uint8) fbScalar { return fbScalar{v} }
+func fbBool(v bool) fbScalar {
+	if v {
+		return fbScalar{1}
+	}
+	return fbScalar{0}
+}
+func fbUint16(v uint16) fbScalar {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return b
+}
+func fbInt32(v int32) fbScalar {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(v))
+	return b
+}
+func fbUint64(v uint64) fbScalar {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	return b
+}
+
+func fbFloat64s(vs []float64) fbVector {
+	b := make([]byte, 8*len(vs))
+	for i, v := range vs {
+		binary.LittleEndian.PutUint64(b[8*i:], math.Float64bits(v))
+	}
+	return fbVector{elemSize: 8, data: b}
+}
+
+func fbUint32s(vs []uint32) fbVector {
+	b := make([]byte, 4*len(vs))
+	for i, v := range vs {
+		binary.LittleEndian.PutUint32(b[4*i:], v)
+	}
+	return fbVector{elemSize: 4, data: b}
+}
+
+// fbBuild returns the size prefixed buffer with the table as its root
+func fbBuild(root fbTable) []byte {
+	b := &fbBuilder{buf: make([]byte, 8)}
+	// the size prefix and the offset to the root table
+	b.patch(4, b.table(root))
+	binary.LittleEndian.PutUint32(b.buf, uint32(len(b.buf)-4))
+	return b.buf
+}
+
+// fbBuilder writes objects front to back; as offsets are unsigned the children
+// of an object are written after it and the offsets to them patched in.
+type fbBuilder struct {
+	buf []byte
+}
+
+// align pads the buffer so the next byte is at a multiple of n; the positions
+// are relative to the start of the size prefix, as the reference verifier of
+// size prefixed buffers checks them.
+func (b *fbBuilder) align(n int) {
+	for len(b.buf)%n != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// patch sets the offset at pos to point to target
+func (b *fbBuilder) patch(pos, target int) {
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(target-pos))
+}
+
+func (b *fbBuilder) uint32(v uint32) {
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b.buf[len(b.buf)-4:], v)
+}
+
+// table writes the table and its children, returning the position of the table
+func (b *fbBuilder) table(t fbTable) int {
+	// the vtable comes first
+	b.align(2)
+	vtpos := len(b.buf)
+	vt := make([]byte, 4+2*len(t))
+	b.buf = append(b.buf, vt...)
+
+	b.align(8)
+	tpos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b.buf[tpos:], uint32(int32(tpos-vtpos)))
+
+	type child struct {
+		slot int
+		v    fbValue
+	}
+	var children []child
+	for i, v := range t {
+		if v == nil {
+			continue
+		}
+		var pos int
+		switch v := v.(type) {
+		case fbScalar:
+			b.align(len(v))
+			pos = len(b.buf)
+			b.buf = append(b.buf, v...)
+		default:
+			b.align(4)
+			pos = len(b.buf)
+			b.buf = append(b.buf, 0, 0, 0, 0)
+			children = append(children, child{slot: pos, v: v})
+		}
+		binary.LittleEndian.PutUint16(b.buf[vtpos+4+2*i:], uint16(pos-tpos))
+	}
+	binary.LittleEndian.PutUint16(b.buf[vtpos:], uint16(len(vt)))
+	binary.LittleEndian.PutUint16(b.buf[vtpos+2:], uint16(len(b.buf)-tpos))
+
+	for _, c := range children {
+		b.patch(c.slot, b.object(c.v))
+	}
+	return tpos
+}
+
+// object writes a string, vector or table, returning its position
+func (b *fbBuilder) object(v fbValue) int {
+	switch v := v.(type) {
+	case fbString:
+		b.align(4)
+		pos := len(b.buf)
+		b.uint32(uint32(len(v)))
+		b.buf = append(b.buf, v...)
+		b.buf = append(b.buf, 0)
+		return pos
+
+	case fbVector:
+		// the elements follow the length, and are aligned to their size
+		for (len(b.buf)+4)%v.elemSize != 0 || len(b.buf)%4 != 0 {
+			b.buf = append(b.buf, 0)
+		}
+		pos := len(b.buf)
+		b.uint32(uint32(len(v.data) / v.elemSize))
+		b.buf = append(b.buf, v.data...)
+		return pos
+
+	case fbTables:
+		b.align(4)
+		pos := len(b.buf)
+		b.uint32(uint32(len(v)))
+		slots := len(b.buf)
+		b.buf = append(b.buf, make([]byte, 4*len(v))...)
+		for i := range v {
+			b.patch(slots+4*i, b.table(v[i]))
+		}
+		return pos
+
+	case fbTable:
+		return b.table(v)
+	}
+	panic(fmt.Sprintf("unknown flatbuffer value %T", v))
+}
+
+// fbReader reads a table of a flatbuffer, all reads are bounds checked and
+// report an error through err.
+type fbReader struct {
+	buf []byte
+	pos int
+	// vtable position and size
+	vtpos, vtsize int
+	err           *error
+}
+
+// errInvalidBuffer is returned for buffers with offsets out of bounds
+var errInvalidBuffer = fmt.Errorf("invalid flatbuffer")
+
+// fbRoot returns the reader of the root table of the buffer, which does not have a size prefix
+func fbRoot(buf []byte, err *error) fbReader {
+	r := fbReader{buf: buf, err: err}
+	if !r.check(0, 4) {
+		return r
+	}
+	return r.tableAt(int(binary.LittleEndian.Uint32(buf)))
+}
+
+func (r fbReader) check(pos, n int) bool {
+	if *r.err != nil {
+		return false
+	}
+	if pos < 0 || n < 0 || pos+n > len(r.buf) {
+		*r.err = errInvalidBuffer
+		return false
+	}
+	return true
+}
+
+func (r fbReader) tableAt(pos int) fbReader {
+	t := fbReader{buf: r.buf, pos: pos, err: r.err}
+	if !r.check(pos, 4) {
+		return t
+	}
+	t.vtpos = pos - int(int32(binary.LittleEndian.Uint32(r.buf[pos:])))
+	if !r.check(t.vtpos, 4) {
+		return t
+	}
+	t.vtsize = int(binary.LittleEndian.Uint16(r.buf[t.vtpos:]))
+	if !r.check(t.vtpos, t.vtsize) {
+		return t
+	}
+	return t
+}
+
+// field returns the position of the field, 0 if it is not set
+func (r fbReader) field(id int) int {
+	if *r.err != nil {
+		return 0
+	}
+	off := 4 + 2*id
+	if off+2 > r.vtsize {
+		return 0
+	}
+	fo := int(binary.LittleEndian.Uint16(r.buf[r.vtpos+off:]))
+	if fo == 0 {
+		return 0
+	}
+	return r.pos + fo
+}
+
+func (r fbReader) uint8(id int, def uint8) uint8 {
+	pos := r.field(id)
+	if pos == 0 || !r.check(pos, 1) {
+		return def
+	}
+	return r.buf[pos]
+}
+
+func (r fbReader) bool(id int, def bool) bool {
+	d := uint8(0)
+	if def {
+		d = 1
+	}
+	return r.uint8(id, d) != 0
+}
+
+func (r fbReader) uint16(id int, def uint16) uint16 {
+	pos := r.field(id)
+	if pos == 0 || !r.check(pos, 2) {
+		return def
+	}
+	return binary.LittleEndian.Uint16(r.buf[pos:])
+}
+
+func (r fbReader) int32(id int, def int32) int32 {
+	pos := r.field(id)
+	if pos == 0 || !r.check(pos, 4) {
+		return def
+	}
+	return int32(binary.LittleEndian.Uint32(r.buf[pos:]))
+}
+
+func (r fbReader) uint64(id int, def uint64) uint64 {
+	pos := r.field(id)
+	if pos == 0 || !r.check(pos, 8) {
+		return def
+	}
+	return binary.LittleEndian.Uint64(r.buf[pos:])
+}
+
+// indirect returns the position of the object the offset field points to, 0 if not set
+func (r fbReader) indirect(id int) int {
+	pos := r.field(id)
+	if pos == 0 || !r.check(pos, 4) {
+		return 0
+	}
+	return pos + int(binary.LittleEndian.Uint32(r.buf[pos:]))
+}
+
+// vector returns the position of the first element and the number of elements
+func (r fbReader) vector(id int, elemSize int) (int, int) {
+	pos := r.indirect(id)
+	if pos == 0 || !r.check(pos, 4) {
+		return 0, 0
+	}
+	n := int(binary.LittleEndian.Uint32(r.buf[pos:]))
+	if !r.check(pos+4, n*elemSize) {
+		return 0, 0
+	}
+	return pos + 4, n
+}
+
+func (r fbReader) string(id int) string {
+	pos, n := r.vector(id, 1)
+	if n == 0 {
+		return ""
+	}
+	return string(r.buf[pos : pos+n])
+}
+
+func (r fbReader) bytes(id int) []byte {
+	pos, n := r.vector(id, 1)
+	if n == 0 {
+		return nil
+	}
+	return r.buf[pos : pos+n]
+}
+
+func (r fbReader) float64s(id int) []float64 {
+	pos, n := r.vector(id, 8)
+	if n == 0 {
+		return nil
+	}
+	vs := make([]float64, n)
+	for i := range vs {
+		vs[i] = math.Float64frombits(binary.LittleEndian.Uint64(r.buf[pos+8*i:]))
+	}
+	return vs
+}
+
+func (r fbReader) uint32s(id int) []uint32 {
+	pos, n := r.vector(id, 4)
+	if n == 0 {
+		return nil
+	}
+	vs := make([]uint32, n)
+	for i := range vs {
+		vs[i] = binary.LittleEndian.Uint32(r.buf[pos+4*i:])
+	}
+	return vs
+}
+
+// table returns the reader of the table field and whether it is set
+func (r fbReader) table(id int) (fbReader, bool) {
+	pos := r.indirect(id)
+	if pos == 0 {
+		return fbReader{err: r.err}, false
+	}
+	return r.tableAt(pos), *r.err == nil
+}
+
+// tables returns the readers of the tables of the vector field
+func (r fbReader) tables(id int) []fbReader {
+	pos, n := r.vector(id, 4)
+	if n == 0 {
+		return nil
+	}
+	ts := make([]fbReader, n)
+	for i := range ts {
+		slot := pos + 4*i
+		ts[i] = r.tableAt(slot + int(binary.LittleEndian.Uint32(r.buf[slot:])))
+	}
+	return ts
+}
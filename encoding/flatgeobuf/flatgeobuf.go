@@ -0,0 +1,171 @@
+// Package flatgeobuf implements reading and writing of FlatGeobuf files as described
+// at https://flatgeobuf.org. A file has magic bytes, a header with the columns of the
+// features, an optional packed Hilbert R-tree index of the bounding boxes of the
+// features, and the features, each a geometry and the values of its columns.
+//
+// The 2D geometry types, the Z, M and ZM variants of points, line strings, polygons,
+// multi points and multi line strings, and collections are supported.
+package flatgeobuf
+
+import (
+	"fmt"
+
+	"github.com/hahaking119/geom"
+)
+
+// magic is the magic bytes of the major version 3 of the format
+var magic = [8]byte{'f', 'g', 'b', 3, 'f', 'g', 'b', 0}
+
+// DefaultIndexNodeSize is the node size of the index used by most writers
+const DefaultIndexNodeSize = 16
+
+// GeometryType is the type of a geometry
+type GeometryType uint8
+
+// geometry types, Unknown is used in the header of files with features of
+// different types
+const (
+	Unknown            GeometryType = 0
+	Point              GeometryType = 1
+	LineString         GeometryType = 2
+	Polygon            GeometryType = 3
+	MultiPoint         GeometryType = 4
+	MultiLineString    GeometryType = 5
+	MultiPolygon       GeometryType = 6
+	GeometryCollection GeometryType = 7
+)
+
+// ColumnType is the type of the values of a column
+type ColumnType uint8
+
+// column types and the Go types of their values
+const (
+	Byte     ColumnType = iota // int8
+	UByte                      // uint8
+	Bool                       // bool
+	Short                      // int16
+	UShort                     // uint16
+	Int                        // int32
+	UInt                       // uint32
+	Long                       // int64
+	ULong                      // uint64
+	Float                      // float32
+	Double                     // float64
+	String                     // string
+	JSON                       // string
+	DateTime                   // string, in ISO 8601; a time.Time is also accepted when writing
+	Binary                     // []byte
+)
+
+// Column describes the values of a property of the features
+type Column struct {
+	Name        string
+	Type        ColumnType
+	Title       string
+	Description string
+	// Width, Precision and Scale are -1 when not known
+	Width      int32
+	Precision  int32
+	Scale      int32
+	Nullable   bool
+	Unique     bool
+	PrimaryKey bool
+	Metadata   string
+}
+
+// NewColumn returns a nullable column with the name and type
+func NewColumn(name string, typ ColumnType) Column {
+	return Column{
+		Name:      name,
+		Type:      typ,
+		Width:     -1,
+		Precision: -1,
+		Scale:     -1,
+		Nullable:  true,
+	}
+}
+
+// CRS is the coordinate reference system of the geometries
+type CRS struct {
+	// Org is the organization of the code, EPSG when empty
+	Org         string
+	Code        int32
+	Name        string
+	Description string
+	WKT         string
+	CodeString  string
+}
+
+// Header is the header of a file
+type Header struct {
+	Name string
+	// Envelope is the extent of all the features, set by the writer
+	Envelope *geom.Extent
+	// GeometryType is the type of all the geometries, or Unknown; set by the writer
+	GeometryType GeometryType
+	// HasZ and HasM are whether the geometries have z and m values; set by the writer
+	HasZ, HasM bool
+	Columns    []Column
+	// FeaturesCount is the number of features, set by the writer
+	FeaturesCount uint64
+	// IndexNodeSize is the number of children of each node of the index; 0 for no index
+	IndexNodeSize uint16
+	CRS           *CRS
+	Title         string
+	Description   string
+	Metadata      string
+}
+
+// Feature is a geometry and the values of its properties, keyed by column name
+type Feature struct {
+	Geometry   geom.Geometry
+	Properties map[string]interface{}
+}
+
+// ErrInvalidMagic is returned when reading a file that does not start with the
+// magic bytes of FlatGeobuf version 3
+type ErrInvalidMagic [8]byte
+
+func (e ErrInvalidMagic) Error() string {
+	return fmt.Sprintf("invalid magic bytes % x", [8]byte(e))
+}
+
+// ErrUnknownColumn is returned when writing a property that is not a column of the header
+type ErrUnknownColumn string
+
+func (e ErrUnknownColumn) Error() string {
+	return fmt.Sprintf("unknown column %q", string(e))
+}
+
+// ErrInvalidValue is returned when writing a property value that does not match
+// the type of its column
+type ErrInvalidValue struct {
+	Column string
+	Type   ColumnType
+	Value  interface{}
+}
+
+func (e ErrInvalidValue) Error() string {
+	return fmt.Sprintf("invalid value %v (%T) for column %q of type %v", e.Value, e.Value, e.Column, e.Type)
+}
+
+// ErrMixedDimensions is returned when writing features whose geometries do not all
+// have the same dimensions
+type ErrMixedDimensions struct {
+	HasZ, HasM bool
+}
+
+func (e ErrMixedDimensions) Error() string {
+	return fmt.Sprintf("geometry with z (%v) and m (%v) differs from the other geometries", e.HasZ, e.HasM)
+}
+
+// ErrUnsupportedGeometry is returned when reading a geometry that has no geom type,
+// such as a multi polygon with z values
+type ErrUnsupportedGeometry struct {
+	Type       GeometryType
+	HasZ, HasM bool
+}
+
+func (e ErrUnsupportedGeometry) Error() string {
+	return fmt.Sprintf("geometry type %v with z (%v) and m (%v) is not supported", e.Type, e.HasZ, e.HasM)
+}
@@ -0,0 +1,540 @@
+package flatgeobuf_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding/flatgeobuf"
+)
+
+// sortByID sorts the features by their id property, as the index reorders them
+func sortByID(features []flatgeobuf.Feature) {
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].Properties["id"].(int64) < features[j].Properties["id"].(int64)
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	type tcase struct {
+		geoms []geom.Geometry
+		typ   flatgeobuf.GeometryType
+		hasZ  bool
+		hasM  bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			for _, nodeSize := range []uint16{0, 2, flatgeobuf.DefaultIndexNodeSize} {
+				features := make([]flatgeobuf.Feature, len(tc.geoms))
+				for i, g := range tc.geoms {
+					features[i] = flatgeobuf.Feature{
+						Geometry:   g,
+						Properties: map[string]interface{}{"id": int64(i)},
+					}
+				}
+				hdr := flatgeobuf.Header{
+					Name:          "test",
+					Columns:       []flatgeobuf.Column{flatgeobuf.NewColumn("id", flatgeobuf.Long)},
+					IndexNodeSize: nodeSize,
+				}
+				var buf bytes.Buffer
+				if err := flatgeobuf.Encode(&buf, hdr, features...); err != nil {
+					t.Fatalf("encode error, expected nil got %v", err)
+				}
+
+				gotHdr, got, err := flatgeobuf.Decode(&buf, nil)
+				if err != nil {
+					t.Fatalf("decode error, expected nil got %v", err)
+				}
+				if gotHdr.GeometryType != tc.typ || gotHdr.HasZ != tc.hasZ || gotHdr.HasM != tc.hasM {
+					t.Errorf("header, expected %v %v %v got %v %v %v", tc.typ, tc.hasZ, tc.hasM, gotHdr.GeometryType, gotHdr.HasZ, gotHdr.HasM)
+				}
+				if gotHdr.FeaturesCount != uint64(len(features)) || gotHdr.IndexNodeSize != nodeSize {
+					t.Errorf("header, expected %v features and node size %v got %v and %v", len(features), nodeSize, gotHdr.FeaturesCount, gotHdr.IndexNodeSize)
+				}
+				sortByID(got)
+				if !reflect.DeepEqual(got, features) {
+					t.Errorf("node size %v features, expected %v got %v", nodeSize, features, got)
+				}
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			geoms: []geom.Geometry{geom.Point{1, 2}, geom.Point{-3, 4}},
+			typ:   flatgeobuf.Point,
+		},
+		"point z": {
+			geoms: []geom.Geometry{geom.PointZ{1, 2, 3}},
+			typ:   flatgeobuf.Point,
+			hasZ:  true,
+		},
+		"point m": {
+			geoms: []geom.Geometry{geom.PointM{1, 2, 3}},
+			typ:   flatgeobuf.Point,
+			hasM:  true,
+		},
+		"point zm": {
+			geoms: []geom.Geometry{geom.PointZM{1, 2, 3, 4}},
+			typ:   flatgeobuf.Point,
+			hasZ:  true,
+			hasM:  true,
+		},
+		"line string": {
+			geoms: []geom.Geometry{geom.LineString{{1, 2}, {3, 4}}, geom.LineString{}},
+			typ:   flatgeobuf.LineString,
+		},
+		"line string z": {
+			geoms: []geom.Geometry{geom.LineStringZ{{1, 2, 3}, {3, 4, 5}}},
+			typ:   flatgeobuf.LineString,
+			hasZ:  true,
+		},
+		"line string zm": {
+			geoms: []geom.Geometry{geom.LineStringZM{{1, 2, 3, 4}, {3, 4, 5, 6}}},
+			typ:   flatgeobuf.LineString,
+			hasZ:  true,
+			hasM:  true,
+		},
+		"polygon": {
+			geoms: []geom.Geometry{
+				geom.Polygon{
+					{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+					{{2, 2}, {2, 4}, {4, 4}},
+				},
+			},
+			typ: flatgeobuf.Polygon,
+		},
+		"polygon m": {
+			geoms: []geom.Geometry{geom.PolygonM{{{0, 0, 1}, {10, 0, 2}, {10, 10, 3}}}},
+			typ:   flatgeobuf.Polygon,
+			hasM:  true,
+		},
+		"multi point z": {
+			geoms: []geom.Geometry{geom.MultiPointZ{{1, 1, 1}, {2, 2, 2}}},
+			typ:   flatgeobuf.MultiPoint,
+			hasZ:  true,
+		},
+		"multi line string": {
+			geoms: []geom.Geometry{geom.MultiLineString{{{1, 1}, {2, 2}}, {{3, 3}, {4, 4}, {5, 3}}}},
+			typ:   flatgeobuf.MultiLineString,
+		},
+		"multi line string zm": {
+			geoms: []geom.Geometry{geom.MultiLineStringZM{{{1, 1, 1, 1}, {2, 2, 2, 2}}}},
+			typ:   flatgeobuf.MultiLineString,
+			hasZ:  true,
+			hasM:  true,
+		},
+		"multi polygon": {
+			geoms: []geom.Geometry{
+				geom.MultiPolygon{
+					{{{0, 0}, {1, 0}, {1, 1}}},
+					{{{5, 5}, {6, 5}, {6, 6}}, {{5.5, 5.2}, {5.8, 5.2}, {5.8, 5.5}}},
+				},
+			},
+			typ: flatgeobuf.MultiPolygon,
+		},
+		"collection": {
+			geoms: []geom.Geometry{
+				geom.Collection{
+					geom.PointZ{1, 2, 3},
+					geom.LineStringZ{{1, 2, 3}, {4, 5, 6}},
+					geom.Collection{geom.MultiPointZ{{1, 1, 1}}},
+				},
+			},
+			typ:  flatgeobuf.GeometryCollection,
+			hasZ: true,
+		},
+		"mixed": {
+			geoms: []geom.Geometry{
+				geom.Point{1, 2},
+				geom.LineString{{1, 2}, {3, 4}},
+				nil,
+				geom.Polygon{{{0, 0}, {1, 0}, {1, 1}}},
+			},
+			typ: flatgeobuf.Unknown,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestProperties(t *testing.T) {
+	cols := []flatgeobuf.Column{
+		flatgeobuf.NewColumn("byte", flatgeobuf.Byte),
+		flatgeobuf.NewColumn("ubyte", flatgeobuf.UByte),
+		flatgeobuf.NewColumn("bool", flatgeobuf.Bool),
+		flatgeobuf.NewColumn("short", flatgeobuf.Short),
+		flatgeobuf.NewColumn("ushort", flatgeobuf.UShort),
+		flatgeobuf.NewColumn("int", flatgeobuf.Int),
+		flatgeobuf.NewColumn("uint", flatgeobuf.UInt),
+		flatgeobuf.NewColumn("long", flatgeobuf.Long),
+		flatgeobuf.NewColumn("ulong", flatgeobuf.ULong),
+		flatgeobuf.NewColumn("float", flatgeobuf.Float),
+		flatgeobuf.NewColumn("double", flatgeobuf.Double),
+		flatgeobuf.NewColumn("string", flatgeobuf.String),
+		flatgeobuf.NewColumn("json", flatgeobuf.JSON),
+		flatgeobuf.NewColumn("datetime", flatgeobuf.DateTime),
+		flatgeobuf.NewColumn("binary", flatgeobuf.Binary),
+		flatgeobuf.NewColumn("unset", flatgeobuf.Int),
+	}
+	cols[0].Title, cols[0].Description, cols[0].Width = "Byte", "a byte", 3
+	hdr := flatgeobuf.Header{
+		Name:        "properties",
+		Columns:     cols,
+		CRS:         &flatgeobuf.CRS{Org: "EPSG", Code: 4326, Name: "WGS 84"},
+		Title:       "title",
+		Description: "description",
+		Metadata:    `{"a":1}`,
+	}
+	props := map[string]interface{}{
+		"byte":     -5,
+		"ubyte":    uint8(200),
+		"bool":     true,
+		"short":    int16(-300),
+		"ushort":   60000,
+		"int":      int32(-70000),
+		"uint":     uint32(70000),
+		"long":     int64(-1) << 40,
+		"ulong":    uint64(1) << 63,
+		"float":    float32(1.5),
+		"double":   2.25,
+		"string":   "hello",
+		"json":     `{"b":[1,2]}`,
+		"datetime": time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		"binary":   []byte{0, 1, 2},
+		"unset":    nil,
+	}
+	expected := map[string]interface{}{
+		"byte":     int8(-5),
+		"ubyte":    uint8(200),
+		"bool":     true,
+		"short":    int16(-300),
+		"ushort":   uint16(60000),
+		"int":      int32(-70000),
+		"uint":     uint32(70000),
+		"long":     int64(-1) << 40,
+		"ulong":    uint64(1) << 63,
+		"float":    float32(1.5),
+		"double":   2.25,
+		"string":   "hello",
+		"json":     `{"b":[1,2]}`,
+		"datetime": "2020-01-02T03:04:05Z",
+		"binary":   []byte{0, 1, 2},
+	}
+
+	var buf bytes.Buffer
+	if err := flatgeobuf.Encode(&buf, hdr, flatgeobuf.Feature{Geometry: geom.Point{1, 2}, Properties: props}); err != nil {
+		t.Fatalf("encode error, expected nil got %v", err)
+	}
+	gotHdr, got, err := flatgeobuf.Decode(&buf, nil)
+	if err != nil {
+		t.Fatalf("decode error, expected nil got %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("features, expected 1 got %v", len(got))
+	}
+	if !reflect.DeepEqual(got[0].Properties, expected) {
+		t.Errorf("properties, expected %v got %v", expected, got[0].Properties)
+	}
+	if !reflect.DeepEqual(gotHdr.Columns, cols) {
+		t.Errorf("columns, expected %v got %v", cols, gotHdr.Columns)
+	}
+	if !reflect.DeepEqual(gotHdr.CRS, hdr.CRS) {
+		t.Errorf("crs, expected %v got %v", hdr.CRS, gotHdr.CRS)
+	}
+	if gotHdr.Name != hdr.Name || gotHdr.Title != hdr.Title || gotHdr.Description != hdr.Description || gotHdr.Metadata != hdr.Metadata {
+		t.Errorf("header, expected %v got %v", hdr, gotHdr)
+	}
+	if expected := (&geom.Extent{1, 2, 1, 2}); !reflect.DeepEqual(gotHdr.Envelope, expected) {
+		t.Errorf("envelope, expected %v got %v", expected, gotHdr.Envelope)
+	}
+}
+
+// reader hides the Seek method of the bytes.Reader
+type reader struct {
+	io.Reader
+}
+
+func TestFilter(t *testing.T) {
+	type tcase struct {
+		nodeSize uint16
+		seek     bool
+		ext      *geom.Extent
+		expected []int64
+	}
+
+	// a 10 by 10 grid of points, with the id 10*x+y
+	var features []flatgeobuf.Feature
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			features = append(features, flatgeobuf.Feature{
+				Geometry:   geom.Point{float64(x), float64(y)},
+				Properties: map[string]interface{}{"id": int64(10*x + y)},
+			})
+		}
+	}
+	// a line string across the grid, with an extent that is not a point
+	features = append(features, flatgeobuf.Feature{
+		Geometry:   geom.LineString{{-1, 20}, {1, 21}},
+		Properties: map[string]interface{}{"id": int64(1000)},
+	})
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			hdr := flatgeobuf.Header{
+				Columns:       []flatgeobuf.Column{flatgeobuf.NewColumn("id", flatgeobuf.Long)},
+				IndexNodeSize: tc.nodeSize,
+			}
+			var buf bytes.Buffer
+			if err := flatgeobuf.Encode(&buf, hdr, features...); err != nil {
+				t.Fatalf("encode error, expected nil got %v", err)
+			}
+
+			var r io.Reader = reader{bytes.NewReader(buf.Bytes())}
+			if tc.seek {
+				r = bytes.NewReader(buf.Bytes())
+			}
+			_, got, err := flatgeobuf.Decode(r, tc.ext)
+			if err != nil {
+				t.Fatalf("decode error, expected nil got %v", err)
+			}
+			sortByID(got)
+			ids := make([]int64, len(got))
+			for i := range got {
+				ids[i] = got[i].Properties["id"].(int64)
+			}
+			if !reflect.DeepEqual(ids, tc.expected) {
+				t.Errorf("ids, expected %v got %v", tc.expected, ids)
+			}
+		}
+	}
+
+	square := &geom.Extent{2, 2, 4, 4}
+	squareIDs := []int64{22, 23, 24, 32, 33, 34, 42, 43, 44}
+	tests := map[string]tcase{
+		"no index":              {ext: square, expected: squareIDs},
+		"index":                 {nodeSize: 16, ext: square, expected: squareIDs},
+		"index seek":            {nodeSize: 16, seek: true, ext: square, expected: squareIDs},
+		"small nodes":           {nodeSize: 2, ext: square, expected: squareIDs},
+		"small nodes seek":      {nodeSize: 3, seek: true, ext: square, expected: squareIDs},
+		"line string no index":  {ext: &geom.Extent{0, 20.5, 0.5, 30}, expected: []int64{1000}},
+		"line string index":     {nodeSize: 4, ext: &geom.Extent{0, 20.5, 0.5, 30}, expected: []int64{1000}},
+		"nothing":               {nodeSize: 4, ext: &geom.Extent{100, 100, 200, 200}, expected: []int64{}},
+		"nothing no index":      {ext: &geom.Extent{100, 100, 200, 200}, expected: []int64{}},
+		"single point index":    {nodeSize: 16, ext: &geom.Extent{9, 9, 9, 9}, expected: []int64{99}},
+		"single point no index": {ext: &geom.Extent{9, 9, 9, 9}, expected: []int64{99}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	type tcase struct {
+		features []flatgeobuf.Feature
+		err      error
+	}
+
+	cols := []flatgeobuf.Column{
+		flatgeobuf.NewColumn("name", flatgeobuf.String),
+		flatgeobuf.NewColumn("count", flatgeobuf.UByte),
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			var buf bytes.Buffer
+			err := flatgeobuf.Encode(&buf, flatgeobuf.Header{Columns: cols}, tc.features...)
+			if !reflect.DeepEqual(err, tc.err) {
+				t.Errorf("error, expected %v got %v", tc.err, err)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"unknown column": {
+			features: []flatgeobuf.Feature{{Properties: map[string]interface{}{"other": 1}}},
+			err:      flatgeobuf.ErrUnknownColumn("other"),
+		},
+		"invalid value": {
+			features: []flatgeobuf.Feature{{Properties: map[string]interface{}{"name": 1}}},
+			err:      flatgeobuf.ErrInvalidValue{Column: "name", Type: flatgeobuf.String, Value: 1},
+		},
+		"value out of range": {
+			features: []flatgeobuf.Feature{{Properties: map[string]interface{}{"count": 256}}},
+			err:      flatgeobuf.ErrInvalidValue{Column: "count", Type: flatgeobuf.UByte, Value: 256},
+		},
+		"mixed dimensions": {
+			features: []flatgeobuf.Feature{
+				{Geometry: geom.Point{1, 2}},
+				{Geometry: geom.PointZ{1, 2, 3}},
+			},
+			err: flatgeobuf.ErrMixedDimensions{HasZ: true},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	_, err := flatgeobuf.NewReader(bytes.NewReader([]byte("fgb\x02fgb\x00\x00\x00\x00\x00")))
+	expected := flatgeobuf.ErrInvalidMagic{'f', 'g', 'b', 2, 'f', 'g', 'b', 0}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("error, expected %v got %v", expected, err)
+	}
+
+	var buf bytes.Buffer
+	if err := flatgeobuf.Encode(&buf, flatgeobuf.Header{}, flatgeobuf.Feature{Geometry: geom.Point{1, 2}}); err != nil {
+		t.Fatalf("encode error, expected nil got %v", err)
+	}
+	_, err = flatgeobuf.NewReader(bytes.NewReader(buf.Bytes()[:20]))
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("error, expected %v got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+// places returns the header and features of the files in testdata, see testdata/README.md
+func places() (flatgeobuf.Header, []flatgeobuf.Feature) {
+	hdr := flatgeobuf.Header{
+		Name: "places",
+		Columns: []flatgeobuf.Column{
+			flatgeobuf.NewColumn("name", flatgeobuf.String),
+			flatgeobuf.NewColumn("population", flatgeobuf.Long),
+			flatgeobuf.NewColumn("area", flatgeobuf.Double),
+			flatgeobuf.NewColumn("capital", flatgeobuf.Bool),
+		},
+		IndexNodeSize: flatgeobuf.DefaultIndexNodeSize,
+		CRS:           &flatgeobuf.CRS{Org: "EPSG", Code: 4326},
+		Title:         "reference writer fixture",
+	}
+	features := []flatgeobuf.Feature{
+		{
+			Geometry:   geom.Point{16.3725, 48.2083},
+			Properties: map[string]interface{}{"name": "Vienna", "population": int64(1897000), "area": 414.87, "capital": true},
+		},
+		{
+			Geometry:   geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, {{2, 2}, {2, 4}, {4, 4}}},
+			Properties: map[string]interface{}{"name": "square", "area": 98.0},
+		},
+		{
+			Geometry: geom.MultiPolygon{
+				{{{20, 20}, {24, 20}, {24, 24}}},
+				{{{30, 30}, {40, 30}, {40, 40}, {30, 40}}, {{32, 32}, {34, 32}, {34, 34}, {32, 34}}},
+			},
+			Properties: map[string]interface{}{"name": "islands"},
+		},
+		{
+			Geometry:   geom.LineString{{-10, -10}, {-5, -2}, {0, -1}},
+			Properties: map[string]interface{}{"name": "road", "capital": false},
+		},
+	}
+	return hdr, features
+}
+
+func TestDecodeReference(t *testing.T) {
+	type tcase struct {
+		ext *geom.Extent
+		// expected are the names of the features, in the order of the file
+		expected []string
+	}
+
+	expHdr, features := places()
+	byName := make(map[string]flatgeobuf.Feature)
+	for _, f := range features {
+		byName[f.Properties["name"].(string)] = f
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			// the file was not written by this package, see testdata/README.md
+			b, err := ioutil.ReadFile(filepath.Join("testdata", "reference.fgb"))
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			hdr, got, err := flatgeobuf.Decode(bytes.NewReader(b), tc.ext)
+			if err != nil {
+				t.Fatalf("decode error, expected nil got %v", err)
+			}
+
+			// the index node size is the default, so is not in the file
+			if hdr.Name != expHdr.Name || hdr.Title != expHdr.Title || hdr.IndexNodeSize != expHdr.IndexNodeSize {
+				t.Errorf("header, expected %v got %v", expHdr, hdr)
+			}
+			if hdr.GeometryType != flatgeobuf.Unknown || hdr.HasZ || hdr.HasM || hdr.FeaturesCount != 4 {
+				t.Errorf("header, expected unknown 2d geometries and 4 features got %v %v %v %v", hdr.GeometryType, hdr.HasZ, hdr.HasM, hdr.FeaturesCount)
+			}
+			if !reflect.DeepEqual(hdr.Columns, expHdr.Columns) {
+				t.Errorf("columns, expected %v got %v", expHdr.Columns, hdr.Columns)
+			}
+			if !reflect.DeepEqual(hdr.CRS, expHdr.CRS) {
+				t.Errorf("crs, expected %v got %v", expHdr.CRS, hdr.CRS)
+			}
+			if expected := (&geom.Extent{-10, -10, 40, 48.2083}); !reflect.DeepEqual(hdr.Envelope, expected) {
+				t.Errorf("envelope, expected %v got %v", expected, hdr.Envelope)
+			}
+
+			var expected []flatgeobuf.Feature
+			for _, name := range tc.expected {
+				expected = append(expected, byName[name])
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("features, expected %v got %v", expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"all": {
+			expected: []string{"islands", "Vienna", "square", "road"},
+		},
+		"index": {
+			ext:      geom.NewExtent([2]float64{-1, -1}, [2]float64{3, 3}),
+			expected: []string{"square", "road"},
+		},
+		"index none": {
+			ext: geom.NewExtent([2]float64{50, 0}, [2]float64{60, 10}),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestEncodeKnownFile(t *testing.T) {
+	// the file was checked with a separate reader, see testdata/README.md
+	expected, err := ioutil.ReadFile(filepath.Join("testdata", "encoded.fgb"))
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+
+	hdr, features := places()
+	var buf bytes.Buffer
+	if err := flatgeobuf.Encode(&buf, hdr, features...); err != nil {
+		t.Fatalf("encode error, expected nil got %v", err)
+	}
+	got := buf.Bytes()
+
+	// the magic bytes, the size prefix and the header
+	hdrEnd := 12 + int(binary.LittleEndian.Uint32(expected[8:]))
+	if len(got) < hdrEnd || !bytes.Equal(got[:hdrEnd], expected[:hdrEnd]) {
+		t.Fatalf("header bytes, expected\n% x\ngot\n% x", expected[:hdrEnd], got[:hdrEnd])
+	}
+	// the index and the features
+	if !bytes.Equal(got[hdrEnd:], expected[hdrEnd:]) {
+		t.Errorf("index and feature bytes, expected\n% x\ngot\n% x", expected[hdrEnd:], got[hdrEnd:])
+	}
+}
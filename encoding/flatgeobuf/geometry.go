@@ -0,0 +1,520 @@
+package flatgeobuf
+
+import (
+	"fmt"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+)
+
+// field ids of the Geometry table
+const (
+	geometryEnds = iota
+	geometryXY
+	geometryZ
+	geometryM
+	geometryT
+	geometryTM
+	geometryType
+	geometryParts
+)
+
+// shape is a geometry as slices of coordinates, with the z and m values following
+// the x and y values. Points, multi points and line strings have a single ring, multi
+// line strings and polygons a single polygon; collections have geometries instead.
+type shape struct {
+	typ        GeometryType
+	hasZ, hasM bool
+	polys      [][][][]float64
+	geoms      []geom.Geometry
+}
+
+// geometryInfo is what the writer needs to know of an encoded geometry
+type geometryInfo struct {
+	typ        GeometryType
+	hasZ, hasM bool
+	// dims is false for empty collections, which go with any dimensions
+	dims bool
+	// ext is the extent of the x and y values, nil for empty geometries
+	ext *geom.Extent
+}
+
+// encodeGeometry returns the Geometry table of the geometry
+func encodeGeometry(geo geom.Geometry) (fbTable, geometryInfo, error) {
+	s, err := newShape(geo)
+	if err != nil {
+		return nil, geometryInfo{}, err
+	}
+	info := geometryInfo{typ: s.typ, hasZ: s.hasZ, hasM: s.hasM, dims: true}
+	t := make(fbTable, geometryParts+1)
+	t[geometryType] = fbUint8(uint8(s.typ))
+
+	switch s.typ {
+	case MultiPolygon:
+		parts := make(fbTables, len(s.polys))
+		for i, p := range s.polys {
+			parts[i] = make(fbTable, geometryParts+1)
+			parts[i][geometryType] = fbUint8(uint8(Polygon))
+			info.ext = addExtent(info.ext, setCoords(parts[i], p, true, s.hasZ, s.hasM))
+		}
+		if len(parts) > 0 {
+			t[geometryParts] = parts
+		}
+
+	case GeometryCollection:
+		info.dims = false
+		parts := make(fbTables, len(s.geoms))
+		for i, g := range s.geoms {
+			part, pinfo, err := encodeGeometry(g)
+			if err != nil {
+				return nil, geometryInfo{}, err
+			}
+			if pinfo.dims {
+				if info.dims && (pinfo.hasZ != info.hasZ || pinfo.hasM != info.hasM) {
+					return nil, geometryInfo{}, ErrMixedDimensions{HasZ: pinfo.hasZ, HasM: pinfo.hasM}
+				}
+				info.dims, info.hasZ, info.hasM = true, pinfo.hasZ, pinfo.hasM
+			}
+			info.ext = addExtent(info.ext, pinfo.ext)
+			parts[i] = part
+		}
+		if len(parts) > 0 {
+			t[geometryParts] = parts
+		}
+
+	default:
+		info.ext = setCoords(t, s.polys[0], s.typ == Polygon, s.hasZ, s.hasM)
+	}
+	return t, info, nil
+}
+
+// setCoords sets the coordinates and ends of the table to those of the rings, and
+// returns their extent
+func setCoords(t fbTable, rings [][][]float64, closed bool, hasZ, hasM bool) *geom.Extent {
+	var (
+		xy, z, m []float64
+		ends     []uint32
+		ext      *geom.Extent
+	)
+	for _, r := range rings {
+		if closed {
+			r = closeRing(r)
+		}
+		for _, pt := range r {
+			xy = append(xy, pt[0], pt[1])
+			ext = addExtent(ext, &geom.Extent{pt[0], pt[1], pt[0], pt[1]})
+			d := 2
+			if hasZ {
+				z = append(z, pt[d])
+				d++
+			}
+			if hasM {
+				m = append(m, pt[d])
+			}
+		}
+		ends = append(ends, uint32(len(xy)/2))
+	}
+	if len(xy) > 0 {
+		t[geometryXY] = fbFloat64s(xy)
+	}
+	if len(z) > 0 {
+		t[geometryZ] = fbFloat64s(z)
+	}
+	if len(m) > 0 {
+		t[geometryM] = fbFloat64s(m)
+	}
+	// the ends are only needed for more than one ring
+	if len(ends) > 1 {
+		t[geometryEnds] = fbUint32s(ends)
+	}
+	return ext
+}
+
+// addExtent returns the extent expanded by the other one, either may be nil
+func addExtent(ext, other *geom.Extent) *geom.Extent {
+	if other == nil {
+		return ext
+	}
+	if ext == nil {
+		e := *other
+		return &e
+	}
+	ext.Add(other)
+	return ext
+}
+
+// decodeGeometry returns the geometry of the Geometry table; the type is read from the
+// table when it is Unknown.
+func decodeGeometry(t fbReader, typ GeometryType, hasZ, hasM bool) (geom.Geometry, error) {
+	if typ == Unknown {
+		typ = GeometryType(t.uint8(geometryType, 0))
+	}
+
+	switch typ {
+	case MultiPolygon:
+		if hasZ || hasM {
+			return nil, ErrUnsupportedGeometry{Type: typ, HasZ: hasZ, HasM: hasM}
+		}
+		parts := t.tables(geometryParts)
+		mp := make(geom.MultiPolygon, len(parts))
+		for i, p := range parts {
+			rings, err := decodeRings(p, hasZ, hasM)
+			if err != nil {
+				return nil, err
+			}
+			mp[i] = lines2(openRings(rings))
+		}
+		return mp, nil
+
+	case GeometryCollection:
+		parts := t.tables(geometryParts)
+		col := make(geom.Collection, 0, len(parts))
+		for _, p := range parts {
+			g, err := decodeGeometry(p, Unknown, hasZ, hasM)
+			if err != nil {
+				return nil, err
+			}
+			if g != nil {
+				col = append(col, g)
+			}
+		}
+		return col, nil
+
+	case Point, LineString, Polygon, MultiPoint, MultiLineString:
+		rings, err := decodeRings(t, hasZ, hasM)
+		if err != nil {
+			return nil, err
+		}
+		if typ == Polygon {
+			rings = openRings(rings)
+		}
+		return newGeometry(typ, hasZ, hasM, rings), nil
+
+	default:
+		return nil, ErrUnsupportedGeometry{Type: typ, HasZ: hasZ, HasM: hasM}
+	}
+}
+
+// decodeRings returns the coordinates of the table split at its ends
+func decodeRings(t fbReader, hasZ, hasM bool) ([][][]float64, error) {
+	var err error
+	xy, z, m, ends := t.float64s(geometryXY), t.float64s(geometryZ), t.float64s(geometryM), t.uint32s(geometryEnds)
+	if *t.err != nil {
+		return nil, *t.err
+	}
+	n := len(xy) / 2
+	switch {
+	case len(xy)%2 != 0:
+		err = fmt.Errorf("odd number of xy values %v", len(xy))
+	case hasZ && len(z) != n:
+		err = fmt.Errorf("%v z values for %v points", len(z), n)
+	case hasM && len(m) != n:
+		err = fmt.Errorf("%v m values for %v points", len(m), n)
+	}
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	if len(ends) == 0 {
+		ends = []uint32{uint32(n)}
+	}
+
+	rings := make([][][]float64, len(ends))
+	start := 0
+	for i, e := range ends {
+		end := int(e)
+		if end < start || end > n {
+			return nil, fmt.Errorf("invalid end %v of %v points", end, n)
+		}
+		r := make([][]float64, 0, end-start)
+		for j := start; j < end; j++ {
+			pt := []float64{xy[2*j], xy[2*j+1]}
+			if hasZ {
+				pt = append(pt, z[j])
+			}
+			if hasM {
+				pt = append(pt, m[j])
+			}
+			r = append(r, pt)
+		}
+		rings[i] = r
+		start = end
+	}
+	return rings, nil
+}
+
+// decodeExtent returns the extent of the x and y values of the Geometry table, nil
+// if it has none
+func decodeExtent(t fbReader) *geom.Extent {
+	var ext *geom.Extent
+	xy := t.float64s(geometryXY)
+	for i := 0; i+1 < len(xy); i += 2 {
+		ext = addExtent(ext, &geom.Extent{xy[i], xy[i+1], xy[i], xy[i+1]})
+	}
+	for _, p := range t.tables(geometryParts) {
+		ext = addExtent(ext, decodeExtent(p))
+	}
+	return ext
+}
+
+// newGeometry returns the geom type of the geometry type and dimensions, points are
+// nil when empty.
+func newGeometry(typ GeometryType, hasZ, hasM bool, rings [][][]float64) geom.Geometry {
+	var pts [][]float64
+	if len(rings) > 0 {
+		pts = rings[0]
+	}
+	if typ == Point && len(pts) == 0 {
+		return nil
+	}
+
+	switch {
+	case hasZ && hasM:
+		switch typ {
+		case Point:
+			return geom.PointZM(pts4(pts)[0])
+		case LineString:
+			return geom.LineStringZM(pts4(pts))
+		case MultiPoint:
+			return geom.MultiPointZM(pts4(pts))
+		case MultiLineString:
+			return geom.MultiLineStringZM(lines4(rings))
+		default:
+			return geom.PolygonZM(lines4(rings))
+		}
+	case hasZ:
+		switch typ {
+		case Point:
+			return geom.PointZ(pts3(pts)[0])
+		case LineString:
+			return geom.LineStringZ(pts3(pts))
+		case MultiPoint:
+			return geom.MultiPointZ(pts3(pts))
+		case MultiLineString:
+			return geom.MultiLineStringZ(lines3(rings))
+		default:
+			return geom.PolygonZ(lines3(rings))
+		}
+	case hasM:
+		switch typ {
+		case Point:
+			return geom.PointM(pts3(pts)[0])
+		case LineString:
+			return geom.LineStringM(pts3(pts))
+		case MultiPoint:
+			return geom.MultiPointM(pts3(pts))
+		case MultiLineString:
+			return geom.MultiLineStringM(lines3(rings))
+		default:
+			return geom.PolygonM(lines3(rings))
+		}
+	default:
+		switch typ {
+		case Point:
+			return geom.Point(pts2(pts)[0])
+		case LineString:
+			return geom.LineString(pts2(pts))
+		case MultiPoint:
+			return geom.MultiPoint(pts2(pts))
+		case MultiLineString:
+			return geom.MultiLineString(lines2(rings))
+		default:
+			return geom.Polygon(lines2(rings))
+		}
+	}
+}
+
+// newShape returns the shape of the geometry
+func newShape(geo geom.Geometry) (shape, error) {
+	one := func(pts [][]float64) [][][][]float64 { return [][][][]float64{{pts}} }
+
+	switch g := geo.(type) {
+	// the z and m types implement the 2d interfaces, so are matched first
+	case geom.PointZ:
+		return shape{typ: Point, hasZ: true, polys: one(coords3(g))}, nil
+	case geom.PointM:
+		return shape{typ: Point, hasM: true, polys: one(coords3(g))}, nil
+	case geom.PointZM:
+		return shape{typ: Point, hasZ: true, hasM: true, polys: one(coords4(g))}, nil
+	case geom.LineStringZ:
+		return shape{typ: LineString, hasZ: true, polys: one(coords3(g...))}, nil
+	case geom.LineStringM:
+		return shape{typ: LineString, hasM: true, polys: one(coords3(g...))}, nil
+	case geom.LineStringZM:
+		return shape{typ: LineString, hasZ: true, hasM: true, polys: one(coords4(g...))}, nil
+	case geom.MultiPointZ:
+		return shape{typ: MultiPoint, hasZ: true, polys: one(coords3(g...))}, nil
+	case geom.MultiPointM:
+		return shape{typ: MultiPoint, hasM: true, polys: one(coords3(g...))}, nil
+	case geom.MultiPointZM:
+		return shape{typ: MultiPoint, hasZ: true, hasM: true, polys: one(coords4(g...))}, nil
+	case geom.PolygonZ:
+		return shape{typ: Polygon, hasZ: true, polys: [][][][]float64{rings3(g)}}, nil
+	case geom.PolygonM:
+		return shape{typ: Polygon, hasM: true, polys: [][][][]float64{rings3(g)}}, nil
+	case geom.PolygonZM:
+		return shape{typ: Polygon, hasZ: true, hasM: true, polys: [][][][]float64{rings4(g)}}, nil
+	case geom.MultiLineStringZ:
+		return shape{typ: MultiLineString, hasZ: true, polys: [][][][]float64{rings3(g)}}, nil
+	case geom.MultiLineStringM:
+		return shape{typ: MultiLineString, hasM: true, polys: [][][][]float64{rings3(g)}}, nil
+	case geom.MultiLineStringZM:
+		return shape{typ: MultiLineString, hasZ: true, hasM: true, polys: [][][][]float64{rings4(g)}}, nil
+
+	case *geom.MultiPolygon:
+		if g == nil {
+			return shape{}, encoding.ErrUnknownGeometry{Geom: geo}
+		}
+		return newShape(*g)
+
+	case geom.Pointer:
+		return shape{typ: Point, polys: one(coords2(g.XY()))}, nil
+	case geom.MultiPointer:
+		return shape{typ: MultiPoint, polys: one(coords2(g.Points()...))}, nil
+	case geom.LineStringer:
+		return shape{typ: LineString, polys: one(coords2(g.Vertices()...))}, nil
+	case geom.MultiLineStringer:
+		return shape{typ: MultiLineString, polys: [][][][]float64{rings2(g.LineStrings())}}, nil
+	case geom.Polygoner:
+		return shape{typ: Polygon, polys: [][][][]float64{rings2(g.LinearRings())}}, nil
+	case geom.MultiPolygoner:
+		plys := g.Polygons()
+		polys := make([][][][]float64, len(plys))
+		for i := range plys {
+			polys[i] = rings2(plys[i])
+		}
+		return shape{typ: MultiPolygon, polys: polys}, nil
+	case geom.Collectioner:
+		return shape{typ: GeometryCollection, geoms: g.Geometries()}, nil
+
+	default:
+		return shape{}, encoding.ErrUnknownGeometry{Geom: geo}
+	}
+}
+
+// closeRing returns the ring with the first point repeated at the end
+func closeRing(r [][]float64) [][]float64 {
+	if len(r) == 0 {
+		return r
+	}
+	first, last := r[0], r[len(r)-1]
+	for d := range first {
+		if first[d] != last[d] {
+			return append(r[:len(r):len(r)], first)
+		}
+	}
+	return r
+}
+
+// openRings returns the rings without the repeated last point
+func openRings(rings [][][]float64) [][][]float64 {
+	for i, r := range rings {
+		if len(r) < 2 {
+			continue
+		}
+		first, last := r[0], r[len(r)-1]
+		closed := true
+		for d := range first {
+			closed = closed && first[d] == last[d]
+		}
+		if closed {
+			rings[i] = r[:len(r)-1]
+		}
+	}
+	return rings
+}
+
+func coords2(pts ...[2]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func coords3(pts ...[3]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func coords4(pts ...[4]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func rings2(rs [][][2]float64) [][][]float64 {
+	cs := make([][][]float64, len(rs))
+	for i := range rs {
+		cs[i] = coords2(rs[i]...)
+	}
+	return cs
+}
+
+func rings3(rs [][][3]float64) [][][]float64 {
+	cs := make([][][]float64, len(rs))
+	for i := range rs {
+		cs[i] = coords3(rs[i]...)
+	}
+	return cs
+}
+
+func rings4(rs [][][4]float64) [][][]float64 {
+	cs := make([][][]float64, len(rs))
+	for i := range rs {
+		cs[i] = coords4(rs[i]...)
+	}
+	return cs
+}
+
+func pts2(cs [][]float64) [][2]float64 {
+	pts := make([][2]float64, len(cs))
+	for i, c := range cs {
+		copy(pts[i][:], c)
+	}
+	return pts
+}
+
+func pts3(cs [][]float64) [][3]float64 {
+	pts := make([][3]float64, len(cs))
+	for i, c := range cs {
+		copy(pts[i][:], c)
+	}
+	return pts
+}
+
+func pts4(cs [][]float64) [][4]float64 {
+	pts := make([][4]float64, len(cs))
+	for i, c := range cs {
+		copy(pts[i][:], c)
+	}
+	return pts
+}
+
+func lines2(rs [][][]float64) [][][2]float64 {
+	ls := make([][][2]float64, len(rs))
+	for i := range rs {
+		ls[i] = pts2(rs[i])
+	}
+	return ls
+}
+
+func lines3(rs [][][]float64) [][][3]float64 {
+	ls := make([][][3]float64, len(rs))
+	for i := range rs {
+		ls[i] = pts3(rs[i])
+	}
+	return ls
+}
+
+func lines4(rs [][][]float64) [][][4]float64 {
+	ls := make([][][4]float64, len(rs))
+	for i := range rs {
+		ls[i] = pts4(rs[i])
+	}
+	return ls
+}
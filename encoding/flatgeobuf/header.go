@@ -0,0 +1,163 @@
+package flatgeobuf
+
+import (
+	"github.com/hahaking119/geom"
+)
+
+// field ids of the Header table
+const (
+	headerName = iota
+	headerEnvelope
+	headerGeometryType
+	headerHasZ
+	headerHasM
+	headerHasT
+	headerHasTM
+	headerColumns
+	headerFeaturesCount
+	headerIndexNodeSize
+	headerCRS
+	headerTitle
+	headerDescription
+	headerMetadata
+)
+
+// field ids of the Column table
+const (
+	columnName = iota
+	columnType
+	columnTitle
+	columnDescription
+	columnWidth
+	columnPrecision
+	columnScale
+	columnNullable
+	columnUnique
+	columnPrimaryKey
+	columnMetadata
+)
+
+// field ids of the Crs table
+const (
+	crsOrg = iota
+	crsCode
+	crsName
+	crsDescription
+	crsWKT
+	crsCodeString
+)
+
+// optString returns nil for the empty string, so it is left out
+func optString(s string) fbValue {
+	if s == "" {
+		return nil
+	}
+	return fbString(s)
+}
+
+func (hdr Header) table() fbTable {
+	t := make(fbTable, headerMetadata+1)
+	t[headerName] = optString(hdr.Name)
+	if hdr.Envelope != nil {
+		t[headerEnvelope] = fbFloat64s(hdr.Envelope[:])
+	}
+	t[headerGeometryType] = fbUint8(uint8(hdr.GeometryType))
+	t[headerHasZ] = fbBool(hdr.HasZ)
+	t[headerHasM] = fbBool(hdr.HasM)
+	if len(hdr.Columns) > 0 {
+		t[headerColumns] = columnsTable(hdr.Columns)
+	}
+	t[headerFeaturesCount] = fbUint64(hdr.FeaturesCount)
+	// the default of the schema is 16, so 0 is always written
+	t[headerIndexNodeSize] = fbUint16(hdr.IndexNodeSize)
+	if hdr.CRS != nil {
+		t[headerCRS] = fbTable{
+			crsOrg:         optString(hdr.CRS.Org),
+			crsCode:        fbInt32(hdr.CRS.Code),
+			crsName:        optString(hdr.CRS.Name),
+			crsDescription: optString(hdr.CRS.Description),
+			crsWKT:         optString(hdr.CRS.WKT),
+			crsCodeString:  optString(hdr.CRS.CodeString),
+		}
+	}
+	t[headerTitle] = optString(hdr.Title)
+	t[headerDescription] = optString(hdr.Description)
+	t[headerMetadata] = optString(hdr.Metadata)
+	return t
+}
+
+func columnsTable(cols []Column) fbTables {
+	ts := make(fbTables, len(cols))
+	for i, c := range cols {
+		ts[i] = fbTable{
+			columnName:        fbString(c.Name),
+			columnType:        fbUint8(uint8(c.Type)),
+			columnTitle:       optString(c.Title),
+			columnDescription: optString(c.Description),
+			columnWidth:       fbInt32(c.Width),
+			columnPrecision:   fbInt32(c.Precision),
+			columnScale:       fbInt32(c.Scale),
+			columnNullable:    fbBool(c.Nullable),
+			columnUnique:      fbBool(c.Unique),
+			columnPrimaryKey:  fbBool(c.PrimaryKey),
+			columnMetadata:    optString(c.Metadata),
+		}
+	}
+	return ts
+}
+
+// decodeHeader returns the header of the flatbuffer, without its size prefix
+func decodeHeader(buf []byte) (Header, error) {
+	var err error
+	t := fbRoot(buf, &err)
+
+	hdr := Header{
+		Name:          t.string(headerName),
+		GeometryType:  GeometryType(t.uint8(headerGeometryType, 0)),
+		HasZ:          t.bool(headerHasZ, false),
+		HasM:          t.bool(headerHasM, false),
+		Columns:       decodeColumns(t.tables(headerColumns)),
+		FeaturesCount: t.uint64(headerFeaturesCount, 0),
+		IndexNodeSize: t.uint16(headerIndexNodeSize, DefaultIndexNodeSize),
+		Title:         t.string(headerTitle),
+		Description:   t.string(headerDescription),
+		Metadata:      t.string(headerMetadata),
+	}
+	if env := t.float64s(headerEnvelope); len(env) >= 4 {
+		hdr.Envelope = &geom.Extent{env[0], env[1], env[2], env[3]}
+	}
+	if crs, ok := t.table(headerCRS); ok {
+		hdr.CRS = &CRS{
+			Org:         crs.string(crsOrg),
+			Code:        crs.int32(crsCode, 0),
+			Name:        crs.string(crsName),
+			Description: crs.string(crsDescription),
+			WKT:         crs.string(crsWKT),
+			CodeString:  crs.string(crsCodeString),
+		}
+	}
+	return hdr, err
+}
+
+func decodeColumns(ts []fbReader) []Column {
+	if len(ts) == 0 {
+		return nil
+	}
+	cols := make([]Column, len(ts))
+	for i, c := range ts {
+		cols[i] = Column{
+			Name:        c.string(columnName),
+			Type:        ColumnType(c.uint8(columnType, 0)),
+			Title:       c.string(columnTitle),
+			Description: c.string(columnDescription),
+			Width:       c.int32(columnWidth, -1),
+			Precision:   c.int32(columnPrecision, -1),
+			Scale:       c.int32(columnScale, -1),
+			Nullable:    c.bool(columnNullable, true),
+			Unique:      c.bool(columnUnique, false),
+			PrimaryKey:  c.bool(columnPrimaryKey, false),
+			Metadata:    c.string(columnMetadata),
+		}
+	}
+	return cols
+}
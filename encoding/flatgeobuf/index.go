@@ -0,0 +1,215 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// The index is a packed Hilbert R-tree: the features are sorted by the Hilbert value
+// of the center of their bounding boxes, the leaves are the bounding boxes of the
+// features and each node above has the bounding box of up to node size nodes of the
+// level below. The nodes are written from the root down, each level after the other.
+
+// nodeItemSize is the size of a node: the bounding box and an offset
+const nodeItemSize = 4*8 + 8
+
+// nodeItem is a node of the index; the offset of a leaf is the offset of the feature
+// in the features section, of the other nodes the index of their first child.
+type nodeItem struct {
+	minX, minY, maxX, maxY float64
+	offset                 uint64
+}
+
+// emptyNode has a bounding box that intersects nothing, used for empty geometries
+var emptyNode = nodeItem{
+	minX: math.Inf(1), minY: math.Inf(1),
+	maxX: math.Inf(-1), maxY: math.Inf(-1),
+}
+
+func (n nodeItem) intersects(o nodeItem) bool {
+	return n.minX <= o.maxX && n.minY <= o.maxY && n.maxX >= o.minX && n.maxY >= o.minY
+}
+
+func (n *nodeItem) expand(o nodeItem) {
+	n.minX = math.Min(n.minX, o.minX)
+	n.minY = math.Min(n.minY, o.minY)
+	n.maxX = math.Max(n.maxX, o.maxX)
+	n.maxY = math.Max(n.maxY, o.maxY)
+}
+
+func (n nodeItem) bytes() []byte {
+	b := make([]byte, nodeItemSize)
+	binary.LittleEndian.PutUint64(b[0:], math.Float64bits(n.minX))
+	binary.LittleEndian.PutUint64(b[8:], math.Float64bits(n.minY))
+	binary.LittleEndian.PutUint64(b[16:], math.Float64bits(n.maxX))
+	binary.LittleEndian.PutUint64(b[24:], math.Float64bits(n.maxY))
+	binary.LittleEndian.PutUint64(b[32:], n.offset)
+	return b
+}
+
+func readNodeItem(b []byte) nodeItem {
+	return nodeItem{
+		minX:   math.Float64frombits(binary.LittleEndian.Uint64(b[0:])),
+		minY:   math.Float64frombits(binary.LittleEndian.Uint64(b[8:])),
+		maxX:   math.Float64frombits(binary.LittleEndian.Uint64(b[16:])),
+		maxY:   math.Float64frombits(binary.LittleEndian.Uint64(b[24:])),
+		offset: binary.LittleEndian.Uint64(b[32:]),
+	}
+}
+
+// levelBounds returns the first and last, exclusive, node of each level, from the
+// leaves up to the root
+func levelBounds(numItems, nodeSize int) [][2]int {
+	n, numNodes := numItems, numItems
+	levelNumNodes := []int{n}
+	for {
+		n = (n + nodeSize - 1) / nodeSize
+		numNodes += n
+		levelNumNodes = append(levelNumNodes, n)
+		if n == 1 {
+			break
+		}
+	}
+	bounds := make([][2]int, len(levelNumNodes))
+	n = numNodes
+	for i, size := range levelNumNodes {
+		n -= size
+		bounds[i] = [2]int{n, n + size}
+	}
+	return bounds
+}
+
+// indexSize returns the size in bytes of the index
+func indexSize(numItems, nodeSize int) int {
+	if numItems == 0 || nodeSize < 2 {
+		return 0
+	}
+	bounds := levelBounds(numItems, nodeSize)
+	return bounds[0][1] * nodeItemSize
+}
+
+// buildIndex returns the nodes of the index of the leaves
+func buildIndex(leaves []nodeItem, nodeSize int) []nodeItem {
+	bounds := levelBounds(len(leaves), nodeSize)
+	nodes := make([]nodeItem, bounds[0][1])
+	copy(nodes[bounds[0][0]:], leaves)
+	for i := 0; i < len(bounds)-1; i++ {
+		pos, end, parent := bounds[i][0], bounds[i][1], bounds[i+1][0]
+		for pos < end {
+			node := emptyNode
+			node.offset = uint64(pos)
+			for j := 0; j < nodeSize && pos < end; j++ {
+				node.expand(nodes[pos])
+				pos++
+			}
+			nodes[parent] = node
+			parent++
+		}
+	}
+	return nodes
+}
+
+// searchIndex returns the offsets, in increasing order, of the features whose bounding
+// boxes intersect the box. The index is the bytes of all the nodes.
+func searchIndex(index []byte, numItems, nodeSize int, box nodeItem) ([]uint64, error) {
+	bounds := levelBounds(numItems, nodeSize)
+	leaves := bounds[0][0]
+
+	type entry struct{ node, level int }
+	var (
+		offsets []uint64
+		queue   = []entry{{node: 0, level: len(bounds) - 1}}
+	)
+	for len(queue) > 0 {
+		e := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		end := e.node + nodeSize
+		if end > bounds[e.level][1] {
+			end = bounds[e.level][1]
+		}
+		for pos := e.node; pos < end; pos++ {
+			node := readNodeItem(index[pos*nodeItemSize:])
+			if !box.intersects(node) {
+				continue
+			}
+			if pos >= leaves {
+				offsets = append(offsets, node.offset)
+				continue
+			}
+			child := bounds[e.level-1]
+			if node.offset < uint64(child[0]) || node.offset >= uint64(child[1]) {
+				return nil, fmt.Errorf("invalid index node offset %v", node.offset)
+			}
+			queue = append(queue, entry{node: int(node.offset), level: e.level - 1})
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets, nil
+}
+
+// hilbertMax is the largest coordinate of the grid the Hilbert values are computed on
+const hilbertMax = 1<<16 - 1
+
+// hilbertValue returns the Hilbert value of the center of the node within the extent
+func hilbertValue(n nodeItem, ext nodeItem) uint32 {
+	coord := func(v, min, span float64) uint32 {
+		if span == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+			return 0
+		}
+		return uint32(math.Floor(hilbertMax * (v - min) / span))
+	}
+	x := coord((n.minX+n.maxX)/2, ext.minX, ext.maxX-ext.minX)
+	y := coord((n.minY+n.maxY)/2, ext.minY, ext.maxY-ext.minY)
+	return hilbert(x, y)
+}
+
+// hilbert returns the Hilbert value of the 16 bit coordinates, using the algorithm
+// from https://github.com/rawrunprotected/hilbert_curves
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xFFFF ^ a
+	c := 0xFFFF ^ (x | y)
+	d := x & (y ^ 0xFFFF)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xFFFF ^ (i0 | a))
+
+	i0 = (i0 | (i0 << 8)) & 0x00FF00FF
+	i0 = (i0 | (i0 << 4)) & 0x0F0F0F0F
+	i0 = (i0 | (i0 << 2)) & 0x33333333
+	i0 = (i0 | (i0 << 1)) & 0x55555555
+
+	i1 = (i1 | (i1 << 8)) & 0x00FF00FF
+	i1 = (i1 | (i1 << 4)) & 0x0F0F0F0F
+	i1 = (i1 | (i1 << 2)) & 0x33333333
+	i1 = (i1 | (i1 << 1)) & 0x55555555
+
+	return (i1 << 1) | i0
+}
@@ -0,0 +1,231 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// encodeProperties returns the properties as the column index and value of each
+// property that is set, in the order of the columns
+func encodeProperties(cols []Column, props map[string]interface{}) ([]byte, error) {
+	for name := range props {
+		if columnIndex(cols, name) < 0 {
+			return nil, ErrUnknownColumn(name)
+		}
+	}
+
+	var buf []byte
+	for i, col := range cols {
+		v, ok := props[col.Name]
+		if !ok || v == nil {
+			continue
+		}
+		b, ok := encodeValue(col.Type, v)
+		if !ok {
+			return nil, ErrInvalidValue{Column: col.Name, Type: col.Type, Value: v}
+		}
+		buf = append(buf, byte(i), byte(i>>8))
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+func columnIndex(cols []Column, name string) int {
+	for i := range cols {
+		if cols[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// encodeValue returns the little endian bytes of the value as the column type. Any
+// integer that fits is accepted for integer columns, and any number for float columns.
+func encodeValue(typ ColumnType, v interface{}) ([]byte, bool) {
+	rv := reflect.ValueOf(v)
+	switch typ {
+	case Byte, Short, Int, Long:
+		i, ok := toInt(rv)
+		if !ok {
+			return nil, false
+		}
+		switch typ {
+		case Byte:
+			return []byte{byte(i)}, i >= math.MinInt8 && i <= math.MaxInt8
+		case Short:
+			return le(2, uint64(i)), i >= math.MinInt16 && i <= math.MaxInt16
+		case Int:
+			return le(4, uint64(i)), i >= math.MinInt32 && i <= math.MaxInt32
+		default:
+			return le(8, uint64(i)), true
+		}
+
+	case UByte, UShort, UInt, ULong:
+		u, ok := toUint(rv)
+		if !ok {
+			return nil, false
+		}
+		switch typ {
+		case UByte:
+			return []byte{byte(u)}, u <= math.MaxUint8
+		case UShort:
+			return le(2, u), u <= math.MaxUint16
+		case UInt:
+			return le(4, u), u <= math.MaxUint32
+		default:
+			return le(8, u), true
+		}
+
+	case Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, false
+		}
+		if b {
+			return []byte{1}, true
+		}
+		return []byte{0}, true
+
+	case Float, Double:
+		var f float64
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f = rv.Float()
+		default:
+			i, ok := toInt(rv)
+			if !ok {
+				return nil, false
+			}
+			f = float64(i)
+		}
+		if typ == Float {
+			return le(4, uint64(math.Float32bits(float32(f)))), true
+		}
+		return le(8, math.Float64bits(f)), true
+
+	case String, JSON, DateTime, Binary:
+		var b []byte
+		switch v := v.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			if typ != Binary {
+				return nil, false
+			}
+			b = v
+		case time.Time:
+			if typ != DateTime {
+				return nil, false
+			}
+			b = []byte(v.Format(time.RFC3339Nano))
+		default:
+			return nil, false
+		}
+		return append(le(4, uint64(len(b))), b...), true
+	}
+	return nil, false
+}
+
+func toInt(rv reflect.Value) (int64, bool) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		return int64(u), u <= math.MaxInt64
+	}
+	return 0, false
+}
+
+func toUint(rv reflect.Value) (uint64, bool) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := rv.Int()
+		return uint64(i), i >= 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), true
+	}
+	return 0, false
+}
+
+// le returns the n lowest bytes of v, little endian
+func le(n int, v uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	return b[:n]
+}
+
+// decodeProperties returns the properties keyed by column name
+func decodeProperties(cols []Column, buf []byte) (map[string]interface{}, error) {
+	props := make(map[string]interface{})
+	for pos := 0; pos < len(buf); {
+		if pos+2 > len(buf) {
+			return nil, fmt.Errorf("truncated properties")
+		}
+		i := int(binary.LittleEndian.Uint16(buf[pos:]))
+		pos += 2
+		if i >= len(cols) {
+			return nil, fmt.Errorf("invalid column index %v of %v columns", i, len(cols))
+		}
+
+		size := 0
+		switch cols[i].Type {
+		case Byte, UByte, Bool:
+			size = 1
+		case Short, UShort:
+			size = 2
+		case Int, UInt, Float:
+			size = 4
+		case Long, ULong, Double:
+			size = 8
+		case String, JSON, DateTime, Binary:
+			if pos+4 > len(buf) {
+				return nil, fmt.Errorf("truncated properties")
+			}
+			size = int(binary.LittleEndian.Uint32(buf[pos:]))
+			pos += 4
+		default:
+			return nil, fmt.Errorf("unknown column type %v", cols[i].Type)
+		}
+		if size < 0 || pos+size > len(buf) {
+			return nil, fmt.Errorf("truncated properties")
+		}
+		b := buf[pos : pos+size]
+		pos += size
+
+		var v interface{}
+		switch cols[i].Type {
+		case Byte:
+			v = int8(b[0])
+		case UByte:
+			v = b[0]
+		case Bool:
+			v = b[0] != 0
+		case Short:
+			v = int16(binary.LittleEndian.Uint16(b))
+		case UShort:
+			v = binary.LittleEndian.Uint16(b)
+		case Int:
+			v = int32(binary.LittleEndian.Uint32(b))
+		case UInt:
+			v = binary.LittleEndian.Uint32(b)
+		case Long:
+			v = int64(binary.LittleEndian.Uint64(b))
+		case ULong:
+			v = binary.LittleEndian.Uint64(b)
+		case Float:
+			v = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case Double:
+			v = math.Float64frombits(binary.LittleEndian.Uint64(b))
+		case Binary:
+			v = append([]byte(nil), b...)
+		default:
+			v = string(b)
+		}
+		props[cols[i].Name] = v
+	}
+	return props, nil
+}
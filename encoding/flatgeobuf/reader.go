@@ -0,0 +1,236 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/hahaking119/geom"
+)
+
+// Reader reads the features of a file one at a time. The features are read in the
+// order they are in the file, which for files with an index is along the Hilbert curve.
+type Reader struct {
+	Header Header
+
+	r io.Reader
+	// filter is the box the features must intersect, nil for all features
+	filter *nodeItem
+	// offsets are the offsets of the features left to read, found with the index
+	offsets    []uint64
+	useOffsets bool
+	started    bool
+	// pos is the position in the features section
+	pos uint64
+}
+
+// NewReader reads the magic bytes and header of the file, and returns a reader of its
+// features. When r is an io.Seeker the features that are filtered out are seeked past.
+func NewReader(r io.Reader) (*Reader, error) {
+	var m [8]byte
+	if _, err := io.ReadFull(r, m[:]); err != nil {
+		return nil, err
+	}
+	// the minor version, the 8th byte, may differ
+	if m[0] != magic[0] || m[1] != magic[1] || m[2] != magic[2] || m[3] != magic[3] ||
+		m[4] != magic[4] || m[5] != magic[5] || m[6] != magic[6] {
+		return nil, ErrInvalidMagic(m)
+	}
+
+	buf, err := readSizePrefixed(r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := decodeHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{Header: hdr, r: r}, nil
+}
+
+// Decode returns all the features of the file that intersect the extent, a nil
+// extent for all features
+func Decode(r io.Reader, ext *geom.Extent) (Header, []Feature, error) {
+	rd, err := NewReader(r)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	rd.Filter(ext)
+	var features []Feature
+	for {
+		f, err := rd.Next()
+		if err == io.EOF {
+			return rd.Header, features, nil
+		}
+		if err != nil {
+			return rd.Header, nil, err
+		}
+		features = append(features, f)
+	}
+}
+
+// Filter sets the extent that the bounding boxes of the features returned by Next must
+// intersect, nil for all features. It has no effect after the first call to Next. The
+// index is used if the file has one, otherwise each feature is read and checked.
+func (rd *Reader) Filter(ext *geom.Extent) {
+	if rd.started {
+		return
+	}
+	if ext == nil {
+		rd.filter = nil
+		return
+	}
+	rd.filter = &nodeItem{minX: ext.MinX(), minY: ext.MinY(), maxX: ext.MaxX(), maxY: ext.MaxY()}
+}
+
+// Next returns the next feature, or io.EOF when there are no more features
+func (rd *Reader) Next() (Feature, error) {
+	if !rd.started {
+		rd.started = true
+		if err := rd.readIndex(); err != nil {
+			return Feature{}, err
+		}
+	}
+
+	for {
+		if rd.useOffsets {
+			if len(rd.offsets) == 0 {
+				return Feature{}, io.EOF
+			}
+			offset := rd.offsets[0]
+			rd.offsets = rd.offsets[1:]
+			if offset < rd.pos {
+				return Feature{}, fmt.Errorf("invalid feature offset %v", offset)
+			}
+			if err := rd.skip(offset - rd.pos); err != nil {
+				return Feature{}, err
+			}
+			rd.pos = offset
+		}
+
+		buf, err := readSizePrefixed(rd.r)
+		if err != nil {
+			return Feature{}, err
+		}
+		rd.pos += 4 + uint64(len(buf))
+
+		var ferr error
+		t := fbRoot(buf, &ferr)
+		if rd.filter != nil && !rd.useOffsets {
+			ext := featureExtent(t)
+			if ferr != nil {
+				return Feature{}, ferr
+			}
+			if ext == nil || !rd.filter.intersects(nodeItem{minX: ext.MinX(), minY: ext.MinY(), maxX: ext.MaxX(), maxY: ext.MaxY()}) {
+				continue
+			}
+		}
+		return rd.decodeFeature(t)
+	}
+}
+
+// readIndex reads the index, when filtering, or skips it
+func (rd *Reader) readIndex() error {
+	hdr := rd.Header
+	if hdr.IndexNodeSize < 2 || hdr.FeaturesCount == 0 {
+		return nil
+	}
+	if hdr.FeaturesCount > uint64(maxInt/nodeItemSize/2) {
+		return fmt.Errorf("too many features for the index %v", hdr.FeaturesCount)
+	}
+	numItems, nodeSize := int(hdr.FeaturesCount), int(hdr.IndexNodeSize)
+	size := indexSize(numItems, nodeSize)
+	if rd.filter == nil {
+		return rd.skip(uint64(size))
+	}
+
+	var index bytes.Buffer
+	if _, err := io.CopyN(&index, rd.r, int64(size)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	offsets, err := searchIndex(index.Bytes(), numItems, nodeSize, *rd.filter)
+	if err != nil {
+		return err
+	}
+	rd.offsets, rd.useOffsets = offsets, true
+	return nil
+}
+
+// maxInt is the largest int
+const maxInt = int(^uint(0) >> 1)
+
+// skip reads past n bytes
+func (rd *Reader) skip(n uint64) error {
+	if n == 0 {
+		return nil
+	}
+	if s, ok := rd.r.(io.Seeker); ok {
+		_, err := s.Seek(int64(n), io.SeekCurrent)
+		return err
+	}
+	_, err := io.CopyN(ioutil.Discard, rd.r, int64(n))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (rd *Reader) decodeFeature(t fbReader) (Feature, error) {
+	var f Feature
+	cols := rd.Header.Columns
+	// a feature may have its own columns
+	if fcols := decodeColumns(t.tables(featureColumns)); fcols != nil {
+		cols = fcols
+	}
+	if g, ok := t.table(featureGeometry); ok {
+		geo, err := decodeGeometry(g, rd.Header.GeometryType, rd.Header.HasZ, rd.Header.HasM)
+		if err != nil {
+			return Feature{}, err
+		}
+		f.Geometry = geo
+	}
+	props := t.bytes(featureProperties)
+	if *t.err != nil {
+		return Feature{}, *t.err
+	}
+	var err error
+	if f.Properties, err = decodeProperties(cols, props); err != nil {
+		return Feature{}, err
+	}
+	return f, nil
+}
+
+// featureExtent returns the extent of the feature's geometry, nil if it has none
+func featureExtent(t fbReader) *geom.Extent {
+	g, ok := t.table(featureGeometry)
+	if !ok {
+		return nil
+	}
+	return decodeExtent(g)
+}
+
+// readSizePrefixed returns the bytes of a size prefixed flatbuffer, without the size.
+// io.EOF is returned only if there are no bytes.
+func readSizePrefixed(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	n := int64(binary.LittleEndian.Uint32(size[:]))
+	if _, err := io.CopyN(&buf, r, n); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
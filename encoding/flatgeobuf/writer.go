@@ -0,0 +1,161 @@
+package flatgeobuf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// field ids of the Feature table
+const (
+	featureGeometry = iota
+	featureProperties
+	featureColumns
+)
+
+// Writer writes features to a file. The header has the extent and number of the
+// features, and the index needs all of them sorted, so the features are kept in
+// memory, encoded, until Close.
+type Writer struct {
+	w   io.Writer
+	hdr Header
+
+	features [][]byte
+	nodes    []nodeItem
+	// typed and dims are whether the geometry type and dimensions are known
+	typed, dims bool
+	closed      bool
+}
+
+// NewWriter returns a writer of the features with the header's name, columns, index
+// node size, crs and descriptions. The other fields of the header are set from the
+// features.
+func NewWriter(w io.Writer, hdr Header) *Writer {
+	hdr.Columns = append([]Column(nil), hdr.Columns...)
+	hdr.Envelope, hdr.GeometryType, hdr.HasZ, hdr.HasM = nil, Unknown, false, false
+	return &Writer{w: w, hdr: hdr}
+}
+
+// Encode writes the features to w
+func Encode(w io.Writer, hdr Header, features ...Feature) error {
+	fw := NewWriter(w, hdr)
+	for _, f := range features {
+		if err := fw.Write(f); err != nil {
+			return err
+		}
+	}
+	return fw.Close()
+}
+
+// Write encodes the feature; the properties must be columns of the header and all the
+// geometries must have the same dimensions. A nil geometry is allowed.
+func (w *Writer) Write(f Feature) error {
+	if w.closed {
+		return fmt.Errorf("write to closed writer")
+	}
+	if len(w.hdr.Columns) > math.MaxUint16+1 {
+		return fmt.Errorf("too many columns %v", len(w.hdr.Columns))
+	}
+
+	t := make(fbTable, featureProperties+1)
+	node := emptyNode
+	if f.Geometry != nil {
+		gt, info, err := encodeGeometry(f.Geometry)
+		if err != nil {
+			return err
+		}
+		if info.dims {
+			if w.dims && (info.hasZ != w.hdr.HasZ || info.hasM != w.hdr.HasM) {
+				return ErrMixedDimensions{HasZ: info.hasZ, HasM: info.hasM}
+			}
+			w.dims, w.hdr.HasZ, w.hdr.HasM = true, info.hasZ, info.hasM
+		}
+		if !w.typed {
+			w.typed, w.hdr.GeometryType = true, info.typ
+		} else if w.hdr.GeometryType != info.typ {
+			w.hdr.GeometryType = Unknown
+		}
+		if info.ext != nil {
+			node = nodeItem{minX: info.ext.MinX(), minY: info.ext.MinY(), maxX: info.ext.MaxX(), maxY: info.ext.MaxY()}
+			w.hdr.Envelope = addExtent(w.hdr.Envelope, info.ext)
+		}
+		t[featureGeometry] = gt
+	}
+
+	props, err := encodeProperties(w.hdr.Columns, f.Properties)
+	if err != nil {
+		return err
+	}
+	if len(props) > 0 {
+		t[featureProperties] = fbVector{elemSize: 1, data: props}
+	}
+
+	w.features = append(w.features, fbBuild(t))
+	w.nodes = append(w.nodes, node)
+	return nil
+}
+
+// Close writes the header, index and features to the underlying writer
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	nodeSize := int(w.hdr.IndexNodeSize)
+	if nodeSize == 1 {
+		return fmt.Errorf("invalid index node size %v", nodeSize)
+	}
+	w.hdr.FeaturesCount = uint64(len(w.features))
+	if len(w.features) == 0 {
+		nodeSize = 0
+		w.hdr.IndexNodeSize = 0
+	}
+
+	order := make([]int, len(w.features))
+	for i := range order {
+		order[i] = i
+	}
+	if nodeSize > 0 && w.hdr.Envelope != nil {
+		ext := nodeItem{
+			minX: w.hdr.Envelope.MinX(), minY: w.hdr.Envelope.MinY(),
+			maxX: w.hdr.Envelope.MaxX(), maxY: w.hdr.Envelope.MaxY(),
+		}
+		values := make([]uint32, len(w.nodes))
+		for i, n := range w.nodes {
+			values[i] = hilbertValue(n, ext)
+		}
+		sort.SliceStable(order, func(i, j int) bool { return values[order[i]] > values[order[j]] })
+	}
+
+	if _, err := w.w.Write(magic[:]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(fbBuild(w.hdr.table())); err != nil {
+		return err
+	}
+
+	if nodeSize > 0 {
+		leaves := make([]nodeItem, len(order))
+		var offset uint64
+		for i, fi := range order {
+			leaves[i] = w.nodes[fi]
+			leaves[i].offset = offset
+			offset += uint64(len(w.features[fi]))
+		}
+		for _, n := range buildIndex(leaves, nodeSize) {
+			if _, err := w.w.Write(n.bytes()); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, fi := range order {
+		if _, err := w.w.Write(w.features[fi]); err != nil {
+			return err
+		}
+	}
+	w.features, w.nodes = nil, nil
+	return nil
+}
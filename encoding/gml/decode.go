@@ -0,0 +1,560 @@
+package gml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hahaking119/geom"
+)
+
+// Unmarshal returns the first geometry of the GML
+func Unmarshal(data []byte) (geom.Geometry, error) {
+	return Decode(bytes.NewReader(data))
+}
+
+// Decode reads the first geometry element of the reader, which may be within other
+// elements such as the features of a WFS response
+func Decode(r io.Reader) (geom.Geometry, error) {
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no geometry element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && geometryElements[start.Name.Local] {
+			return DecodeElement(d, start)
+		}
+	}
+}
+
+// geometryElements are the names of the geometry elements that are decoded
+var geometryElements = map[string]bool{
+	"Point":           true,
+	"LineString":      true,
+	"LinearRing":      true,
+	"Curve":           true,
+	"Polygon":         true,
+	"Surface":         true,
+	"MultiPoint":      true,
+	"MultiCurve":      true,
+	"MultiLineString": true,
+	"MultiSurface":    true,
+	"MultiPolygon":    true,
+	"MultiGeometry":   true,
+}
+
+// DecodeElement reads the geometry of the start element, which must be a geometry
+// element, from the decoder
+func DecodeElement(d *xml.Decoder, start xml.StartElement) (geom.Geometry, error) {
+	n, err := readNode(d, start)
+	if err != nil {
+		return nil, err
+	}
+	geo, err := decodeGeometry(n, 2)
+	if err != nil {
+		return nil, err
+	}
+	if latFirst(n.attrs["srsName"]) {
+		if geo, err = swapAxes(geo); err != nil {
+			return nil, err
+		}
+	}
+	if srid, ok := SRID(n.attrs["srsName"]); ok {
+		return withSRID(geo, srid), nil
+	}
+	return geo, nil
+}
+
+// node is an element of a document
+type node struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*node
+}
+
+// readNode reads the element of the start element, with the local names of its
+// attributes and children
+func readNode(d *xml.Decoder, start xml.StartElement) (*node, error) {
+	n := &node{name: start.Name.Local, attrs: make(map[string]string)}
+	for _, attr := range start.Attr {
+		n.attrs[attr.Name.Local] = attr.Value
+	}
+	var text strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			c, err := readNode(d, tok)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, c)
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			n.text = text.String()
+			return n, nil
+		}
+	}
+}
+
+// child returns the first child with one of the names
+func (n *node) child(names ...string) *node {
+	for _, c := range n.children {
+		for _, name := range names {
+			if c.name == name {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// members returns the geometry elements of the member elements with one of the names
+func (n *node) members(names ...string) ([]*node, error) {
+	var members []*node
+	for _, c := range n.children {
+		for _, name := range names {
+			if c.name != name {
+				continue
+			}
+			if len(c.children) == 0 {
+				return nil, fmt.Errorf("%v without a geometry", c.name)
+			}
+			members = append(members, c.children...)
+		}
+	}
+	return members, nil
+}
+
+// dims returns the srsDimension of the element, or the inherited one
+func (n *node) dims(inherited int) (int, error) {
+	s, ok := n.attrs["srsDimension"]
+	if !ok {
+		return inherited, nil
+	}
+	dims, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || dims < 2 || dims > 3 {
+		return 0, fmt.Errorf("unsupported srsDimension %q", s)
+	}
+	return dims, nil
+}
+
+// decodeGeometry returns the geometry of the element; dims is the inherited srsDimension
+func decodeGeometry(n *node, dims int) (geom.Geometry, error) {
+	dims, err := n.dims(dims)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.name {
+	case "Point":
+		pts, hasZ, err := decodePositions(n, dims)
+		if err != nil {
+			return nil, err
+		}
+		if len(pts) != 1 {
+			return nil, fmt.Errorf("point with %v positions", len(pts))
+		}
+		if hasZ {
+			return geom.PointZ(pts[0]), nil
+		}
+		return geom.Point{pts[0][0], pts[0][1]}, nil
+
+	case "LineString", "LinearRing", "Curve":
+		line, hasZ, err := decodeLine(n, dims)
+		if err != nil {
+			return nil, err
+		}
+		if hasZ {
+			return geom.LineStringZ(line), nil
+		}
+		return geom.LineString(points2(line)), nil
+
+	case "Polygon", "Surface":
+		polys, hasZ, err := decodePolygons(n, dims)
+		if err != nil {
+			return nil, err
+		}
+		if len(polys) == 1 {
+			return newPolygon(polys[0], hasZ), nil
+		}
+		return newMultiPolygon(polys, hasZ), nil
+
+	case "MultiPoint":
+		members, err := n.members("pointMember", "pointMembers")
+		if err != nil {
+			return nil, err
+		}
+		var (
+			mp   = make(geom.MultiPointZ, len(members))
+			hasZ bool
+		)
+		for i, m := range members {
+			if m.name != "Point" {
+				return nil, ErrUnknownElement(m.name)
+			}
+			mdims, err := m.dims(dims)
+			if err != nil {
+				return nil, err
+			}
+			pts, z, err := decodePositions(m, mdims)
+			if err != nil {
+				return nil, err
+			}
+			if len(pts) != 1 {
+				return nil, fmt.Errorf("point with %v positions", len(pts))
+			}
+			mp[i], hasZ = pts[0], hasZ || z
+		}
+		if hasZ {
+			return mp, nil
+		}
+		return geom.MultiPoint(points2(mp)), nil
+
+	case "MultiCurve", "MultiLineString":
+		members, err := n.members("curveMember", "curveMembers", "lineStringMember")
+		if err != nil {
+			return nil, err
+		}
+		var (
+			mls  = make(geom.MultiLineStringZ, len(members))
+			hasZ bool
+		)
+		for i, m := range members {
+			var z bool
+			if mls[i], z, err = decodeLine(m, dims); err != nil {
+				return nil, err
+			}
+			hasZ = hasZ || z
+		}
+		if hasZ {
+			return mls, nil
+		}
+		ml := make(geom.MultiLineString, len(mls))
+		for i, ls := range mls {
+			ml[i] = points2(ls)
+		}
+		return ml, nil
+
+	case "MultiSurface", "MultiPolygon":
+		members, err := n.members("surfaceMember", "surfaceMembers", "polygonMember")
+		if err != nil {
+			return nil, err
+		}
+		var (
+			polys [][][][3]float64
+			hasZ  bool
+		)
+		for _, m := range members {
+			p, z, err := decodePolygons(m, dims)
+			if err != nil {
+				return nil, err
+			}
+			polys, hasZ = append(polys, p...), hasZ || z
+		}
+		return newMultiPolygon(polys, hasZ), nil
+
+	case "MultiGeometry":
+		members, err := n.members("geometryMember", "geometryMembers")
+		if err != nil {
+			return nil, err
+		}
+		col := make(geom.Collection, len(members))
+		for i, m := range members {
+			if col[i], err = decodeGeometry(m, dims); err != nil {
+				return nil, err
+			}
+		}
+		return col, nil
+
+	default:
+		return nil, ErrUnknownElement(n.name)
+	}
+}
+
+// decodeLine returns the positions of a line string, linear ring or curve of line
+// string segments
+func decodeLine(n *node, dims int) ([][3]float64, bool, error) {
+	dims, err := n.dims(dims)
+	if err != nil {
+		return nil, false, err
+	}
+	switch n.name {
+	case "LineString", "LinearRing", "LineStringSegment":
+		return decodePositions(n, dims)
+
+	case "Curve":
+		segs := n.child("segments")
+		if segs == nil {
+			return nil, false, fmt.Errorf("curve without segments")
+		}
+		var (
+			line [][3]float64
+			hasZ bool
+		)
+		for _, seg := range segs.children {
+			if seg.name != "LineStringSegment" {
+				return nil, false, ErrUnknownElement(seg.name)
+			}
+			pts, z, err := decodeLine(seg, dims)
+			if err != nil {
+				return nil, false, err
+			}
+			// segments share their end points
+			if len(line) > 0 && len(pts) > 0 && line[len(line)-1] == pts[0] {
+				pts = pts[1:]
+			}
+			line, hasZ = append(line, pts...), hasZ || z
+		}
+		return line, hasZ, nil
+
+	default:
+		return nil, false, ErrUnknownElement(n.name)
+	}
+}
+
+// decodePolygons returns the rings of a polygon, or of the polygon patches of a
+// surface, without their closing positions
+func decodePolygons(n *node, dims int) ([][][][3]float64, bool, error) {
+	dims, err := n.dims(dims)
+	if err != nil {
+		return nil, false, err
+	}
+	switch n.name {
+	case "Polygon", "PolygonPatch":
+		var (
+			poly [][][3]float64
+			hasZ bool
+		)
+		for _, c := range n.children {
+			switch c.name {
+			case "exterior", "outerBoundaryIs":
+				if len(poly) > 0 {
+					return nil, false, fmt.Errorf("polygon with more than one exterior")
+				}
+			case "interior", "innerBoundaryIs":
+				if len(poly) == 0 {
+					return nil, false, fmt.Errorf("polygon interior before its exterior")
+				}
+			default:
+				continue
+			}
+			ring := c.child("LinearRing")
+			if ring == nil {
+				return nil, false, fmt.Errorf("%v without a linear ring", c.name)
+			}
+			pts, z, err := decodeLine(ring, dims)
+			if err != nil {
+				return nil, false, err
+			}
+			// rings are closed in GML and open in geom
+			if n := len(pts); n > 1 && pts[0] == pts[n-1] {
+				pts = pts[:n-1]
+			}
+			poly, hasZ = append(poly, pts), hasZ || z
+		}
+		return [][][][3]float64{poly}, hasZ, nil
+
+	case "Surface":
+		patches := n.child("patches")
+		if patches == nil {
+			return nil, false, fmt.Errorf("surface without patches")
+		}
+		var (
+			polys [][][][3]float64
+			hasZ  bool
+		)
+		for _, patch := range patches.children {
+			if patch.name != "PolygonPatch" {
+				return nil, false, ErrUnknownElement(patch.name)
+			}
+			p, z, err := decodePolygons(patch, dims)
+			if err != nil {
+				return nil, false, err
+			}
+			polys, hasZ = append(polys, p...), hasZ || z
+		}
+		return polys, hasZ, nil
+
+	default:
+		return nil, false, ErrUnknownElement(n.name)
+	}
+}
+
+// decodePositions returns the positions of the pos, posList, pointProperty and
+// coordinates children of the element, and whether they have z values
+func decodePositions(n *node, dims int) ([][3]float64, bool, error) {
+	var (
+		pts  [][3]float64
+		hasZ bool
+	)
+	for _, c := range n.children {
+		switch c.name {
+		case "posList":
+			cdims, err := c.dims(dims)
+			if err != nil {
+				return nil, false, err
+			}
+			vals, err := parseFloats(strings.Fields(c.text))
+			if err != nil {
+				return nil, false, err
+			}
+			if len(vals)%cdims != 0 {
+				return nil, false, fmt.Errorf("posList of %v values with srsDimension %v", len(vals), cdims)
+			}
+			for i := 0; i < len(vals); i += cdims {
+				var pt [3]float64
+				copy(pt[:], vals[i:i+cdims])
+				pts = append(pts, pt)
+			}
+			hasZ = hasZ || cdims == 3
+
+		case "pos":
+			pt, z, err := parsePosition(strings.Fields(c.text))
+			if err != nil {
+				return nil, false, err
+			}
+			pts, hasZ = append(pts, pt), hasZ || z
+
+		case "pointProperty", "pointRep":
+			p := c.child("Point")
+			if p == nil {
+				return nil, false, fmt.Errorf("%v without a point", c.name)
+			}
+			ppts, z, err := decodePositions(p, dims)
+			if err != nil {
+				return nil, false, err
+			}
+			pts, hasZ = append(pts, ppts...), hasZ || z
+
+		case "coordinates":
+			cs, ts := c.attrs["cs"], c.attrs["ts"]
+			if cs == "" {
+				cs = ","
+			}
+			tuples := strings.Fields(c.text)
+			if ts != "" && strings.TrimSpace(ts) != "" {
+				tuples = strings.Split(strings.TrimSpace(c.text), ts)
+			}
+			for _, tuple := range tuples {
+				vals := strings.Split(strings.TrimSpace(tuple), cs)
+				if dec := c.attrs["decimal"]; dec != "" && dec != "." {
+					for i := range vals {
+						vals[i] = strings.Replace(vals[i], dec, ".", 1)
+					}
+				}
+				pt, z, err := parsePosition(vals)
+				if err != nil {
+					return nil, false, err
+				}
+				pts, hasZ = append(pts, pt), hasZ || z
+			}
+		}
+	}
+	return pts, hasZ, nil
+}
+
+// parsePosition returns the position of two or three values
+func parsePosition(fields []string) ([3]float64, bool, error) {
+	if len(fields) < 2 || len(fields) > 3 {
+		return [3]float64{}, false, fmt.Errorf("position of %v values", len(fields))
+	}
+	vals, err := parseFloats(fields)
+	if err != nil {
+		return [3]float64{}, false, err
+	}
+	var pt [3]float64
+	copy(pt[:], vals)
+	return pt, len(vals) == 3, nil
+}
+
+func parseFloats(fields []string) ([]float64, error) {
+	vals := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coordinate %q", f)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+func newPolygon(rings [][][3]float64, hasZ bool) geom.Geometry {
+	if hasZ {
+		return geom.PolygonZ(rings)
+	}
+	poly := make(geom.Polygon, len(rings))
+	for i, ring := range rings {
+		poly[i] = points2(ring)
+	}
+	return poly
+}
+
+// newMultiPolygon returns the multi polygon of the polygons, or a collection of
+// them with z values
+func newMultiPolygon(polys [][][][3]float64, hasZ bool) geom.Geometry {
+	if hasZ {
+		col := make(geom.Collection, len(polys))
+		for i, p := range polys {
+			col[i] = geom.PolygonZ(p)
+		}
+		return col
+	}
+	mp := make(geom.MultiPolygon, len(polys))
+	for i, p := range polys {
+		mp[i] = newPolygon(p, false).(geom.Polygon)
+	}
+	return mp
+}
+
+func points2(pts [][3]float64) [][2]float64 {
+	pts2 := make([][2]float64, len(pts))
+	for i, pt := range pts {
+		pts2[i] = [2]float64{pt[0], pt[1]}
+	}
+	return pts2
+}
+
+// withSRID returns the geometry as its geom type with an SRID, if it has one
+func withSRID(geo geom.Geometry, srid uint32) geom.Geometry {
+	switch geo := geo.(type) {
+	case geom.Point:
+		return geom.PointS{Srid: srid, Xy: geo}
+	case geom.PointZ:
+		return geom.PointZS{Srid: srid, Xyz: geo}
+	case geom.MultiPoint:
+		return geom.MultiPointS{Srid: srid, Mp: geo}
+	case geom.MultiPointZ:
+		return geom.MultiPointZS{Srid: srid, Mpz: geo}
+	case geom.LineString:
+		return geom.LineStringS{Srid: srid, Ls: geo}
+	case geom.LineStringZ:
+		return geom.LineStringZS{Srid: srid, Lsz: geo}
+	case geom.MultiLineString:
+		return geom.MultiLineStringS{Srid: srid, Mls: geo}
+	case geom.MultiLineStringZ:
+		return geom.MultiLineStringZS{Srid: srid, Mlsz: geo}
+	case geom.Polygon:
+		return geom.PolygonS{Srid: srid, Pol: geo}
+	case geom.PolygonZ:
+		return geom.PolygonZS{Srid: srid, Polz: geo}
+	default:
+		return geo
+	}
+}
@@ -0,0 +1,289 @@
+package gml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+)
+
+// Marshal returns the GML of the geometry; id is its gml:id, left out if empty
+func Marshal(geo geom.Geometry, id string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, geo, id); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes the GML of the geometry; id is its gml:id, left out if empty
+func Encode(w io.Writer, geo geom.Geometry, id string) error {
+	e := xml.NewEncoder(w)
+	if err := EncodeElement(e, geo, id); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// EncodeElement writes the geometry element of the geometry to the encoder, with
+// the gml prefix declared on it. The gml:id of the geometry is id and those of the
+// geometries of multi geometries are id followed by a dot and their position,
+// starting at 1; with an empty id the geometries have no gml:id. Multi line strings are written as multi curves and multi polygons
+// and collections of polygons as multi surfaces; collections of other geometries
+// are written as multi geometries.
+func EncodeElement(e *xml.Encoder, geo geom.Geometry, id string) error {
+	geo, srid := withoutSRID(geo)
+	if err := validate(geo); err != nil {
+		return err
+	}
+	enc := encoder{e: e}
+	attrs := []xml.Attr{{Name: xml.Name{Local: "xmlns:gml"}, Value: Namespace}}
+	if srid != 0 {
+		name := SRSName(srid)
+		if latFirst(name) {
+			var err error
+			if geo, err = swapAxes(geo); err != nil {
+				return err
+			}
+		}
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "srsName"}, Value: name})
+	}
+	enc.geometry(geo, id, attrs...)
+	return enc.err
+}
+
+// withoutSRID returns the geometry of a geom type with an SRID and its SRID
+func withoutSRID(geo geom.Geometry) (geom.Geometry, uint32) {
+	switch geo := geo.(type) {
+	case geom.PointS:
+		return geo.Xy, geo.Srid
+	case geom.PointZS:
+		return geo.Xyz, geo.Srid
+	case geom.MultiPointS:
+		return geo.Mp, geo.Srid
+	case geom.MultiPointZS:
+		return geo.Mpz, geo.Srid
+	case geom.LineStringS:
+		return geo.Ls, geo.Srid
+	case geom.LineStringZS:
+		return geo.Lsz, geo.Srid
+	case geom.MultiLineStringS:
+		return geo.Mls, geo.Srid
+	case geom.MultiLineStringZS:
+		return geo.Mlsz, geo.Srid
+	case geom.PolygonS:
+		return geo.Pol, geo.Srid
+	case geom.PolygonZS:
+		return geo.Polz, geo.Srid
+	default:
+		return geo, 0
+	}
+}
+
+// validate returns an error for geometries that can not be written
+func validate(geo geom.Geometry) error {
+	switch geo := geo.(type) {
+	case geom.Point, geom.PointZ, geom.MultiPoint, geom.MultiPointZ,
+		geom.LineString, geom.LineStringZ, geom.MultiLineString, geom.MultiLineStringZ,
+		geom.Polygon, geom.PolygonZ, geom.MultiPolygon:
+		return nil
+	case geom.Collection:
+		for _, g := range geo {
+			if err := validate(g); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return encoding.ErrUnknownGeometry{Geom: geo}
+	}
+}
+
+// hasZ returns whether the geometry has z values
+func hasZ(geo geom.Geometry) bool {
+	switch geo := geo.(type) {
+	case geom.PointZ, geom.MultiPointZ, geom.LineStringZ, geom.MultiLineStringZ, geom.PolygonZ:
+		return true
+	case geom.Collection:
+		for _, g := range geo {
+			if hasZ(g) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// encoder writes the tokens of geometries, keeping the first error
+type encoder struct {
+	e   *xml.Encoder
+	err error
+}
+
+func (enc *encoder) token(tok xml.Token) {
+	if enc.err == nil {
+		enc.err = enc.e.EncodeToken(tok)
+	}
+}
+
+func (enc *encoder) start(name string, attrs ...xml.Attr) {
+	enc.token(xml.StartElement{Name: xml.Name{Local: "gml:" + name}, Attr: attrs})
+}
+
+func (enc *encoder) end(name string) {
+	enc.token(xml.EndElement{Name: xml.Name{Local: "gml:" + name}})
+}
+
+// positions writes a pos or posList element of the points
+func (enc *encoder) positions(name string, dims int, pts ...[]float64) {
+	var sb strings.Builder
+	for i, pt := range pts {
+		for j := 0; j < dims; j++ {
+			if i > 0 || j > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(strconv.FormatFloat(pt[j], 'f', -1, 64))
+		}
+	}
+	enc.start(name)
+	enc.token(xml.CharData(sb.String()))
+	enc.end(name)
+}
+
+// geometry writes the element of the geometry with the attributes
+func (enc *encoder) geometry(geo geom.Geometry, id string, attrs ...xml.Attr) {
+	if id != "" {
+		attrs = append([]xml.Attr{{Name: xml.Name{Local: "gml:id"}, Value: id}}, attrs...)
+	}
+	dims := 2
+	// the geometries of a collection have their own dimensions
+	if _, ok := geo.(geom.Collection); !ok && hasZ(geo) {
+		dims = 3
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "srsDimension"}, Value: "3"})
+	}
+	member := func(i int) string {
+		if id == "" {
+			return ""
+		}
+		return fmt.Sprintf("%v.%v", id, i+1)
+	}
+
+	switch geo := geo.(type) {
+	case geom.Point:
+		enc.start("Point", attrs...)
+		enc.positions("pos", dims, geo[:])
+		enc.end("Point")
+	case geom.PointZ:
+		enc.start("Point", attrs...)
+		enc.positions("pos", dims, geo[:])
+		enc.end("Point")
+
+	case geom.LineString:
+		enc.start("LineString", attrs...)
+		enc.positions("posList", dims, coords2(geo)...)
+		enc.end("LineString")
+	case geom.LineStringZ:
+		enc.start("LineString", attrs...)
+		enc.positions("posList", dims, coords3(geo)...)
+		enc.end("LineString")
+
+	case geom.Polygon:
+		rings := make([][][]float64, len(geo))
+		for i, ring := range geo {
+			rings[i] = coords2(ring)
+		}
+		enc.polygon(dims, rings, attrs)
+	case geom.PolygonZ:
+		rings := make([][][]float64, len(geo))
+		for i, ring := range geo {
+			rings[i] = coords3(ring)
+		}
+		enc.polygon(dims, rings, attrs)
+
+	case geom.MultiPoint:
+		enc.multi("MultiPoint", "pointMember", len(geo), func(i int) { enc.geometry(geom.Point(geo[i]), member(i)) }, attrs)
+	case geom.MultiPointZ:
+		enc.multi("MultiPoint", "pointMember", len(geo), func(i int) { enc.geometry(geom.PointZ(geo[i]), member(i)) }, attrs)
+	case geom.MultiLineString:
+		enc.multi("MultiCurve", "curveMember", len(geo), func(i int) { enc.geometry(geom.LineString(geo[i]), member(i)) }, attrs)
+	case geom.MultiLineStringZ:
+		enc.multi("MultiCurve", "curveMember", len(geo), func(i int) { enc.geometry(geom.LineStringZ(geo[i]), member(i)) }, attrs)
+	case geom.MultiPolygon:
+		enc.multi("MultiSurface", "surfaceMember", len(geo), func(i int) { enc.geometry(geom.Polygon(geo[i]), member(i)) }, attrs)
+
+	case geom.Collection:
+		name, memberName := "MultiSurface", "surfaceMember"
+		for _, g := range geo {
+			switch g.(type) {
+			case geom.Polygon, geom.PolygonZ:
+			default:
+				name, memberName = "MultiGeometry", "geometryMember"
+			}
+		}
+		if len(geo) == 0 {
+			name, memberName = "MultiGeometry", "geometryMember"
+		}
+		enc.multi(name, memberName, len(geo), func(i int) { enc.geometry(geo[i], member(i)) }, attrs)
+	}
+}
+
+// polygon writes a polygon of the rings, which are closed as GML requires
+func (enc *encoder) polygon(dims int, rings [][][]float64, attrs []xml.Attr) {
+	enc.start("Polygon", attrs...)
+	for i, ring := range rings {
+		name := "interior"
+		if i == 0 {
+			name = "exterior"
+		}
+		if n := len(ring); n > 0 && !equal(ring[0], ring[n-1]) {
+			ring = append(ring, ring[0])
+		}
+		enc.start(name)
+		enc.start("LinearRing")
+		enc.positions("posList", dims, ring...)
+		enc.end("LinearRing")
+		enc.end(name)
+	}
+	enc.end("Polygon")
+}
+
+// multi writes the element of a multi geometry of n geometries, each written by geometry
+func (enc *encoder) multi(name, memberName string, n int, geometry func(i int), attrs []xml.Attr) {
+	enc.start(name, attrs...)
+	for i := 0; i < n; i++ {
+		enc.start(memberName)
+		geometry(i)
+		enc.end(memberName)
+	}
+	enc.end(name)
+}
+
+func coords2(pts [][2]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func coords3(pts [][3]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func equal(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
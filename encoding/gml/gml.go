@@ -0,0 +1,100 @@
+// Package gml implements encoding and decoding of the simple feature geometries of
+// GML 3.2 as described at https://www.ogc.org/standards/gml.
+//
+// Points, line strings, polygons, multi points, multi curves, multi surfaces and
+// multi geometries are supported, with coordinates given by pos or posList elements
+// and z values when the srsDimension is 3; the multi line strings and multi polygons
+// and the coordinates elements of earlier versions, curves of line string segments
+// and surfaces of polygon patches are also read.
+//
+// The srsName of the geometry is read as an SRID, and geometries with a geom type
+// with an SRID, such as geom.PointS, are read as that type. Such geometries are
+// written with the srsName of their SRID.
+//
+// The x of geom geometries is always the longitude. The EPSG urn and http srsNames
+// of geographic coordinate reference systems, such as
+// http://www.opengis.net/def/crs/EPSG/0/4326, have the latitude first, so the axes
+// of their coordinates are swapped when read and written. The EPSG:4326 form and
+// CRS84 have the longitude first, as most WFS servers write them.
+package gml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hahaking119/geom"
+)
+
+// Namespace is the XML namespace of GML 3.2
+const Namespace = "http://www.opengis.net/gml/3.2"
+
+// SRID returns the EPSG code of an srsName, such as EPSG:4326,
+// urn:ogc:def:crs:EPSG::4326 or http://www.opengis.net/def/crs/EPSG/0/4326; the
+// OGC CRS84 is 4326.
+func SRID(srsName string) (uint32, bool) {
+	name := strings.ToUpper(strings.TrimSpace(srsName))
+	if strings.HasSuffix(name, "CRS84") || strings.HasSuffix(name, "CRS:84") {
+		return 4326, true
+	}
+	if !strings.Contains(name, "EPSG") {
+		return 0, false
+	}
+	code := name[strings.LastIndexAny(name, ":/#")+1:]
+	srid, err := strconv.ParseUint(code, 10, 32)
+	if err != nil || srid == 0 {
+		return 0, false
+	}
+	return uint32(srid), true
+}
+
+// latFirstCodes are the EPSG codes of common geographic coordinate reference
+// systems, which have the latitude first
+var latFirstCodes = map[uint32]bool{
+	4326: true, // WGS 84
+	4979: true, // WGS 84 3D
+	4258: true, // ETRS89
+	4937: true, // ETRS89 3D
+	4269: true, // NAD83
+	4267: true, // NAD27
+	4617: true, // NAD83(CSRS)
+	4283: true, // GDA94
+	7844: true, // GDA2020
+	4674: true, // SIRGAS 2000
+	4612: true, // JGD2000
+	6668: true, // JGD2011
+	4490: true, // CGCS2000
+}
+
+// latFirst returns whether the coordinates of the srsName have the latitude first,
+// which is the case for the EPSG urn and http forms of geographic coordinate
+// reference systems.
+func latFirst(srsName string) bool {
+	name := strings.ToUpper(strings.TrimSpace(srsName))
+	if !strings.HasPrefix(name, "URN:") && !strings.Contains(name, "/DEF/CRS/EPSG/") {
+		return false
+	}
+	srid, ok := SRID(name)
+	return ok && strings.Contains(name, "EPSG") && latFirstCodes[srid]
+}
+
+// swapAxes returns the geometry with the x and y of its points swapped
+func swapAxes(geo geom.Geometry) (geom.Geometry, error) {
+	return geom.ApplyToPoints(geo, func(coords ...float64) ([]float64, error) {
+		return []float64{coords[1], coords[0]}, nil
+	})
+}
+
+// SRSName returns the srsName of the EPSG code, the URI of the OGC register. The
+// coordinates of geographic coordinate reference systems, such as 4326, are
+// written with the latitude first.
+func SRSName(srid uint32) string {
+	return fmt.Sprintf("http://www.opengis.net/def/crs/EPSG/0/%v", srid)
+}
+
+// ErrUnknownElement is returned when decoding an element that is not a geometry
+type ErrUnknownElement string
+
+func (e ErrUnknownElement) Error() string {
+	return fmt.Sprintf("unknown geometry element %q", string(e))
+}
@@ -0,0 +1,298 @@
+package gml_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding/gml"
+)
+
+func TestRoundTrip(t *testing.T) {
+	type tcase struct {
+		geo geom.Geometry
+		// expected is the geometry read back, geo if nil
+		expected geom.Geometry
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			b, err := gml.Marshal(tc.geo, "g")
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			got, err := gml.Unmarshal(b)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			expected := tc.expected
+			if expected == nil {
+				expected = tc.geo
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("geometry, expected %v got %v\n%s", expected, got, b)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point":   {geo: geom.Point{1.5, -2}},
+		"point z": {geo: geom.PointZ{1, 2, 3}},
+		"point s": {geo: geom.PointS{Srid: 4326, Xy: geom.Point{1, 2}}},
+		"line string": {
+			geo: geom.LineString{{1, 2}, {3, 4}, {0.000001, 1e10}},
+		},
+		"line string zs": {
+			geo: geom.LineStringZS{Srid: 3857, Lsz: geom.LineStringZ{{1, 2, 3}, {4, 5, 6}}},
+		},
+		"polygon": {
+			geo: geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, {{1, 1}, {2, 1}, {2, 2}}},
+		},
+		"polygon zs": {
+			geo: geom.PolygonZS{Srid: 25832, Polz: geom.PolygonZ{{{0, 0, 1}, {10, 0, 1}, {10, 10, 1}}}},
+		},
+		"multi point": {geo: geom.MultiPoint{{1, 2}, {3, 4}}},
+		"multi point zs": {
+			geo: geom.MultiPointZS{Srid: 4326, Mpz: geom.MultiPointZ{{1, 2, 3}}},
+		},
+		"multi line string": {
+			geo: geom.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+		},
+		"multi line string s": {
+			geo: geom.MultiLineStringS{Srid: 4326, Mls: geom.MultiLineString{{{1, 2}, {3, 4}}}},
+		},
+		"multi polygon": {
+			geo: geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}}}, {{{5, 5}, {6, 5}, {6, 6}}, {{5.1, 5.1}, {5.5, 5.1}, {5.5, 5.2}}}},
+		},
+		"collection of polygon z": {
+			geo: geom.Collection{geom.PolygonZ{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}}}},
+		},
+		"collection": {
+			geo: geom.Collection{
+				geom.PointZ{1, 2, 3},
+				geom.LineString{{1, 2}, {3, 4}},
+				geom.Collection{geom.Point{1, 2}},
+			},
+		},
+		"empty collection": {geo: geom.Collection{}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type tcase struct {
+		gml      string
+		expected geom.Geometry
+		err      bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := gml.Decode(strings.NewReader(tc.gml))
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("geometry, expected %v got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"wfs feature": {
+			gml: `<wfs:FeatureCollection xmlns:wfs="http://www.opengis.net/wfs/2.0" xmlns:gml="http://www.opengis.net/gml/3.2">
+				<wfs:member><app:road xmlns:app="urn:app" gml:id="r1"><app:name>a</app:name><app:geom>
+				<gml:LineString gml:id="l1" srsName="urn:ogc:def:crs:EPSG::4326" srsDimension="3">
+					<gml:posList>1 2 3
+						4 5 6</gml:posList>
+				</gml:LineString></app:geom></app:road></wfs:member></wfs:FeatureCollection>`,
+			// the urn of 4326 has the latitude first
+			expected: geom.LineStringZS{Srid: 4326, Lsz: geom.LineStringZ{{2, 1, 3}, {5, 4, 6}}},
+		},
+		"wfs 2.0 response 4326": {
+			gml: `<?xml version="1.0" encoding="UTF-8"?>
+<wfs:FeatureCollection xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:wfs="http://www.opengis.net/wfs/2.0"
+	xmlns:gml="http://www.opengis.net/gml/3.2" xmlns:topp="http://www.openplans.org/topp"
+	numberMatched="1" numberReturned="1" timeStamp="2024-05-02T09:13:41.283Z">
+	<wfs:boundedBy><gml:Envelope srsDimension="2" srsName="urn:ogc:def:crs:EPSG::4326">
+		<gml:lowerCorner>40.4961 -74.2557</gml:lowerCorner><gml:upperCorner>40.9155 -73.7004</gml:upperCorner>
+	</gml:Envelope></wfs:boundedBy>
+	<wfs:member>
+		<topp:tasmania_roads gml:id="tasmania_roads.1">
+			<topp:the_geom>
+				<gml:MultiCurve gml:id="tasmania_roads.1.the_geom" srsDimension="2" srsName="urn:ogc:def:crs:EPSG::4326">
+					<gml:curveMember><gml:LineString gml:id="tasmania_roads.1.the_geom.1">
+						<gml:posList>-42.8806 147.3250 -42.8820 147.3301 -42.8840 147.3363</gml:posList>
+					</gml:LineString></gml:curveMember>
+				</gml:MultiCurve>
+			</topp:the_geom>
+			<topp:TYPE>alley</topp:TYPE>
+		</topp:tasmania_roads>
+	</wfs:member>
+</wfs:FeatureCollection>`,
+			expected: geom.MultiLineStringS{Srid: 4326, Mls: geom.MultiLineString{
+				{{147.3250, -42.8806}, {147.3301, -42.8820}, {147.3363, -42.8840}},
+			}},
+		},
+		"http uri 4258": {
+			gml:      `<Point srsName="http://www.opengis.net/def/crs/EPSG/0/4258"><pos>52.5 13.4</pos></Point>`,
+			expected: geom.PointS{Srid: 4258, Xy: geom.Point{13.4, 52.5}},
+		},
+		"epsg short form 4326": {
+			gml:      `<Point srsName="EPSG:4326"><pos>13.4 52.5</pos></Point>`,
+			expected: geom.PointS{Srid: 4326, Xy: geom.Point{13.4, 52.5}},
+		},
+		"crs84": {
+			gml:      `<Point srsName="urn:ogc:def:crs:OGC:1.3:CRS84"><pos>13.4 52.5</pos></Point>`,
+			expected: geom.PointS{Srid: 4326, Xy: geom.Point{13.4, 52.5}},
+		},
+		"projected urn": {
+			gml:      `<Point srsName="urn:ogc:def:crs:EPSG::25832"><pos>389000 5820000</pos></Point>`,
+			expected: geom.PointS{Srid: 25832, Xy: geom.Point{389000, 5820000}},
+		},
+		"line string of pos": {
+			gml:      `<LineString><pos>1 2</pos><pos>3 4</pos><pointProperty><Point><pos>5 6</pos></Point></pointProperty></LineString>`,
+			expected: geom.LineString{{1, 2}, {3, 4}, {5, 6}},
+		},
+		"pos with z": {
+			gml:      `<Point srsName="EPSG:3857"><pos>1 2 3</pos></Point>`,
+			expected: geom.PointZS{Srid: 3857, Xyz: geom.PointZ{1, 2, 3}},
+		},
+		"unknown srs name": {
+			gml:      `<Point srsName="urn:ogc:def:crs:OGC::AUTO42001"><pos>1 2</pos></Point>`,
+			expected: geom.Point{1, 2},
+		},
+		"posList srsDimension": {
+			gml:      `<LineString><posList srsDimension="3">1 2 3 4 5 6</posList></LineString>`,
+			expected: geom.LineStringZ{{1, 2, 3}, {4, 5, 6}},
+		},
+		"inherited srsDimension": {
+			gml: `<MultiCurve srsDimension="3"><curveMember><LineString><posList>1 2 3 4 5 6</posList></LineString></curveMember>
+				<curveMember><LineString><posList>7 8 9 1 2 3</posList></LineString></curveMember></MultiCurve>`,
+			expected: geom.MultiLineStringZ{{{1, 2, 3}, {4, 5, 6}}, {{7, 8, 9}, {1, 2, 3}}},
+		},
+		"gml 2 coordinates": {
+			gml: `<gml:MultiPolygon xmlns:gml="http://www.opengis.net/gml" srsName="http://www.opengis.net/gml/srs/epsg.xml#4326">
+				<gml:polygonMember><gml:Polygon><gml:outerBoundaryIs><gml:LinearRing>
+				<gml:coordinates>0,0 1,0 1,1 0,0</gml:coordinates></gml:LinearRing></gml:outerBoundaryIs>
+				</gml:Polygon></gml:polygonMember></gml:MultiPolygon>`,
+			expected: geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}}}},
+		},
+		"point members": {
+			gml:      `<MultiPoint><pointMembers><Point><pos>1 2</pos></Point><Point><pos>3 4</pos></Point></pointMembers></MultiPoint>`,
+			expected: geom.MultiPoint{{1, 2}, {3, 4}},
+		},
+		"curve": {
+			gml: `<Curve><segments><LineStringSegment><posList>1 2 3 4</posList></LineStringSegment>
+				<LineStringSegment><posList>3 4 5 6</posList></LineStringSegment></segments></Curve>`,
+			expected: geom.LineString{{1, 2}, {3, 4}, {5, 6}},
+		},
+		"surface": {
+			gml: `<MultiSurface><surfaceMember><Surface><patches>
+				<PolygonPatch><exterior><LinearRing><posList>0 0 1 0 1 1 0 0</posList></LinearRing></exterior></PolygonPatch>
+				</patches></Surface></surfaceMember></MultiSurface>`,
+			expected: geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}}}},
+		},
+		"multi polygon z": {
+			gml: `<MultiSurface srsDimension="3"><surfaceMember><Polygon><exterior><LinearRing>
+				<posList>0 0 1 1 0 1 1 1 1 0 0 1</posList></LinearRing></exterior></Polygon></surfaceMember></MultiSurface>`,
+			expected: geom.Collection{geom.PolygonZ{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}}}},
+		},
+		"no geometry":           {gml: `<a><b/></a>`, err: true},
+		"posList not divisible": {gml: `<LineString srsDimension="3"><posList>1 2 3 4</posList></LineString>`, err: true},
+		"invalid srsDimension":  {gml: `<LineString srsDimension="4"><posList>1 2 3 4</posList></LineString>`, err: true},
+		"invalid number":        {gml: `<Point><pos>1 a</pos></Point>`, err: true},
+		"point of two":          {gml: `<Point><pos>1 2</pos><pos>3 4</pos></Point>`, err: true},
+		"member by reference":   {gml: `<MultiPoint><pointMember href="#p1"/></MultiPoint>`, err: true},
+		"unknown member":        {gml: `<MultiGeometry><geometryMember><Arc/></geometryMember></MultiGeometry>`, err: true},
+		"unclosed":              {gml: `<Point><pos>1 2</pos>`, err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	got, err := gml.Marshal(geom.MultiPointS{Srid: 4326, Mp: geom.MultiPoint{{1, 2}}}, "mp")
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	expected := `<gml:MultiPoint gml:id="mp" xmlns:gml="http://www.opengis.net/gml/3.2" srsName="http://www.opengis.net/def/crs/EPSG/0/4326">` +
+		`<gml:pointMember><gml:Point gml:id="mp.1"><gml:pos>2 1</gml:pos></gml:Point></gml:pointMember></gml:MultiPoint>`
+	if string(got) != expected {
+		t.Errorf("gml, expected %v got %s", expected, got)
+	}
+
+	// projected coordinates are not swapped
+	got, err = gml.Marshal(geom.PointS{Srid: 3857, Xy: geom.Point{1, 2}}, "p")
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	expected = `<gml:Point gml:id="p" xmlns:gml="http://www.opengis.net/gml/3.2" srsName="http://www.opengis.net/def/crs/EPSG/0/3857">` +
+		`<gml:pos>1 2</gml:pos></gml:Point>`
+	if string(got) != expected {
+		t.Errorf("gml, expected %v got %s", expected, got)
+	}
+
+	// without an id there are no gml:id attributes
+	got, err = gml.Marshal(geom.Collection{geom.Point{1, 2}, geom.MultiLineString{{{1, 2}, {3, 4}}}}, "")
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	expected = `<gml:MultiGeometry xmlns:gml="http://www.opengis.net/gml/3.2">` +
+		`<gml:geometryMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:geometryMember>` +
+		`<gml:geometryMember><gml:MultiCurve><gml:curveMember><gml:LineString><gml:posList>1 2 3 4</gml:posList></gml:LineString></gml:curveMember></gml:MultiCurve></gml:geometryMember>` +
+		`</gml:MultiGeometry>`
+	if string(got) != expected {
+		t.Errorf("gml, expected %v got %s", expected, got)
+	}
+
+	if _, err := gml.Marshal(geom.PointM{1, 2, 3}, "p"); err == nil {
+		t.Errorf("error, expected error got nil")
+	}
+}
+
+func TestSRID(t *testing.T) {
+	type tcase struct {
+		name string
+		srid uint32
+		ok   bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			srid, ok := gml.SRID(tc.name)
+			if srid != tc.srid || ok != tc.ok {
+				t.Errorf("srid, expected %v %v got %v %v", tc.srid, tc.ok, srid, ok)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"epsg":         {name: "EPSG:4326", srid: 4326, ok: true},
+		"urn":          {name: "urn:ogc:def:crs:EPSG::3857", srid: 3857, ok: true},
+		"urn version":  {name: "urn:x-ogc:def:crs:EPSG:6.6:4326", srid: 4326, ok: true},
+		"uri":          {name: "http://www.opengis.net/def/crs/EPSG/0/25832", srid: 25832, ok: true},
+		"gml 2":        {name: "http://www.opengis.net/gml/srs/epsg.xml#4326", srid: 4326, ok: true},
+		"crs84":        {name: "urn:ogc:def:crs:OGC:1.3:CRS84", srid: 4326, ok: true},
+		"srs name":     {name: gml.SRSName(2056), srid: 2056, ok: true},
+		"not epsg":     {name: "urn:ogc:def:crs:OGC::AUTO42001"},
+		"invalid code": {name: "EPSG:abc"},
+		"empty":        {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -0,0 +1,156 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"time"
+
+	"github.com/hahaking119/geom"
+)
+
+// Decode reads a GPX document
+func Decode(r io.Reader) (*GPX, error) {
+	var doc gpxXML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	g := &GPX{Creator: doc.Creator}
+	if md := doc.Metadata; md != nil {
+		g.Name, g.Description = md.Name, md.Desc
+		if md.Time != nil {
+			g.Time = time.Time(*md.Time)
+		}
+	}
+
+	for _, wpt := range doc.Wpts {
+		f := Feature{
+			Geometry: geom.Point{float64(wpt.Lon), float64(wpt.Lat)},
+			Properties: properties(map[string]string{
+				"name": wpt.Name,
+				"cmt":  wpt.Cmt,
+				"desc": wpt.Desc,
+				"src":  wpt.Src,
+				"sym":  wpt.Sym,
+				"type": wpt.Type,
+			}, nil),
+		}
+		if wpt.Ele != nil {
+			f.Geometry = geom.PointZ{float64(wpt.Lon), float64(wpt.Lat), float64(*wpt.Ele)}
+		}
+		if wpt.Time != nil {
+			f.Properties["time"] = time.Time(*wpt.Time)
+		}
+		g.Waypoints = append(g.Waypoints, f)
+	}
+
+	for _, rte := range doc.Rtes {
+		f := Feature{
+			Properties: properties(map[string]string{
+				"name": rte.Name,
+				"cmt":  rte.Cmt,
+				"desc": rte.Desc,
+				"src":  rte.Src,
+				"type": rte.Type,
+			}, rte.Number),
+		}
+		line, hasZ := decodePoints(rte.Rtepts)
+		if hasZ {
+			ls := make(geom.LineStringZ, len(line))
+			for i, pt := range line {
+				ls[i] = [3]float64{pt[0], pt[1], pt[2]}
+			}
+			f.Geometry = ls
+		} else {
+			ls := make(geom.LineString, len(line))
+			for i, pt := range line {
+				ls[i] = [2]float64{pt[0], pt[1]}
+			}
+			f.Geometry = ls
+		}
+		g.Routes = append(g.Routes, f)
+	}
+
+	for _, trk := range doc.Trks {
+		f := Feature{
+			Properties: properties(map[string]string{
+				"name": trk.Name,
+				"cmt":  trk.Cmt,
+				"desc": trk.Desc,
+				"src":  trk.Src,
+				"type": trk.Type,
+			}, trk.Number),
+		}
+		f.Geometry = decodeTrack(trk.Trksegs)
+		g.Tracks = append(g.Tracks, f)
+	}
+	return g, nil
+}
+
+// properties returns the non empty strings and the number, if not nil
+func properties(strs map[string]string, number *int) map[string]interface{} {
+	props := make(map[string]interface{})
+	for k, v := range strs {
+		if v != "" {
+			props[k] = v
+		}
+	}
+	if number != nil {
+		props["number"] = *number
+	}
+	return props
+}
+
+// decodePoints returns the x, y, z and m values of the points, with NaN for missing
+// elevations and times, and whether any point has an elevation
+func decodePoints(wpts []wptXML) (pts [][4]float64, hasZ bool) {
+	pts = make([][4]float64, len(wpts))
+	for i, wpt := range wpts {
+		pts[i] = [4]float64{float64(wpt.Lon), float64(wpt.Lat), math.NaN(), math.NaN()}
+		if wpt.Ele != nil {
+			pts[i][2] = float64(*wpt.Ele)
+			hasZ = true
+		}
+		if wpt.Time != nil {
+			pts[i][3] = TimeToM(time.Time(*wpt.Time))
+		}
+	}
+	return pts, hasZ
+}
+
+// decodeTrack returns the geometry of the segments of a track
+func decodeTrack(segs []trksegXML) geom.Geometry {
+	var (
+		lines = make([][][4]float64, len(segs))
+		hasZ  bool
+	)
+	for i, seg := range segs {
+		var z bool
+		lines[i], z = decodePoints(seg.Trkpts)
+		hasZ = hasZ || z
+	}
+
+	if hasZ {
+		mls := make(geom.MultiLineStringZM, len(lines))
+		for i, line := range lines {
+			mls[i] = line
+		}
+		if len(mls) == 1 {
+			return geom.LineStringZM(mls[0])
+		}
+		return mls
+	}
+
+	mls := make(geom.MultiLineStringM, len(lines))
+	for i, line := range lines {
+		mls[i] = make([][3]float64, len(line))
+		for j, pt := range line {
+			mls[i][j] = [3]float64{pt[0], pt[1], pt[3]}
+		}
+	}
+	if len(mls) == 1 {
+		return geom.LineStringM(mls[0])
+	}
+	return mls
+}
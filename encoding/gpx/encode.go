@@ -0,0 +1,233 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+)
+
+// Encode writes the GPX document. Waypoints must be points, with or without z values,
+// routes line strings and tracks line strings or multi line strings; m values are
+// written as the times of the points and NaN elevations and times are left out.
+func Encode(w io.Writer, g *GPX) error {
+	doc := gpxXML{
+		Xmlns:   Namespace,
+		Version: "1.1",
+		Creator: g.Creator,
+	}
+	if doc.Creator == "" {
+		doc.Creator = DefaultCreator
+	}
+	if g.Name != "" || g.Description != "" || !g.Time.IsZero() {
+		doc.Metadata = &metadataXML{Name: g.Name, Desc: g.Description}
+		if !g.Time.IsZero() {
+			t := timestamp(g.Time)
+			doc.Metadata.Time = &t
+		}
+	}
+
+	for _, f := range g.Waypoints {
+		var pt [4]float64
+		switch geo := f.Geometry.(type) {
+		case geom.Point:
+			pt = [4]float64{geo[0], geo[1], math.NaN(), math.NaN()}
+		case geom.PointZ:
+			pt = [4]float64{geo[0], geo[1], geo[2], math.NaN()}
+		default:
+			return fmt.Errorf("waypoint: %w", encoding.ErrUnknownGeometry{Geom: f.Geometry})
+		}
+		wpt := encodePoint(pt)
+		err := stringProperties(f.Properties, map[string]*string{
+			"name": &wpt.Name,
+			"cmt":  &wpt.Cmt,
+			"desc": &wpt.Desc,
+			"src":  &wpt.Src,
+			"sym":  &wpt.Sym,
+			"type": &wpt.Type,
+		})
+		if err != nil {
+			return err
+		}
+		if v, ok := f.Properties["time"]; ok && v != nil {
+			t, ok := v.(time.Time)
+			if !ok {
+				return ErrInvalidProperty{Key: "time", Value: v}
+			}
+			ts := timestamp(t)
+			wpt.Time = &ts
+		}
+		doc.Wpts = append(doc.Wpts, wpt)
+	}
+
+	for _, f := range g.Routes {
+		lines, ok := encodeLines(f.Geometry)
+		if !ok || len(lines) != 1 {
+			return fmt.Errorf("route: %w", encoding.ErrUnknownGeometry{Geom: f.Geometry})
+		}
+		var rte rteXML
+		err := stringProperties(f.Properties, map[string]*string{
+			"name": &rte.Name,
+			"cmt":  &rte.Cmt,
+			"desc": &rte.Desc,
+			"src":  &rte.Src,
+			"type": &rte.Type,
+		})
+		if err != nil {
+			return err
+		}
+		if rte.Number, err = number(f.Properties); err != nil {
+			return err
+		}
+		for _, pt := range lines[0] {
+			rte.Rtepts = append(rte.Rtepts, encodePoint(pt))
+		}
+		doc.Rtes = append(doc.Rtes, rte)
+	}
+
+	for _, f := range g.Tracks {
+		lines, ok := encodeLines(f.Geometry)
+		if !ok {
+			return fmt.Errorf("track: %w", encoding.ErrUnknownGeometry{Geom: f.Geometry})
+		}
+		var trk trkXML
+		err := stringProperties(f.Properties, map[string]*string{
+			"name": &trk.Name,
+			"cmt":  &trk.Cmt,
+			"desc": &trk.Desc,
+			"src":  &trk.Src,
+			"type": &trk.Type,
+		})
+		if err != nil {
+			return err
+		}
+		if trk.Number, err = number(f.Properties); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			var seg trksegXML
+			for _, pt := range line {
+				seg.Trkpts = append(seg.Trkpts, encodePoint(pt))
+			}
+			trk.Trksegs = append(trk.Trksegs, seg)
+		}
+		doc.Trks = append(doc.Trks, trk)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// encodePoint returns the point of the x, y, z and m values, without the NaN ones
+func encodePoint(pt [4]float64) wptXML {
+	wpt := wptXML{Lon: decimal(pt[0]), Lat: decimal(pt[1])}
+	if !math.IsNaN(pt[2]) {
+		ele := decimal(pt[2])
+		wpt.Ele = &ele
+	}
+	if !math.IsNaN(pt[3]) {
+		t := timestamp(MToTime(pt[3]))
+		wpt.Time = &t
+	}
+	return wpt
+}
+
+// encodeLines returns the x, y, z and m values of the line strings of the geometry,
+// with NaN for missing values
+func encodeLines(geo geom.Geometry) ([][][4]float64, bool) {
+	nan := math.NaN()
+	var lines [][][4]float64
+	add := func(n int, pt func(i int) [4]float64) {
+		line := make([][4]float64, n)
+		for i := range line {
+			line[i] = pt(i)
+		}
+		lines = append(lines, line)
+	}
+
+	switch geo := geo.(type) {
+	case geom.LineString:
+		add(len(geo), func(i int) [4]float64 { return [4]float64{geo[i][0], geo[i][1], nan, nan} })
+	case geom.LineStringZ:
+		add(len(geo), func(i int) [4]float64 { return [4]float64{geo[i][0], geo[i][1], geo[i][2], nan} })
+	case geom.LineStringM:
+		add(len(geo), func(i int) [4]float64 { return [4]float64{geo[i][0], geo[i][1], nan, geo[i][2]} })
+	case geom.LineStringZM:
+		add(len(geo), func(i int) [4]float64 { return geo[i] })
+	case geom.MultiLineString:
+		for _, ls := range geo {
+			if l, ok := encodeLines(geom.LineString(ls)); ok {
+				lines = append(lines, l...)
+			}
+		}
+	case geom.MultiLineStringZ:
+		for _, ls := range geo {
+			if l, ok := encodeLines(geom.LineStringZ(ls)); ok {
+				lines = append(lines, l...)
+			}
+		}
+	case geom.MultiLineStringM:
+		for _, ls := range geo {
+			if l, ok := encodeLines(geom.LineStringM(ls)); ok {
+				lines = append(lines, l...)
+			}
+		}
+	case geom.MultiLineStringZM:
+		for _, ls := range geo {
+			if l, ok := encodeLines(geom.LineStringZM(ls)); ok {
+				lines = append(lines, l...)
+			}
+		}
+	default:
+		return nil, false
+	}
+	return lines, true
+}
+
+// stringProperties sets the strings to the string properties of their keys
+func stringProperties(props map[string]interface{}, strs map[string]*string) error {
+	for k, str := range strs {
+		v, ok := props[k]
+		if !ok || v == nil {
+			continue
+		}
+		if *str, ok = v.(string); !ok {
+			return ErrInvalidProperty{Key: k, Value: v}
+		}
+	}
+	return nil
+}
+
+// number returns the number property, any integer value
+func number(props map[string]interface{}) (*int, error) {
+	v, ok := props["number"]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	var n int
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = int(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = int(rv.Uint())
+	default:
+		return nil, ErrInvalidProperty{Key: "number", Value: v}
+	}
+	if n < 0 {
+		return nil, ErrInvalidProperty{Key: "number", Value: v}
+	}
+	return &n, nil
+}
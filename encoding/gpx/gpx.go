@@ -0,0 +1,74 @@
+// Package gpx implements reading and writing of GPX 1.1 documents as described at
+// https://www.topografix.com/GPX/1/1/. Waypoints, routes and tracks are read as
+// features, each a geometry and the GPX elements describing it as properties.
+//
+// Waypoints are points, with the elevation as the z value when there is one. Routes
+// are line strings, z when any route point has an elevation. Tracks keep the time
+// of their points as the m value, in seconds since the Unix epoch, and are line
+// strings when they have one segment or multi line strings otherwise, with z values
+// when any track point has an elevation; so a track is a LineStringM, LineStringZM,
+// MultiLineStringM or MultiLineStringZM. A missing time or elevation is NaN.
+package gpx
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hahaking119/geom"
+)
+
+// Namespace is the XML namespace of GPX 1.1 documents
+const Namespace = "http://www.topografix.com/GPX/1/1"
+
+// DefaultCreator is the creator of the documents written without one
+const DefaultCreator = "github.com/hahaking119/geom"
+
+// GPX is a GPX document
+type GPX struct {
+	// Creator is the name of the software that created the document
+	Creator string
+	// Name, Description and Time are the metadata of the document
+	Name        string
+	Description string
+	Time        time.Time
+
+	// Waypoints are points with the properties name, cmt, desc, src, sym and type
+	// as strings and time as a time.Time
+	Waypoints []Feature
+	// Routes are line strings with the properties name, cmt, desc, src and type
+	// as strings and number as an int
+	Routes []Feature
+	// Tracks are line strings or multi line strings with m values, with the same
+	// properties as routes
+	Tracks []Feature
+}
+
+// Feature is a geometry and its properties
+type Feature struct {
+	Geometry   geom.Geometry
+	Properties map[string]interface{}
+}
+
+// TimeToM returns the m value of the time, the seconds since the Unix epoch
+func TimeToM(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+}
+
+// MToTime returns the UTC time of the m value, rounded to the millisecond
+func MToTime(m float64) time.Time {
+	sec := math.Floor(m)
+	ms := math.Round((m - sec) * 1e3)
+	return time.Unix(int64(sec), int64(ms)*int64(time.Millisecond)).UTC()
+}
+
+// ErrInvalidProperty is returned when writing a property whose value does not have
+// the type of the GPX element
+type ErrInvalidProperty struct {
+	Key   string
+	Value interface{}
+}
+
+func (e ErrInvalidProperty) Error() string {
+	return fmt.Sprintf("invalid value %v (%T) for property %q", e.Value, e.Value, e.Key)
+}
@@ -0,0 +1,267 @@
+package gpx_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+	"github.com/hahaking119/geom/encoding/gpx"
+)
+
+var (
+	t0  = time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	m0  = gpx.TimeToM(t0)
+	nan = math.NaN()
+)
+
+const document = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <metadata>
+    <name>walk</name>
+    <time>2020-05-01T10:00:00Z</time>
+  </metadata>
+  <wpt lat="52.5" lon="13.4">
+    <ele>34.5</ele>
+    <time>2020-05-01T10:00:00Z</time>
+    <name>start</name>
+    <sym>Flag</sym>
+  </wpt>
+  <wpt lat="52.6" lon="13.5"/>
+  <rte>
+    <name>route</name>
+    <number>2</number>
+    <rtept lat="1" lon="2"/>
+    <rtept lat="3" lon="4"/>
+  </rte>
+  <trk>
+    <name>track</name>
+    <type>walking</type>
+    <trkseg>
+      <trkpt lat="1" lon="2">
+        <ele>10</ele>
+        <time>2020-05-01T10:00:00Z</time>
+      </trkpt>
+      <trkpt lat="3" lon="4">
+        <time>2020-05-01T10:00:01.5Z</time>
+      </trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="5" lon="6"><ele>12</ele></trkpt>
+    </trkseg>
+  </trk>
+  <trk>
+    <trkseg>
+      <trkpt lat="1" lon="2"><time>2020-05-01T10:00:00</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>
+`
+
+func TestDecode(t *testing.T) {
+	got, err := gpx.Decode(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+
+	if got.Creator != "test" || got.Name != "walk" || !got.Time.Equal(t0) {
+		t.Errorf("metadata, expected test walk %v got %v %v %v", t0, got.Creator, got.Name, got.Time)
+	}
+
+	expected := []gpx.Feature{
+		{
+			Geometry:   geom.PointZ{13.4, 52.5, 34.5},
+			Properties: map[string]interface{}{"name": "start", "sym": "Flag", "time": t0},
+		},
+		{
+			Geometry:   geom.Point{13.5, 52.6},
+			Properties: map[string]interface{}{},
+		},
+		{
+			Geometry:   geom.LineString{{2, 1}, {4, 3}},
+			Properties: map[string]interface{}{"name": "route", "number": 2},
+		},
+		{
+			Geometry: geom.MultiLineStringZM{
+				{{2, 1, 10, m0}, {4, 3, nan, m0 + 1.5}},
+				{{6, 5, 12, nan}},
+			},
+			Properties: map[string]interface{}{"name": "track", "type": "walking"},
+		},
+		{
+			Geometry:   geom.LineStringM{{2, 1, m0}},
+			Properties: map[string]interface{}{},
+		},
+	}
+
+	var features []gpx.Feature
+	features = append(features, got.Waypoints...)
+	features = append(features, got.Routes...)
+	features = append(features, got.Tracks...)
+	if len(features) != len(expected) {
+		t.Fatalf("features, expected %v got %v", len(expected), len(features))
+	}
+	for i, f := range features {
+		// compare the text of the geometries, as NaN is not equal to itself
+		if g, e := fmt.Sprintf("%T%v", f.Geometry, f.Geometry), fmt.Sprintf("%T%v", expected[i].Geometry, expected[i].Geometry); g != e {
+			t.Errorf("feature %v geometry, expected %v got %v", i, e, g)
+		}
+		if !reflect.DeepEqual(f.Properties, expected[i].Properties) {
+			t.Errorf("feature %v properties, expected %v got %v", i, expected[i].Properties, f.Properties)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	type tcase struct {
+		doc      gpx.GPX
+		expected gpx.GPX
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := gpx.Encode(&buf, &tc.doc); err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			got, err := gpx.Decode(&buf)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(*got, tc.expected) {
+				t.Errorf("document, expected %+v got %+v", tc.expected, *got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"empty": {
+			expected: gpx.GPX{Creator: gpx.DefaultCreator},
+		},
+		"metadata": {
+			doc:      gpx.GPX{Creator: "me", Name: "n", Description: "d", Time: t0},
+			expected: gpx.GPX{Creator: "me", Name: "n", Description: "d", Time: t0},
+		},
+		"waypoints": {
+			doc: gpx.GPX{
+				Waypoints: []gpx.Feature{
+					{Geometry: geom.Point{0.00001, -45}, Properties: map[string]interface{}{"name": "a", "desc": "b", "ignored": 1}},
+					{Geometry: geom.PointZ{1, 2, 3}, Properties: map[string]interface{}{"time": t0, "type": "c", "cmt": "d", "src": "e"}},
+				},
+			},
+			expected: gpx.GPX{
+				Creator: gpx.DefaultCreator,
+				Waypoints: []gpx.Feature{
+					{Geometry: geom.Point{0.00001, -45}, Properties: map[string]interface{}{"name": "a", "desc": "b"}},
+					{Geometry: geom.PointZ{1, 2, 3}, Properties: map[string]interface{}{"time": t0, "type": "c", "cmt": "d", "src": "e"}},
+				},
+			},
+		},
+		"routes": {
+			doc: gpx.GPX{
+				Routes: []gpx.Feature{
+					{Geometry: geom.LineStringZ{{1, 2, 3}, {4, 5, 6}}, Properties: map[string]interface{}{"number": uint8(7)}},
+					{Geometry: geom.LineStringM{{1, 2, m0}}},
+				},
+			},
+			expected: gpx.GPX{
+				Creator: gpx.DefaultCreator,
+				Routes: []gpx.Feature{
+					{Geometry: geom.LineStringZ{{1, 2, 3}, {4, 5, 6}}, Properties: map[string]interface{}{"number": 7}},
+					{Geometry: geom.LineString{{1, 2}}, Properties: map[string]interface{}{}},
+				},
+			},
+		},
+		"tracks": {
+			doc: gpx.GPX{
+				Tracks: []gpx.Feature{
+					{Geometry: geom.LineStringM{{1, 2, m0}, {3, 4, m0 + 0.25}}, Properties: map[string]interface{}{"name": "t"}},
+					{Geometry: geom.MultiLineStringZM{{{1, 2, 3, m0}}, {{4, 5, 6, m0 + 60}}}},
+					{Geometry: geom.MultiLineStringM{{{1, 2, m0}}, {{3, 4, m0}}}},
+				},
+			},
+			expected: gpx.GPX{
+				Creator: gpx.DefaultCreator,
+				Tracks: []gpx.Feature{
+					{Geometry: geom.LineStringM{{1, 2, m0}, {3, 4, m0 + 0.25}}, Properties: map[string]interface{}{"name": "t"}},
+					{Geometry: geom.MultiLineStringZM{{{1, 2, 3, m0}}, {{4, 5, 6, m0 + 60}}}, Properties: map[string]interface{}{}},
+					{Geometry: geom.MultiLineStringM{{{1, 2, m0}}, {{3, 4, m0}}}, Properties: map[string]interface{}{}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	type tcase struct {
+		doc gpx.GPX
+		err error
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			err := gpx.Encode(&bytes.Buffer{}, &tc.doc)
+			if err == nil {
+				t.Fatalf("error, expected %v got nil", tc.err)
+			}
+			var unknown encoding.ErrUnknownGeometry
+			if errors.As(tc.err, &unknown) {
+				if !errors.As(err, &unknown) {
+					t.Errorf("error, expected %v got %v", tc.err, err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(err, tc.err) {
+				t.Errorf("error, expected %v got %v", tc.err, err)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"waypoint line string": {
+			doc: gpx.GPX{Waypoints: []gpx.Feature{{Geometry: geom.LineString{{1, 2}}}}},
+			err: encoding.ErrUnknownGeometry{},
+		},
+		"route multi line string": {
+			doc: gpx.GPX{Routes: []gpx.Feature{{Geometry: geom.MultiLineString{{{1, 2}}, {{3, 4}}}}}},
+			err: encoding.ErrUnknownGeometry{},
+		},
+		"track polygon": {
+			doc: gpx.GPX{Tracks: []gpx.Feature{{Geometry: geom.Polygon{{{1, 2}, {3, 4}, {5, 6}}}}}},
+			err: encoding.ErrUnknownGeometry{},
+		},
+		"name not a string": {
+			doc: gpx.GPX{Waypoints: []gpx.Feature{{Geometry: geom.Point{1, 2}, Properties: map[string]interface{}{"name": 1}}}},
+			err: gpx.ErrInvalidProperty{Key: "name", Value: 1},
+		},
+		"time not a time": {
+			doc: gpx.GPX{Waypoints: []gpx.Feature{{Geometry: geom.Point{1, 2}, Properties: map[string]interface{}{"time": "now"}}}},
+			err: gpx.ErrInvalidProperty{Key: "time", Value: "now"},
+		},
+		"negative number": {
+			doc: gpx.GPX{Tracks: []gpx.Feature{{Geometry: geom.LineString{{1, 2}}, Properties: map[string]interface{}{"number": -1}}}},
+			err: gpx.ErrInvalidProperty{Key: "number", Value: -1},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestMToTime(t *testing.T) {
+	tm := time.Date(2021, 1, 2, 3, 4, 5, 678e6, time.UTC)
+	if got := gpx.MToTime(gpx.TimeToM(tm)); !got.Equal(tm) {
+		t.Errorf("time, expected %v got %v", tm, got)
+	}
+}
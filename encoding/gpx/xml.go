@@ -0,0 +1,99 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the elements of a GPX document, matched without their namespace when reading so
+// GPX 1.0 tracks, routes and waypoints are also read
+
+type gpxXML struct {
+	XMLName  xml.Name     `xml:"gpx"`
+	Xmlns    string       `xml:"xmlns,attr,omitempty"`
+	Version  string       `xml:"version,attr"`
+	Creator  string       `xml:"creator,attr"`
+	Metadata *metadataXML `xml:"metadata"`
+	Wpts     []wptXML     `xml:"wpt"`
+	Rtes     []rteXML     `xml:"rte"`
+	Trks     []trkXML     `xml:"trk"`
+}
+
+type metadataXML struct {
+	Name string     `xml:"name,omitempty"`
+	Desc string     `xml:"desc,omitempty"`
+	Time *timestamp `xml:"time"`
+}
+
+// wptXML is a waypoint, route point or track point
+type wptXML struct {
+	Lat  decimal    `xml:"lat,attr"`
+	Lon  decimal    `xml:"lon,attr"`
+	Ele  *decimal   `xml:"ele"`
+	Time *timestamp `xml:"time"`
+	Name string     `xml:"name,omitempty"`
+	Cmt  string     `xml:"cmt,omitempty"`
+	Desc string     `xml:"desc,omitempty"`
+	Src  string     `xml:"src,omitempty"`
+	Sym  string     `xml:"sym,omitempty"`
+	Type string     `xml:"type,omitempty"`
+}
+
+type rteXML struct {
+	Name   string   `xml:"name,omitempty"`
+	Cmt    string   `xml:"cmt,omitempty"`
+	Desc   string   `xml:"desc,omitempty"`
+	Src    string   `xml:"src,omitempty"`
+	Number *int     `xml:"number"`
+	Type   string   `xml:"type,omitempty"`
+	Rtepts []wptXML `xml:"rtept"`
+}
+
+type trkXML struct {
+	Name    string      `xml:"name,omitempty"`
+	Cmt     string      `xml:"cmt,omitempty"`
+	Desc    string      `xml:"desc,omitempty"`
+	Src     string      `xml:"src,omitempty"`
+	Number  *int        `xml:"number"`
+	Type    string      `xml:"type,omitempty"`
+	Trksegs []trksegXML `xml:"trkseg"`
+}
+
+type trksegXML struct {
+	Trkpts []wptXML `xml:"trkpt"`
+}
+
+// decimal is a number written without an exponent, as the schema requires
+type decimal float64
+
+func (d decimal) MarshalText() ([]byte, error) {
+	return strconv.AppendFloat(nil, float64(d), 'f', -1, 64), nil
+}
+
+func (d *decimal) UnmarshalText(b []byte) error {
+	f, err := strconv.ParseFloat(strings.TrimSpace(string(b)), 64)
+	*d = decimal(f)
+	return err
+}
+
+// timestamp is a time written in UTC; times without a time zone are read as UTC
+type timestamp time.Time
+
+func (t timestamp) MarshalText() ([]byte, error) {
+	return time.Time(t).UTC().MarshalText()
+}
+
+func (t *timestamp) UnmarshalText(b []byte) error {
+	s := strings.TrimSpace(string(b))
+	tm, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		var lerr error
+		if tm, lerr = time.Parse("2006-01-02T15:04:05.999999999", s); lerr != nil {
+			return err
+		}
+	}
+	*t = timestamp(tm)
+	return nil
+}
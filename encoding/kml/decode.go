@@ -0,0 +1,268 @@
+package kml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hahaking119/geom"
+)
+
+// Decode reads the placemarks of a KML document, in the order they appear in its
+// documents and folders
+func Decode(r io.Reader) ([]Placemark, error) {
+	d := xml.NewDecoder(r)
+	var pms []Placemark
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return pms, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "Placemark" {
+			pm, err := decodePlacemark(d, start)
+			if err != nil {
+				return nil, err
+			}
+			pms = append(pms, pm)
+		}
+	}
+}
+
+// decodePlacemark reads the placemark of the start element
+func decodePlacemark(d *xml.Decoder, start xml.StartElement) (Placemark, error) {
+	pm := Placemark{Properties: make(map[string]interface{})}
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "id" {
+			pm.ID = attr.Value
+		}
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return Placemark{}, err
+		}
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			return pm, nil
+
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "name":
+				err = d.DecodeElement(&pm.Name, &tok)
+				pm.Name = strings.TrimSpace(pm.Name)
+			case "description":
+				err = d.DecodeElement(&pm.Description, &tok)
+				pm.Description = strings.TrimSpace(pm.Description)
+			case "ExtendedData":
+				var ed extendedDataXML
+				err = d.DecodeElement(&ed, &tok)
+				for _, data := range ed.Data {
+					pm.Properties[data.Name] = data.Value
+				}
+				for _, sd := range ed.SchemaData {
+					for _, data := range sd.SimpleData {
+						pm.Properties[data.Name] = data.Value
+					}
+				}
+			default:
+				var geo geom.Geometry
+				if geo, err = decodeGeometry(d, tok); geo != nil {
+					pm.Geometry = geo
+				}
+			}
+			if err != nil {
+				return Placemark{}, fmt.Errorf("placemark %q: %v", pm.Name, err)
+			}
+		}
+	}
+}
+
+// decodeGeometry reads the geometry of the start element; elements that are not
+// geometries are skipped and have a nil geometry
+func decodeGeometry(d *xml.Decoder, start xml.StartElement) (geom.Geometry, error) {
+	switch start.Name.Local {
+	case "Point":
+		var c coordinatesXML
+		if err := d.DecodeElement(&c, &start); err != nil {
+			return nil, err
+		}
+		pts, hasZ, err := decodeCoordinates(c.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		if len(pts) != 1 {
+			return nil, fmt.Errorf("point with %v coordinates", len(pts))
+		}
+		if hasZ {
+			return geom.PointZ(pts[0]), nil
+		}
+		return geom.Point{pts[0][0], pts[0][1]}, nil
+
+	case "LineString", "LinearRing":
+		var c coordinatesXML
+		if err := d.DecodeElement(&c, &start); err != nil {
+			return nil, err
+		}
+		pts, hasZ, err := decodeCoordinates(c.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		if hasZ {
+			return geom.LineStringZ(pts), nil
+		}
+		return geom.LineString(points2(pts)), nil
+
+	case "Polygon":
+		var p polygonXML
+		if err := d.DecodeElement(&p, &start); err != nil {
+			return nil, err
+		}
+		coords := []string{p.Outer}
+		for _, b := range p.Inner {
+			coords = append(coords, b.Rings...)
+		}
+		var (
+			rings = make([][][3]float64, len(coords))
+			hasZ  bool
+		)
+		for i, c := range coords {
+			pts, z, err := decodeCoordinates(c)
+			if err != nil {
+				return nil, err
+			}
+			// rings are closed in KML and open in geom
+			if n := len(pts); n > 1 && pts[0] == pts[n-1] {
+				pts = pts[:n-1]
+			}
+			rings[i], hasZ = pts, hasZ || z
+		}
+		if hasZ {
+			return geom.PolygonZ(rings), nil
+		}
+		poly := make(geom.Polygon, len(rings))
+		for i, ring := range rings {
+			poly[i] = points2(ring)
+		}
+		return poly, nil
+
+	case "MultiGeometry":
+		var geos []geom.Geometry
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch tok := tok.(type) {
+			case xml.EndElement:
+				return newMultiGeometry(geos), nil
+			case xml.StartElement:
+				geo, err := decodeGeometry(d, tok)
+				if err != nil {
+					return nil, err
+				}
+				if geo != nil {
+					geos = append(geos, geo)
+				}
+			}
+		}
+
+	default:
+		return nil, d.Skip()
+	}
+}
+
+// newMultiGeometry returns the multi geometry of geometries of the same type, or a
+// collection of them
+func newMultiGeometry(geos []geom.Geometry) geom.Geometry {
+	if len(geos) == 0 {
+		return geom.Collection{}
+	}
+	typ := reflect.TypeOf(geos[0])
+	for _, geo := range geos[1:] {
+		if reflect.TypeOf(geo) != typ {
+			return geom.Collection(geos)
+		}
+	}
+
+	switch geos[0].(type) {
+	case geom.Point:
+		mp := make(geom.MultiPoint, len(geos))
+		for i, geo := range geos {
+			mp[i] = geo.(geom.Point)
+		}
+		return mp
+	case geom.PointZ:
+		mp := make(geom.MultiPointZ, len(geos))
+		for i, geo := range geos {
+			mp[i] = geo.(geom.PointZ)
+		}
+		return mp
+	case geom.LineString:
+		mls := make(geom.MultiLineString, len(geos))
+		for i, geo := range geos {
+			mls[i] = geo.(geom.LineString)
+		}
+		return mls
+	case geom.LineStringZ:
+		mls := make(geom.MultiLineStringZ, len(geos))
+		for i, geo := range geos {
+			mls[i] = geo.(geom.LineStringZ)
+		}
+		return mls
+	case geom.Polygon:
+		mp := make(geom.MultiPolygon, len(geos))
+		for i, geo := range geos {
+			mp[i] = geo.(geom.Polygon)
+		}
+		return mp
+	default:
+		return geom.Collection(geos)
+	}
+}
+
+var commaSpace = regexp.MustCompile(`\s*,\s*`)
+
+// decodeCoordinates returns the coordinates of the text of a coordinates element,
+// tuples of longitude, latitude and an optional altitude separated by commas, and
+// whether any of them has an altitude
+func decodeCoordinates(s string) ([][3]float64, bool, error) {
+	// some writers put spaces around the commas of a tuple
+	s = commaSpace.ReplaceAllString(s, ",")
+	var (
+		pts  [][3]float64
+		hasZ bool
+	)
+	for _, tuple := range strings.Fields(s) {
+		vals := strings.Split(tuple, ",")
+		if len(vals) < 2 || len(vals) > 3 {
+			return nil, false, fmt.Errorf("invalid coordinates %q", tuple)
+		}
+		var pt [3]float64
+		for i, v := range vals {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid coordinates %q", tuple)
+			}
+			pt[i] = f
+		}
+		hasZ = hasZ || len(vals) == 3
+		pts = append(pts, pt)
+	}
+	return pts, hasZ, nil
+}
+
+func points2(pts [][3]float64) [][2]float64 {
+	pts2 := make([][2]float64, len(pts))
+	for i, pt := range pts {
+		pts2[i] = [2]float64{pt[0], pt[1]}
+	}
+	return pts2
+}
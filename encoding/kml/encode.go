@@ -0,0 +1,193 @@
+package kml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+)
+
+// Encode writes a KML document of the placemarks. Geometries with z values have an
+// absolute altitude mode, and multi geometries and collections are written as
+// multi geometries. Properties are written as the data of the extended data of the
+// placemarks, sorted by name; times are written in RFC 3339 and other values as
+// formatted by fmt.
+func Encode(w io.Writer, placemarks ...Placemark) error {
+	doc := kmlXML{Xmlns: Namespace}
+	for _, pm := range placemarks {
+		p := placemarkXML{ID: pm.ID, Name: pm.Name, Description: pm.Description}
+		if pm.Geometry != nil {
+			var err error
+			if p.Geometry, err = encodeGeometry(pm.Geometry); err != nil {
+				return err
+			}
+		}
+		if len(pm.Properties) > 0 {
+			p.ExtendedData = encodeProperties(pm.Properties)
+		}
+		doc.Document.Placemarks = append(doc.Document.Placemarks, p)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// encodeProperties returns the extended data of the properties
+func encodeProperties(props map[string]interface{}) *extendedDataXML {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ed := new(extendedDataXML)
+	for _, k := range keys {
+		var s string
+		switch v := props[k].(type) {
+		case nil:
+		case string:
+			s = v
+		case time.Time:
+			s = v.Format(time.RFC3339Nano)
+		default:
+			s = fmt.Sprint(v)
+		}
+		ed.Data = append(ed.Data, dataXML{Name: k, Value: s})
+	}
+	return ed
+}
+
+// encodeGeometry returns the geometry element of the geometry
+func encodeGeometry(geo geom.Geometry) (interface{}, error) {
+	switch geo := geo.(type) {
+	case geom.Point:
+		return pointXML{Coordinates: encodeCoordinates(false, geo[:])}, nil
+	case geom.PointZ:
+		return pointXML{AltitudeMode: "absolute", Coordinates: encodeCoordinates(true, geo[:])}, nil
+
+	case geom.LineString:
+		return lineStringXML{Coordinates: encodeCoordinates(false, coords2(geo)...)}, nil
+	case geom.LineStringZ:
+		return lineStringXML{AltitudeMode: "absolute", Coordinates: encodeCoordinates(true, coords3(geo)...)}, nil
+
+	case geom.Polygon:
+		rings := make([][][]float64, len(geo))
+		for i, ring := range geo {
+			rings[i] = coords2(ring)
+		}
+		return encodePolygon(false, rings), nil
+	case geom.PolygonZ:
+		rings := make([][][]float64, len(geo))
+		for i, ring := range geo {
+			rings[i] = coords3(ring)
+		}
+		return encodePolygon(true, rings), nil
+
+	case geom.MultiPoint:
+		return encodeMultiGeometry(len(geo), func(i int) geom.Geometry { return geom.Point(geo[i]) })
+	case geom.MultiPointZ:
+		return encodeMultiGeometry(len(geo), func(i int) geom.Geometry { return geom.PointZ(geo[i]) })
+	case geom.MultiLineString:
+		return encodeMultiGeometry(len(geo), func(i int) geom.Geometry { return geom.LineString(geo[i]) })
+	case geom.MultiLineStringZ:
+		return encodeMultiGeometry(len(geo), func(i int) geom.Geometry { return geom.LineStringZ(geo[i]) })
+	case geom.MultiPolygon:
+		return encodeMultiGeometry(len(geo), func(i int) geom.Geometry { return geom.Polygon(geo[i]) })
+	case geom.Collection:
+		return encodeMultiGeometry(len(geo), func(i int) geom.Geometry { return geo[i] })
+
+	default:
+		return nil, encoding.ErrUnknownGeometry{Geom: geo}
+	}
+}
+
+func encodeMultiGeometry(n int, geo func(i int) geom.Geometry) (interface{}, error) {
+	mg := multiGeometryXML{Geometries: make([]interface{}, n)}
+	for i := range mg.Geometries {
+		var err error
+		if mg.Geometries[i], err = encodeGeometry(geo(i)); err != nil {
+			return nil, err
+		}
+	}
+	return mg, nil
+}
+
+// encodePolygon returns the polygon of the rings, which are closed as KML requires
+func encodePolygon(hasZ bool, rings [][][]float64) polygonXML {
+	var p polygonXML
+	if hasZ {
+		p.AltitudeMode = "absolute"
+	}
+	for i, ring := range rings {
+		if n := len(ring); n > 0 && !equal(ring[0], ring[n-1]) {
+			ring = append(ring, ring[0])
+		}
+		c := encodeCoordinates(hasZ, ring...)
+		if i == 0 {
+			p.Outer = c
+			continue
+		}
+		p.Inner = append(p.Inner, boundaryXML{Rings: []string{c}})
+	}
+	return p
+}
+
+// encodeCoordinates returns the text of a coordinates element of the points
+func encodeCoordinates(hasZ bool, pts ...[]float64) string {
+	dims := 2
+	if hasZ {
+		dims = 3
+	}
+	var sb strings.Builder
+	for i, pt := range pts {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		for j := 0; j < dims; j++ {
+			if j > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(strconv.FormatFloat(pt[j], 'f', -1, 64))
+		}
+	}
+	return sb.String()
+}
+
+func coords2(pts [][2]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func coords3(pts [][3]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func equal(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
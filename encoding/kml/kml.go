@@ -0,0 +1,103 @@
+// Package kml implements reading and writing of the placemarks of KML 2.2 documents
+// as described at https://developers.google.com/kml/documentation/kmlreference.
+//
+// A placemark is a geometry, a name, a description and the data of its extended
+// data as properties. Points, line strings, linear rings and polygons are read as
+// their geom types, with z values when any coordinate has an altitude; linear rings
+// are read as line strings. Multi geometries of points, of line strings or of
+// polygons without z values are read as the matching multi geometry and other multi
+// geometries as collections. Properties are read as strings.
+package kml
+
+import (
+	"encoding/xml"
+
+	"github.com/hahaking119/geom"
+)
+
+// Namespace is the XML namespace of KML 2.2 documents
+const Namespace = "http://www.opengis.net/kml/2.2"
+
+// Placemark is a geometry and its properties
+type Placemark struct {
+	ID          string
+	Name        string
+	Description string
+	Geometry    geom.Geometry
+	// Properties are the data of the extended data of the placemark
+	Properties map[string]interface{}
+}
+
+// the elements of a KML document, matched without their namespace when reading
+
+type kmlXML struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document documentXML `xml:"Document"`
+}
+
+type documentXML struct {
+	Placemarks []placemarkXML `xml:"Placemark"`
+}
+
+type placemarkXML struct {
+	ID           string           `xml:"id,attr,omitempty"`
+	Name         string           `xml:"name,omitempty"`
+	Description  string           `xml:"description,omitempty"`
+	ExtendedData *extendedDataXML `xml:"ExtendedData"`
+	// Geometry is one of the geometry elements
+	Geometry interface{}
+}
+
+type extendedDataXML struct {
+	Data       []dataXML       `xml:"Data"`
+	SchemaData []schemaDataXML `xml:"SchemaData"`
+}
+
+type dataXML struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type schemaDataXML struct {
+	SimpleData []simpleDataXML `xml:"SimpleData"`
+}
+
+type simpleDataXML struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// coordinatesXML is a point, line string or linear ring when reading
+type coordinatesXML struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type pointXML struct {
+	XMLName      xml.Name `xml:"Point"`
+	AltitudeMode string   `xml:"altitudeMode,omitempty"`
+	Coordinates  string   `xml:"coordinates"`
+}
+
+type lineStringXML struct {
+	XMLName      xml.Name `xml:"LineString"`
+	AltitudeMode string   `xml:"altitudeMode,omitempty"`
+	Coordinates  string   `xml:"coordinates"`
+}
+
+type polygonXML struct {
+	XMLName      xml.Name      `xml:"Polygon"`
+	AltitudeMode string        `xml:"altitudeMode,omitempty"`
+	Outer        string        `xml:"outerBoundaryIs>LinearRing>coordinates"`
+	Inner        []boundaryXML `xml:"innerBoundaryIs"`
+}
+
+// boundaryXML is an inner boundary, which some writers give more than one ring
+type boundaryXML struct {
+	Rings []string `xml:"LinearRing>coordinates"`
+}
+
+type multiGeometryXML struct {
+	XMLName    xml.Name `xml:"MultiGeometry"`
+	Geometries []interface{}
+}
@@ -0,0 +1,237 @@
+package kml_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding/kml"
+)
+
+const document = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <name>doc</name>
+    <Style id="s"><LineStyle><width>2</width></LineStyle></Style>
+    <Folder>
+      <name>folder</name>
+      <Placemark id="p1">
+        <name> point </name>
+        <description><![CDATA[<b>bold</b>]]></description>
+        <styleUrl>#s</styleUrl>
+        <ExtendedData>
+          <Data name="a"><value>1</value></Data>
+          <SchemaData schemaUrl="#schema">
+            <SimpleData name="b">two</SimpleData>
+          </SchemaData>
+        </ExtendedData>
+        <Point>
+          <coordinates>
+            13.4, 52.5
+          </coordinates>
+        </Point>
+      </Placemark>
+    </Folder>
+    <Placemark>
+      <Polygon>
+        <outerBoundaryIs><LinearRing><coordinates>0,0,1 10,0,1 10,10,1 0,0,1</coordinates></LinearRing></outerBoundaryIs>
+        <innerBoundaryIs><LinearRing><coordinates>1,1 2,1 2,2 1,1</coordinates></LinearRing></innerBoundaryIs>
+      </Polygon>
+    </Placemark>
+    <Placemark>
+      <MultiGeometry>
+        <Point><coordinates>1,2</coordinates></Point>
+        <LineString><coordinates>1,2 3,4</coordinates></LineString>
+      </MultiGeometry>
+    </Placemark>
+    <Placemark>
+      <MultiGeometry>
+        <LinearRing><coordinates>1,2 3,4 5,6 1,2</coordinates></LinearRing>
+        <LineString><coordinates>1,2 3,4</coordinates></LineString>
+      </MultiGeometry>
+    </Placemark>
+    <Placemark><name>no geometry</name></Placemark>
+  </Document>
+</kml>
+`
+
+func TestDecode(t *testing.T) {
+	got, err := kml.Decode(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+
+	expected := []kml.Placemark{
+		{
+			ID:          "p1",
+			Name:        "point",
+			Description: "<b>bold</b>",
+			Geometry:    geom.Point{13.4, 52.5},
+			Properties:  map[string]interface{}{"a": "1", "b": "two"},
+		},
+		{
+			Geometry: geom.PolygonZ{
+				{{0, 0, 1}, {10, 0, 1}, {10, 10, 1}},
+				{{1, 1, 0}, {2, 1, 0}, {2, 2, 0}},
+			},
+			Properties: map[string]interface{}{},
+		},
+		{
+			Geometry:   geom.Collection{geom.Point{1, 2}, geom.LineString{{1, 2}, {3, 4}}},
+			Properties: map[string]interface{}{},
+		},
+		{
+			Geometry:   geom.MultiLineString{{{1, 2}, {3, 4}, {5, 6}, {1, 2}}, {{1, 2}, {3, 4}}},
+			Properties: map[string]interface{}{},
+		},
+		{
+			Name:       "no geometry",
+			Properties: map[string]interface{}{},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("placemarks, expected %v got %v", expected, got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	type tcase struct {
+		placemark kml.Placemark
+		expected  kml.Placemark
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := kml.Encode(&buf, tc.placemark); err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			got, err := kml.Decode(&buf)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("placemarks, expected 1 got %v", len(got))
+			}
+			if !reflect.DeepEqual(got[0], tc.expected) {
+				t.Errorf("placemark, expected %v got %v", tc.expected, got[0])
+			}
+		}
+	}
+
+	empty := map[string]interface{}{}
+	tests := map[string]tcase{
+		"point": {
+			placemark: kml.Placemark{ID: "a", Name: "b", Description: "c & d", Geometry: geom.Point{0.00001, -45}},
+			expected:  kml.Placemark{ID: "a", Name: "b", Description: "c & d", Geometry: geom.Point{0.00001, -45}, Properties: empty},
+		},
+		"point z": {
+			placemark: kml.Placemark{Geometry: geom.PointZ{1, 2, 3}},
+			expected:  kml.Placemark{Geometry: geom.PointZ{1, 2, 3}, Properties: empty},
+		},
+		"line string z": {
+			placemark: kml.Placemark{Geometry: geom.LineStringZ{{1, 2, 3}, {4, 5, 6}}},
+			expected:  kml.Placemark{Geometry: geom.LineStringZ{{1, 2, 3}, {4, 5, 6}}, Properties: empty},
+		},
+		"polygon": {
+			placemark: kml.Placemark{Geometry: geom.Polygon{{{0, 0}, {10, 0}, {10, 10}}, {{1, 1}, {2, 1}, {2, 2}}}},
+			expected:  kml.Placemark{Geometry: geom.Polygon{{{0, 0}, {10, 0}, {10, 10}}, {{1, 1}, {2, 1}, {2, 2}}}, Properties: empty},
+		},
+		"multi point": {
+			placemark: kml.Placemark{Geometry: geom.MultiPoint{{1, 2}, {3, 4}}},
+			expected:  kml.Placemark{Geometry: geom.MultiPoint{{1, 2}, {3, 4}}, Properties: empty},
+		},
+		"multi line string z": {
+			placemark: kml.Placemark{Geometry: geom.MultiLineStringZ{{{1, 2, 3}, {4, 5, 6}}, {{7, 8, 9}, {1, 2, 3}}}},
+			expected:  kml.Placemark{Geometry: geom.MultiLineStringZ{{{1, 2, 3}, {4, 5, 6}}, {{7, 8, 9}, {1, 2, 3}}}, Properties: empty},
+		},
+		"multi polygon": {
+			placemark: kml.Placemark{Geometry: geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}}}, {{{5, 5}, {6, 5}, {6, 6}}}}},
+			expected:  kml.Placemark{Geometry: geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}}}, {{{5, 5}, {6, 5}, {6, 6}}}}, Properties: empty},
+		},
+		"collection": {
+			placemark: kml.Placemark{Geometry: geom.Collection{
+				geom.PolygonZ{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}}},
+				geom.Collection{geom.Point{1, 2}},
+			}},
+			expected: kml.Placemark{Geometry: geom.Collection{
+				geom.PolygonZ{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}}},
+				geom.MultiPoint{{1, 2}},
+			}, Properties: empty},
+		},
+		"properties": {
+			placemark: kml.Placemark{Properties: map[string]interface{}{
+				"string": "<a>",
+				"int":    42,
+				"float":  1.5,
+				"bool":   true,
+				"time":   time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC),
+				"nil":    nil,
+			}},
+			expected: kml.Placemark{Properties: map[string]interface{}{
+				"string": "<a>",
+				"int":    "42",
+				"float":  "1.5",
+				"bool":   "true",
+				"time":   "2020-05-01T10:00:00Z",
+				"nil":    "",
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	type tcase struct {
+		geo geom.Geometry
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			if err := kml.Encode(&bytes.Buffer{}, kml.Placemark{Geometry: tc.geo}); err == nil {
+				t.Errorf("error, expected error got nil")
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point m":               {geo: geom.PointM{1, 2, 3}},
+		"line string m":         {geo: geom.LineStringM{{1, 2, 3}}},
+		"collection of point m": {geo: geom.Collection{geom.PointM{1, 2, 3}}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	type tcase struct {
+		doc string
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			if _, err := kml.Decode(strings.NewReader(tc.doc)); err == nil {
+				t.Errorf("error, expected error got nil")
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"invalid number":     {doc: `<kml><Placemark><Point><coordinates>1,a</coordinates></Point></Placemark></kml>`},
+		"one value":          {doc: `<kml><Placemark><Point><coordinates>1</coordinates></Point></Placemark></kml>`},
+		"point of two":       {doc: `<kml><Placemark><Point><coordinates>1,2 3,4</coordinates></Point></Placemark></kml>`},
+		"unclosed placemark": {doc: `<kml><Placemark><name>a</name>`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
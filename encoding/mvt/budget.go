@@ -0,0 +1,647 @@
+package mvt
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/planar"
+	"github.com/hahaking119/geom/planar/makevalid"
+	"github.com/hahaking119/geom/planar/makevalid/hitmap"
+	"github.com/hahaking119/geom/planar/makevalid/walker"
+	"github.com/hahaking119/geom/planar/simplify"
+)
+
+// DefaultMaxSize is the maximum encoded tile size, in bytes, commonly used by
+// renderers and tile servers.
+const DefaultMaxSize = 500 * 1024
+
+// maxBudgetTolerance is the largest simplification tolerance, in pixels, used to
+// fit a tile into its budget before features are dropped.
+const maxBudgetTolerance = 8.0
+
+// ErrOverBudget is returned when a tile can not be made to fit its max size
+var ErrOverBudget = fmt.Errorf("tile is over its max size")
+
+// BudgetReport describes what was done to a tile to fit it into its max size.
+type BudgetReport struct {
+	// Size is the size of the encoded tile in bytes
+	Size int
+	// Coalesced is the number of features that were merged into another
+	// feature, by layer name
+	Coalesced map[string]int
+	// Tolerance is the simplification tolerance, in pixels, that was used;
+	// 0 if the geometries were not simplified
+	Tolerance float64
+	// Dropped are the features that were dropped, by layer name
+	Dropped map[string][]Feature
+}
+
+// Encode returns the tile encoded as protobuf bytes. If MaxSize is set and the tile is
+// larger, the following is done to a copy of the tile until it fits:
+//
+//  1. features in a layer with identical tags and the same kind of geometry are coalesced
+//     into a single feature: points into a MultiPoint, lines that share end points are
+//     merged and polygons that touch or overlap are unioned. Coalesced features lose
+//     their IDs.
+//  2. the geometries are simplified with a tolerance doubling from 1 to 8 pixels.
+//  3. the smallest features are dropped; the size of a polygon is its area and of a line
+//     its length and of points their number, so a point is the size of a pixel.
+//
+// The report describes what was done. ErrOverBudget is returned if the tile can not fit.
+func (t *Tile) Encode(ctx context.Context) ([]byte, *BudgetReport, error) {
+	report := &BudgetReport{
+		Coalesced: make(map[string]int),
+		Dropped:   make(map[string][]Feature),
+	}
+
+	b, err := marshalLayers(ctx, t.layers)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Size = len(b)
+	if t.MaxSize <= 0 || len(b) <= t.MaxSize {
+		return b, report, nil
+	}
+
+	// coalesce features
+	layers := make([]Layer, len(t.layers))
+	for i := range t.layers {
+		layers[i] = t.layers[i]
+		layers[i].features, report.Coalesced[layers[i].Name], err = coalesceFeatures(ctx, layers[i].features, layers[i].ValueOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		if report.Coalesced[layers[i].Name] == 0 {
+			delete(report.Coalesced, layers[i].Name)
+		}
+	}
+	if b, err = marshalLayers(ctx, layers); err != nil {
+		return nil, nil, err
+	}
+	report.Size = len(b)
+	if len(b) <= t.MaxSize {
+		return b, report, nil
+	}
+
+	// simplify, always from the coalesced geometries
+	coalesced := layers
+	for tol := 1.0; tol <= maxBudgetTolerance; tol *= 2 {
+		layers, err = simplifyLayers(ctx, coalesced, tol)
+		if err != nil {
+			return nil, nil, err
+		}
+		if b, err = marshalLayers(ctx, layers); err != nil {
+			return nil, nil, err
+		}
+		report.Size, report.Tolerance = len(b), tol
+		if len(b) <= t.MaxSize {
+			return b, report, nil
+		}
+	}
+
+	// drop the smallest features
+	type sizedFeature struct {
+		layer, idx int
+		size       float64
+	}
+	var sized []sizedFeature
+	for i := range layers {
+		for j := range layers[i].features {
+			sized = append(sized, sizedFeature{
+				layer: i,
+				idx:   j,
+				size:  featureSize(layers[i].features[j].Geometry),
+			})
+		}
+	}
+	sort.SliceStable(sized, func(i, j int) bool { return sized[i].size < sized[j].size })
+
+	// dropSmallest returns the layers without the n smallest features
+	dropSmallest := func(n int) []Layer {
+		dropped := make(map[[2]int]bool, n)
+		for _, f := range sized[:n] {
+			dropped[[2]int{f.layer, f.idx}] = true
+		}
+		kept := make([]Layer, len(layers))
+		for i := range layers {
+			kept[i] = layers[i]
+			kept[i].features = nil
+			for j := range layers[i].features {
+				if !dropped[[2]int{i, j}] {
+					kept[i].features = append(kept[i].features, layers[i].features[j])
+				}
+			}
+		}
+		return kept
+	}
+
+	// the encoded size shrinks as more features are dropped, so search
+	// for the fewest features that need to be dropped.
+	n := sort.Search(len(sized)+1, func(n int) bool {
+		if err != nil {
+			return true
+		}
+		var nb []byte
+		if nb, err = marshalLayers(ctx, dropSmallest(n)); err != nil {
+			return true
+		}
+		return len(nb) <= t.MaxSize
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if n > len(sized) {
+		return nil, report, ErrOverBudget
+	}
+
+	if b, err = marshalLayers(ctx, dropSmallest(n)); err != nil {
+		return nil, nil, err
+	}
+	report.Size = len(b)
+	for _, f := range sized[:n] {
+		name := layers[f.layer].Name
+		report.Dropped[name] = append(report.Dropped[name], layers[f.layer].features[f.idx])
+	}
+	return b, report, nil
+}
+
+func marshalLayers(ctx context.Context, layers []Layer) ([]byte, error) {
+	vt, err := (&Tile{layers: layers}).VTile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(vt)
+}
+
+// featureSize returns the area of polygons, the length of lines and the number of points.
+func featureSize(geo geom.Geometry) float64 {
+	ringArea := func(ring [][2]float64) float64 {
+		var a float64
+		for i := range ring {
+			j := (i + 1) % len(ring)
+			a += ring[i][0]*ring[j][1] - ring[j][0]*ring[i][1]
+		}
+		return math.Abs(a) / 2
+	}
+	polygonArea := func(ply [][][2]float64) float64 {
+		var a float64
+		for i := range ply {
+			if i == 0 {
+				a += ringArea(ply[i])
+				continue
+			}
+			a -= ringArea(ply[i])
+		}
+		return a
+	}
+	length := func(ls [][2]float64) float64 {
+		var l float64
+		for i := 1; i < len(ls); i++ {
+			l += math.Hypot(ls[i][0]-ls[i-1][0], ls[i][1]-ls[i-1][1])
+		}
+		return l
+	}
+
+	var size float64
+	switch g := geo.(type) {
+	case geom.Point:
+		size = 1
+	case geom.MultiPoint:
+		size = float64(len(g))
+	case geom.LineString:
+		size = length(g)
+	case geom.MultiLineString:
+		for _, ls := range g {
+			size += length(ls)
+		}
+	case geom.Polygon:
+		size = polygonArea(g)
+	case geom.MultiPolygon:
+		for _, p := range g {
+			size += polygonArea(p)
+		}
+	case *geom.MultiPolygon:
+		if g != nil {
+			size = featureSize(*g)
+		}
+	}
+	return size
+}
+
+func simplifyLayers(ctx context.Context, layers []Layer, tolerance float64) ([]Layer, error) {
+	simp := simplify.DouglasPeucker{Tolerance: tolerance}
+	simplified := make([]Layer, len(layers))
+	for i := range layers {
+		simplified[i] = layers[i]
+		simplified[i].features = make([]Feature, len(layers[i].features))
+		for j, f := range layers[i].features {
+			geo := f.Geometry
+			if mp, ok := geo.(*geom.MultiPolygon); ok && mp != nil {
+				geo = *mp
+			}
+			geo, err := planar.Simplify(ctx, simp, geo)
+			if err != nil {
+				return nil, err
+			}
+			f.Geometry = geo
+			simplified[i].features[j] = f
+		}
+	}
+	return simplified, nil
+}
+
+// geometry kinds that can be coalesced
+const (
+	kindNone = iota
+	kindPoint
+	kindLine
+	kindPolygon
+)
+
+func geometryKind(geo geom.Geometry) int {
+	switch geo.(type) {
+	case geom.Point, geom.MultiPoint:
+		return kindPoint
+	case geom.LineString, geom.MultiLineString:
+		return kindLine
+	case geom.Polygon, geom.MultiPolygon, *geom.MultiPolygon:
+		return kindPolygon
+	default:
+		return kindNone
+	}
+}
+
+// coalesceFeatures merges features with identical tags, as encoded with the value
+// options, and the same kind of geometry into the first of those features: points are
+// merged into a MultiPoint, lines only when they share an end point and polygons only
+// when they touch or overlap, in which case they are unioned. The number of features
+// merged is returned.
+func coalesceFeatures(ctx context.Context, features []Feature, opts ValueOptions) ([]Feature, int, error) {
+	groups := make(map[string][]int)
+	for i := range features {
+		kind := geometryKind(features[i].Geometry)
+		if kind == kindNone {
+			continue
+		}
+		tags, err := opts.normalizeTags(features[i].Tags)
+		if err != nil {
+			return nil, 0, err
+		}
+		key := fmt.Sprintf("%v %v", kind, tagsKey(tags))
+		groups[key] = append(groups[key], i)
+	}
+
+	// features are joined into sets, each set is merged into its first feature
+	parent := make([]int, len(features))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	join := func(i, j int) {
+		i, j = find(i), find(j)
+		switch {
+		case i < j:
+			parent[j] = i
+		case j < i:
+			parent[i] = j
+		}
+	}
+
+	for _, idxs := range groups {
+		for a := range idxs {
+			for _, j := range idxs[a+1:] {
+				i := idxs[a]
+				if find(i) == find(j) {
+					continue
+				}
+				switch geometryKind(features[i].Geometry) {
+				case kindPoint:
+					join(i, j)
+				case kindLine:
+					if linesConnect(features[i].Geometry, features[j].Geometry) {
+						join(i, j)
+					}
+				case kindPolygon:
+					if polygonsTouch(features[i].Geometry, features[j].Geometry) {
+						join(i, j)
+					}
+				}
+			}
+		}
+	}
+
+	sets := make(map[int][]geom.Geometry)
+	for i := range features {
+		root := find(i)
+		sets[root] = append(sets[root], features[i].Geometry)
+	}
+
+	coalesced := make([]Feature, 0, len(sets))
+	for i := range features {
+		geos, ok := sets[i]
+		if !ok {
+			continue
+		}
+		f := features[i]
+		if len(geos) > 1 {
+			geo, err := coalesceGeometries(ctx, geos)
+			if err != nil {
+				return nil, 0, err
+			}
+			f.ID = nil
+			f.Geometry = geo
+		}
+		coalesced = append(coalesced, f)
+	}
+	return coalesced, len(features) - len(coalesced), nil
+}
+
+// tagsKey returns a key of the normalized tags that is equal only for tags with the
+// same keys and values of the same MVT value type
+func tagsKey(tags map[string]interface{}) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(strconv.Quote(k))
+		b.WriteByte('=')
+		switch v := tags[k].(type) {
+		case string:
+			b.WriteString("s" + strconv.Quote(v))
+		case bool:
+			b.WriteString("b" + strconv.FormatBool(v))
+		case float32:
+			b.WriteString("f" + strconv.FormatFloat(float64(v), 'g', -1, 32))
+		case float64:
+			b.WriteString("d" + strconv.FormatFloat(v, 'g', -1, 64))
+		case intValue:
+			b.WriteString("i" + strconv.FormatInt(int64(v), 10))
+		case uintValue:
+			b.WriteString("u" + strconv.FormatUint(uint64(v), 10))
+		case sintValue:
+			b.WriteString("z" + strconv.FormatInt(int64(v), 10))
+		default:
+			// normalized tags only have the above types
+			b.WriteString(fmt.Sprintf("%T %#v", v, v))
+		}
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+// lineStrings returns the lines of a line geometry
+func lineStrings(geo geom.Geometry) [][][2]float64 {
+	switch g := geo.(type) {
+	case geom.LineString:
+		return [][][2]float64{g}
+	case geom.MultiLineString:
+		return g
+	}
+	return nil
+}
+
+// polygons returns the polygons of a polygon geometry
+func polygons(geo geom.Geometry) [][][][2]float64 {
+	switch g := geo.(type) {
+	case geom.Polygon:
+		return [][][][2]float64{g}
+	case geom.MultiPolygon:
+		return g
+	case *geom.MultiPolygon:
+		if g != nil {
+			return *g
+		}
+	}
+	return nil
+}
+
+// linesConnect reports if a line of a shares an end point with a line of b
+func linesConnect(a, b geom.Geometry) bool {
+	for _, la := range lineStrings(a) {
+		if len(la) == 0 {
+			continue
+		}
+		for _, lb := range lineStrings(b) {
+			if len(lb) == 0 {
+				continue
+			}
+			for _, pa := range [2][2]float64{la[0], la[len(la)-1]} {
+				if pa == lb[0] || pa == lb[len(lb)-1] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// polygonsTouch reports if a polygon of a touches or overlaps a polygon of b
+func polygonsTouch(a, b geom.Geometry) bool {
+	for _, pa := range polygons(a) {
+		for _, pb := range polygons(b) {
+			if polygonTouches(pa, pb) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// polygonTouches reports if the boundaries of the polygons touch or cross, or if
+// one polygon is inside of the other. A polygon inside a hole of the other does
+// not touch it.
+func polygonTouches(a, b [][][2]float64) bool {
+	if len(a) == 0 || len(a[0]) == 0 || len(b) == 0 || len(b[0]) == 0 {
+		return false
+	}
+	ea, eb := geom.NewExtent(a[0]...), geom.NewExtent(b[0]...)
+	if ea.MinX() > eb.MaxX() || eb.MinX() > ea.MaxX() || ea.MinY() > eb.MaxY() || eb.MinY() > ea.MaxY() {
+		return false
+	}
+
+	for _, ra := range a {
+		for i := range ra {
+			a1, a2 := ra[i], ra[(i+1)%len(ra)]
+			for _, rb := range b {
+				for j := range rb {
+					if segmentsTouch(a1, a2, rb[j], rb[(j+1)%len(rb)]) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	// the boundaries do not touch, so either one is inside the other or they are apart
+	return insidePolygon(a, b[0][0]) || insidePolygon(b, a[0][0])
+}
+
+// segmentsTouch reports if the segment p1 p2 touches or crosses the segment q1 q2
+func segmentsTouch(p1, p2, q1, q2 [2]float64) bool {
+	orient := func(a, b, c [2]float64) int {
+		v := (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+		switch {
+		case v > 0:
+			return 1
+		case v < 0:
+			return -1
+		}
+		return 0
+	}
+	// within reports if c, which is on the line of a b, is on the segment
+	within := func(a, b, c [2]float64) bool {
+		return math.Min(a[0], b[0]) <= c[0] && c[0] <= math.Max(a[0], b[0]) &&
+			math.Min(a[1], b[1]) <= c[1] && c[1] <= math.Max(a[1], b[1])
+	}
+
+	o1, o2 := orient(p1, p2, q1), orient(p1, p2, q2)
+	o3, o4 := orient(q1, q2, p1), orient(q1, q2, p2)
+	switch {
+	case o1 != o2 && o3 != o4:
+		return true
+	case o1 == 0 && within(p1, p2, q1),
+		o2 == 0 && within(p1, p2, q2),
+		o3 == 0 && within(q1, q2, p1),
+		o4 == 0 && within(q1, q2, p2):
+		return true
+	}
+	return false
+}
+
+// insidePolygon reports if the point is inside the polygon and not in its holes,
+// using the even-odd rule
+func insidePolygon(ply [][][2]float64, pt [2]float64) bool {
+	var inside bool
+	for _, ring := range ply {
+		for i := range ring {
+			a, b := ring[i], ring[(i+1)%len(ring)]
+			if (a[1] > pt[1]) != (b[1] > pt[1]) &&
+				pt[0] < a[0]+(pt[1]-a[1])*(b[0]-a[0])/(b[1]-a[1]) {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// coalesceGeometries merges geometries of the same kind into a single geometry.
+// Lines are expected to share end points and polygons to touch.
+func coalesceGeometries(ctx context.Context, geos []geom.Geometry) (geom.Geometry, error) {
+	var (
+		pts   geom.MultiPoint
+		lines [][][2]float64
+		plys  geom.MultiPolygon
+	)
+	for _, geo := range geos {
+		switch g := geo.(type) {
+		case geom.Point:
+			pts = append(pts, g)
+		case geom.MultiPoint:
+			pts = append(pts, g...)
+		case geom.LineString, geom.MultiLineString:
+			lines = append(lines, lineStrings(g)...)
+		case geom.Polygon, geom.MultiPolygon, *geom.MultiPolygon:
+			plys = append(plys, polygons(g)...)
+		}
+	}
+
+	switch {
+	case len(pts) > 0:
+		return pts, nil
+	case len(plys) > 0:
+		union, err := unionPolygons(ctx, plys)
+		if err != nil || len(union) != 1 {
+			return union, err
+		}
+		return geom.Polygon(union[0]), nil
+	}
+
+	lines = mergeLines(lines)
+	if len(lines) == 1 {
+		return geom.LineString(lines[0]), nil
+	}
+	return geom.MultiLineString(lines), nil
+}
+
+// unionPolygons returns the union of the polygons. A point is inside the union if it
+// is inside any of the polygons.
+func unionPolygons(ctx context.Context, plys geom.MultiPolygon) (geom.MultiPolygon, error) {
+	hms := make([]planar.HitMapper, len(plys))
+	for i := range plys {
+		hm, err := hitmap.NewFromPolygons(nil, plys[i])
+		if err != nil {
+			return nil, err
+		}
+		hms[i] = hm
+	}
+
+	triangles, err := makevalid.InsideTrianglesForMultiPolygon(ctx, nil, &plys, hitmap.NewOrderedHM(hms...))
+	if err != nil {
+		return nil, err
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	return walker.New(triangles).MultiPolygon(ctx), nil
+}
+
+// mergeLines joins lines that share an end point.
+func mergeLines(lines [][][2]float64) [][][2]float64 {
+	reverse := func(ls [][2]float64) [][2]float64 {
+		r := make([][2]float64, len(ls))
+		for i := range ls {
+			r[len(ls)-1-i] = ls[i]
+		}
+		return r
+	}
+
+	merged := make([][][2]float64, 0, len(lines))
+	for _, ls := range lines {
+		if len(ls) > 0 {
+			merged = append(merged, append([][2]float64(nil), ls...))
+		}
+	}
+
+	for joined := true; joined; {
+		joined = false
+		for i := 0; i < len(merged); i++ {
+			for j := i + 1; j < len(merged); j++ {
+				a, b := merged[i], merged[j]
+				var ls [][2]float64
+				switch {
+				case a[len(a)-1] == b[0]:
+					ls = append(a, b[1:]...)
+				case b[len(b)-1] == a[0]:
+					ls = append(b, a[1:]...)
+				case a[len(a)-1] == b[len(b)-1]:
+					ls = append(a, reverse(b)[1:]...)
+				case a[0] == b[0]:
+					ls = append(reverse(a), b[1:]...)
+				default:
+					continue
+				}
+				merged[i] = ls
+				merged = append(merged[:j], merged[j+1:]...)
+				joined = true
+				j = i
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,331 @@
+package mvt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hahaking119/geom"
+)
+
+func TestTileEncode(t *testing.T) {
+	ctx := context.Background()
+
+	newTile := func(features ...Feature) *Tile {
+		layer := &Layer{Name: "layer"}
+		layer.AddFeatures(features...)
+		tile := new(Tile)
+		if err := tile.AddLayers(layer); err != nil {
+			t.Fatal(err)
+		}
+		return tile
+	}
+	encodedSize := func(features ...Feature) int {
+		b, _, err := newTile(features...).Encode(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(b)
+	}
+	tags := func(v string) map[string]interface{} {
+		return map[string]interface{}{"name": v}
+	}
+
+	t.Run("under budget", func(t *testing.T) {
+		tile := newTile(Feature{Geometry: geom.Point{1, 1}, Tags: tags("a")})
+		tile.MaxSize = DefaultMaxSize
+		b, report, err := tile.Encode(ctx)
+		if err != nil {
+			t.Fatalf("error, expected nil got %v", err)
+		}
+		if report.Size != len(b) || report.Tolerance != 0 || len(report.Dropped) != 0 || len(report.Coalesced) != 0 {
+			t.Errorf("report, expected nothing done got %+v", report)
+		}
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		features := []Feature{
+			{Geometry: geom.LineString{{0, 0}, {10, 0}}, Tags: tags("a")},
+			{Geometry: geom.LineString{{10, 0}, {20, 0}}, Tags: tags("a")},
+			{Geometry: geom.LineString{{30, 5}, {20, 0}}, Tags: tags("a")},
+			{Geometry: geom.LineString{{0, 10}, {10, 10}}, Tags: tags("b")},
+		}
+		tile := newTile(features...)
+		tile.MaxSize = encodedSize(features...) - 1
+
+		b, report, err := tile.Encode(ctx)
+		if err != nil {
+			t.Fatalf("error, expected nil got %v", err)
+		}
+		if report.Coalesced["layer"] != 2 || report.Tolerance != 0 || len(report.Dropped) != 0 {
+			t.Errorf("report, expected 2 coalesced got %+v", report)
+		}
+		decoded, err := Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs := decoded.Layers()[0].Features()
+		if len(fs) != 2 {
+			t.Fatalf("features, expected 2 got %v", len(fs))
+		}
+		if ls, ok := fs[0].Geometry.(geom.LineString); !ok || len(ls) != 4 {
+			t.Errorf("geometry, expected merged line of 4 points got %v", fs[0].Geometry)
+		}
+	})
+
+	t.Run("simplify", func(t *testing.T) {
+		var wiggle geom.LineString
+		for i := 0; i <= 100; i++ {
+			wiggle = append(wiggle, [2]float64{float64(i * 10), float64(i % 2)})
+		}
+		tile := newTile(Feature{Geometry: wiggle, Tags: tags("a")})
+		tile.MaxSize = encodedSize(Feature{Geometry: geom.LineString{{0, 0}, {1000, 0}}, Tags: tags("a")}) + 10
+
+		_, report, err := tile.Encode(ctx)
+		if err != nil {
+			t.Fatalf("error, expected nil got %v", err)
+		}
+		if report.Tolerance == 0 || len(report.Dropped) != 0 {
+			t.Errorf("report, expected simplification only got %+v", report)
+		}
+	})
+
+	t.Run("drop smallest", func(t *testing.T) {
+		square := func(size float64) geom.Polygon {
+			return geom.Polygon{{{0, 0}, {size, 0}, {size, size}, {0, size}}}
+		}
+		big := Feature{Geometry: square(100), Tags: tags("big")}
+		tile := newTile(
+			Feature{Geometry: square(10), Tags: tags("small")},
+			big,
+			Feature{Geometry: geom.Point{5, 5}, Tags: tags("point")},
+		)
+		tile.MaxSize = encodedSize(big)
+
+		b, report, err := tile.Encode(ctx)
+		if err != nil {
+			t.Fatalf("error, expected nil got %v", err)
+		}
+		if len(b) > tile.MaxSize {
+			t.Errorf("size, expected at most %v got %v", tile.MaxSize, len(b))
+		}
+		dropped := report.Dropped["layer"]
+		if len(dropped) != 2 {
+			t.Fatalf("dropped, expected 2 got %v", dropped)
+		}
+		for _, f := range dropped {
+			if f.Tags["name"] == "big" {
+				t.Errorf("dropped, expected the big polygon to be kept")
+			}
+		}
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		tile := newTile(Feature{Geometry: geom.Point{1, 1}, Tags: tags("a")})
+		tile.MaxSize = 1
+		if _, _, err := tile.Encode(ctx); err != ErrOverBudget {
+			t.Errorf("error, expected %v got %v", ErrOverBudget, err)
+		}
+	})
+}
+
+func TestCoalesceFeatures(t *testing.T) {
+	type tcase struct {
+		tags []map[string]interface{}
+		opts ValueOptions
+		// expected is the number of features merged
+		expected int
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			features := make([]Feature, len(tc.tags))
+			for i := range tc.tags {
+				features[i] = Feature{Geometry: geom.Point{float64(i), 0}, Tags: tc.tags[i]}
+			}
+			got, count, err := coalesceFeatures(context.Background(), features, tc.opts)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if count != tc.expected || len(got) != len(features)-tc.expected {
+				t.Errorf("coalesced, expected %v got %v of %v features", tc.expected, count, len(got))
+			}
+		}
+	}
+
+	a, b := "a", "a"
+	one, otherOne := 1.0, 1.0
+
+	tests := map[string]tcase{
+		"equal": {
+			tags:     []map[string]interface{}{{"k": "a", "n": 1}, {"n": 1, "k": "a"}},
+			expected: 1,
+		},
+		"pointers to equal values": {
+			tags:     []map[string]interface{}{{"k": &a, "n": &one}, {"k": &b, "n": &otherOne}},
+			expected: 1,
+		},
+		"pointer and value": {
+			tags:     []map[string]interface{}{{"k": &a}, {"k": "a"}},
+			expected: 1,
+		},
+		"different value types": {
+			tags:     []map[string]interface{}{{"k": "1"}, {"k": 1}, {"k": 1.0}, {"k": uint(1)}, {"k": true}},
+			expected: 0,
+		},
+		"printed alike": {
+			tags:     []map[string]interface{}{{"k": `a" "b`}, {"k": "a", "b": ""}},
+			expected: 0,
+		},
+		"nil skipped": {
+			tags:     []map[string]interface{}{{"k": "a", "n": nil}, {"k": "a"}},
+			expected: 1,
+		},
+		"nil as string": {
+			tags:     []map[string]interface{}{{"k": "a", "n": nil}, {"k": "a"}},
+			opts:     ValueOptions{Nil: NilNullString},
+			expected: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestCoalesceGeometries(t *testing.T) {
+	type tcase struct {
+		geoms []geom.Geometry
+		// expected are the coalesced geometries, compared by type and size
+		expected []geom.Geometry
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			features := make([]Feature, len(tc.geoms))
+			for i := range tc.geoms {
+				features[i] = Feature{Geometry: tc.geoms[i], Tags: map[string]interface{}{"k": "a"}}
+			}
+			got, count, err := coalesceFeatures(context.Background(), features, ValueOptions{})
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if count != len(features)-len(got) {
+				t.Errorf("coalesced, expected %v got %v", len(features)-len(got), count)
+			}
+			if len(got) != len(tc.expected) {
+				t.Fatalf("features, expected %v got %v", len(tc.expected), len(got))
+			}
+			for i := range tc.expected {
+				if fmt.Sprintf("%T", got[i].Geometry) != fmt.Sprintf("%T", tc.expected[i]) {
+					t.Errorf("geometry %v, expected %T got %T", i, tc.expected[i], got[i].Geometry)
+				}
+				if size, expected := featureSize(got[i].Geometry), featureSize(tc.expected[i]); size != expected {
+					t.Errorf("geometry %v size, expected %v got %v", i, expected, size)
+				}
+			}
+		}
+	}
+
+	square := func(x, y, size float64) geom.Polygon {
+		return geom.Polygon{{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}}
+	}
+
+	tests := map[string]tcase{
+		"points": {
+			geoms:    []geom.Geometry{geom.Point{0, 0}, geom.MultiPoint{{5, 5}, {6, 6}}},
+			expected: []geom.Geometry{geom.MultiPoint{{0, 0}, {5, 5}, {6, 6}}},
+		},
+		"disjoint lines": {
+			geoms:    []geom.Geometry{geom.LineString{{0, 0}, {10, 0}}, geom.LineString{{0, 10}, {10, 10}}},
+			expected: []geom.Geometry{geom.LineString{{0, 0}, {10, 0}}, geom.LineString{{0, 10}, {10, 10}}},
+		},
+		"crossing lines": {
+			geoms:    []geom.Geometry{geom.LineString{{0, 0}, {10, 10}}, geom.LineString{{0, 10}, {10, 0}}},
+			expected: []geom.Geometry{geom.LineString{{0, 0}, {10, 10}}, geom.LineString{{0, 10}, {10, 0}}},
+		},
+		"connected lines": {
+			geoms: []geom.Geometry{
+				geom.LineString{{0, 0}, {10, 0}},
+				geom.LineString{{20, 0}, {30, 0}},
+				geom.LineString{{20, 0}, {10, 0}},
+				geom.LineString{{0, 10}, {10, 10}},
+			},
+			expected: []geom.Geometry{geom.LineString{{0, 0}, {30, 0}}, geom.LineString{{0, 10}, {10, 10}}},
+		},
+		"disjoint polygons": {
+			geoms:    []geom.Geometry{square(0, 0, 10), square(20, 0, 10)},
+			expected: []geom.Geometry{square(0, 0, 10), square(20, 0, 10)},
+		},
+		"overlapping polygons": {
+			geoms:    []geom.Geometry{square(0, 0, 10), square(5, 5, 10)},
+			expected: []geom.Geometry{geom.Polygon{{{0, 0}, {10, 0}, {10, 5}, {15, 5}, {15, 15}, {5, 15}, {5, 10}, {0, 10}}}},
+		},
+		"polygons sharing an edge": {
+			geoms:    []geom.Geometry{square(0, 0, 10), square(10, 0, 10)},
+			expected: []geom.Geometry{geom.Polygon{{{0, 0}, {20, 0}, {20, 10}, {0, 10}}}},
+		},
+		"polygons touching at a corner": {
+			geoms:    []geom.Geometry{square(0, 0, 10), square(10, 10, 10)},
+			expected: []geom.Geometry{geom.MultiPolygon{square(0, 0, 10), square(10, 10, 10)}},
+		},
+		"polygon inside another": {
+			geoms:    []geom.Geometry{square(0, 0, 10), square(2, 2, 2)},
+			expected: []geom.Geometry{square(0, 0, 10)},
+		},
+		"polygon in a hole": {
+			geoms: []geom.Geometry{
+				geom.Polygon{square(0, 0, 10)[0], square(2, 2, 6)[0]},
+				square(4, 4, 2),
+			},
+			expected: []geom.Geometry{
+				geom.Polygon{square(0, 0, 10)[0], square(2, 2, 6)[0]},
+				square(4, 4, 2),
+			},
+		},
+		"polygon filling part of a hole": {
+			geoms: []geom.Geometry{
+				geom.Polygon{square(0, 0, 10)[0], square(2, 2, 6)[0]},
+				geom.Polygon{{{1, 1}, {5, 1}, {5, 9}, {1, 9}}},
+			},
+			expected: []geom.Geometry{
+				geom.Polygon{square(0, 0, 10)[0], {{5, 2}, {8, 2}, {8, 8}, {5, 8}}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestFeatureSize(t *testing.T) {
+	type tcase struct {
+		geo      geom.Geometry
+		expected float64
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			if got := featureSize(tc.geo); got != tc.expected {
+				t.Errorf("size, expected %v got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point":          {geo: geom.Point{1, 1}, expected: 1},
+		"multi point":    {geo: geom.MultiPoint{{1, 1}, {2, 2}}, expected: 2},
+		"line":           {geo: geom.LineString{{0, 0}, {3, 4}}, expected: 5},
+		"multi line":     {geo: geom.MultiLineString{{{0, 0}, {3, 4}}, {{0, 0}, {0, 1}}}, expected: 6},
+		"polygon":        {geo: geom.Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}}, expected: 4},
+		"polygon hole":   {geo: geom.Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}, {{1, 1}, {2, 1}, {2, 2}, {1, 2}}}, expected: 15},
+		"nil multi poly": {geo: (*geom.MultiPolygon)(nil), expected: 0},
+		"empty polygon":  {geo: geom.Polygon{}, expected: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -0,0 +1,260 @@
+package mvt
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/planar"
+	"github.com/hahaking119/geom/planar/clip"
+	"github.com/hahaking119/geom/planar/makevalid"
+	"github.com/hahaking119/geom/planar/simplify"
+	"github.com/hahaking119/geom/slippy"
+	"github.com/hahaking119/geom/winding"
+)
+
+// DefaultBuffer is the number of pixels, of a DefaultExtent sized tile, commonly kept
+// around a tile so lines and polygon edges render correctly across tile boundaries.
+const DefaultBuffer = 64
+
+// DefaultSimplifyTolerance is the tolerance, in pixels, commonly used to simplify geometries.
+const DefaultSimplifyTolerance = 1.0
+
+// SourceLayer is a layer of features whose geometries are in the grid's coordinate
+// system, along with the options of how the features are turned into a tile layer.
+type SourceLayer struct {
+	// Name is the unique name of the layer within the tile
+	Name string
+	// Features are the features of the layer, in the grid's coordinate system.
+	// Collections are split into one feature per geometry; as IDs must be unique
+	// within a layer, the split features have no ID.
+	Features []Feature
+
+	// Extent is the dimension of the layer in pixels, defaults to DefaultExtent
+	Extent uint32
+	// Buffer is the number of pixels around the tile features are kept for
+	Buffer uint
+	// SimplifyTolerance is the tolerance, in pixels, used to simplify the geometries
+	// with Douglas-Peucker; 0 disables simplification.
+	SimplifyTolerance float64
+	// SimplifyMaxZoom is the last zoom that geometries are simplified at; 0 means
+	// all zooms are simplified.
+	SimplifyMaxZoom uint
+	// DontClip disables clipping the geometries to the buffered tile
+	DontClip bool
+	// DontMakeValid disables making polygons valid. As only makevalid is able to
+	// clip polygons, polygons will not be clipped either.
+	DontMakeValid bool
+	// Validation is how geometries that break the MVT 2.1 rules are handled
+	// when the tile is encoded, defaults to ValidationFix
+	Validation ValidationMode
+	// ValueOptions configures how the values of the features' tags are encoded
+	ValueOptions ValueOptions
+}
+
+// BuildTile builds a tile from the source layers. For each feature the geometry is simplified
+// for the tile's zoom, made valid and clipped to the buffered tile, then converted to the
+// tile's pixel coordinates; features with no geometry left are dropped.
+func BuildTile(ctx context.Context, g slippy.Grid, tile *slippy.Tile, layers ...SourceLayer) (*Tile, error) {
+	t := new(Tile)
+	for i := range layers {
+		l, err := layers[i].Build(ctx, g, tile)
+		if err != nil {
+			return nil, err
+		}
+		if err = t.AddLayers(l); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Build returns the tile layer for the source layer. See BuildTile.
+func (sl SourceLayer) Build(ctx context.Context, g slippy.Grid, tile *slippy.Tile) (*Layer, error) {
+	if tile == nil {
+		return nil, fmt.Errorf("layer %v: tile is nil", sl.Name)
+	}
+
+	extent := sl.Extent
+	if extent == 0 {
+		extent = DefaultExtent
+	}
+	pixelSize, ok := slippy.Resolution(g, tile.Z, float64(extent))
+	if !ok {
+		return nil, fmt.Errorf("layer %v: zoom %v not valid for grid", sl.Name, tile.Z)
+	}
+	tileExt, ok := slippy.Extent(g, tile)
+	if !ok {
+		return nil, fmt.Errorf("layer %v: tile %v not valid for grid", sl.Name, tile)
+	}
+
+	var clipRegion *geom.Extent
+	var clipper planar.Clipper
+	if !sl.DontClip {
+		clipRegion = tileExt.ExpandBy(float64(sl.Buffer) * pixelSize)
+		clipper = clip.Default
+	}
+
+	var simplifier planar.Simplifer
+	if sl.SimplifyTolerance > 0 && (sl.SimplifyMaxZoom == 0 || tile.Z <= sl.SimplifyMaxZoom) {
+		simplifier = simplify.DouglasPeucker{
+			Tolerance: sl.SimplifyTolerance * pixelSize,
+		}
+	}
+
+	mv := makevalid.Makevalid{
+		Clipper: clipper,
+		Order:   winding.Order{},
+	}
+
+	layer := &Layer{
+		Name:         sl.Name,
+		Validation:   sl.Validation,
+		ValueOptions: sl.ValueOptions,
+	}
+	layer.SetExtent(int(extent))
+
+	for i := range sl.Features {
+		split := NewFeatures(sl.Features[i].Geometry, sl.Features[i].Tags)
+		for _, f := range split {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			geo, err := planar.Simplify(ctx, simplifier, f.Geometry)
+			if err != nil {
+				return nil, fmt.Errorf("layer %v feature %v: simplify: %v", sl.Name, i, err)
+			}
+
+			switch geo.(type) {
+			case geom.Polygoner, geom.MultiPolygoner:
+				if !sl.DontMakeValid {
+					geo, _, err = mv.Makevalid(ctx, geo, clipRegion)
+				}
+			default:
+				if clipper != nil {
+					geo, err = clipper.Clip(ctx, geo, clipRegion)
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("layer %v feature %v: %v", sl.Name, i, err)
+			}
+			if geo == nil {
+				continue
+			}
+
+			geo = quantize(PrepareGeo(geo, tileExt, float64(extent)))
+			if geo == nil {
+				continue
+			}
+
+			if len(split) == 1 {
+				f.ID = sl.Features[i].ID
+			}
+			f.Geometry = geo
+			layer.AddFeatures(f)
+		}
+	}
+
+	return layer, nil
+}
+
+// quantize rounds the pixel coordinates of the geometry to whole pixels, removing
+// repeated points. Parts with too few points left are dropped, nil is returned if
+// nothing is left.
+func quantize(geo geom.Geometry) geom.Geometry {
+	round := func(pt [2]float64) [2]float64 {
+		return [2]float64{math.Round(pt[0]), math.Round(pt[1])}
+	}
+	line := func(pts [][2]float64, min int) [][2]float64 {
+		ls := make([][2]float64, 0, len(pts))
+		for _, pt := range pts {
+			pt = round(pt)
+			if len(ls) > 0 && ls[len(ls)-1] == pt {
+				continue
+			}
+			ls = append(ls, pt)
+		}
+		// rings are closed by the encoder, so drop a repeated first point
+		if min == 3 && len(ls) > 1 && ls[0] == ls[len(ls)-1] {
+			ls = ls[:len(ls)-1]
+		}
+		if len(ls) < min {
+			return nil
+		}
+		return ls
+	}
+	polygon := func(rings [][][2]float64) [][][2]float64 {
+		var ply [][][2]float64
+		for i, ring := range rings {
+			r := line(ring, 3)
+			if r == nil {
+				if i == 0 {
+					// without the exterior ring there is no polygon
+					return nil
+				}
+				continue
+			}
+			ply = append(ply, r)
+		}
+		// rounding can flip the winding order of small rings
+		if ply = tileOrder.RectifyPolygon(ply); len(ply) == 0 {
+			return nil
+		}
+		return ply
+	}
+
+	switch g := geo.(type) {
+	case geom.Point:
+		return geom.Point(round(g))
+
+	case geom.MultiPoint:
+		if len(g) == 0 {
+			return nil
+		}
+		mp := make(geom.MultiPoint, len(g))
+		for i := range g {
+			mp[i] = round(g[i])
+		}
+		return mp
+
+	case geom.LineString:
+		if ls := line(g, 2); ls != nil {
+			return geom.LineString(ls)
+		}
+
+	case geom.MultiLineString:
+		var mls geom.MultiLineString
+		for _, l := range g {
+			if ls := line(l, 2); ls != nil {
+				mls = append(mls, ls)
+			}
+		}
+		if len(mls) > 0 {
+			return mls
+		}
+
+	case geom.Polygon:
+		if ply := polygon(g); ply != nil {
+			return geom.Polygon(ply)
+		}
+
+	case geom.MultiPolygon:
+		var mp geom.MultiPolygon
+		for _, p := range g {
+			if ply := polygon(p); ply != nil {
+				mp = append(mp, ply)
+			}
+		}
+		if len(mp) > 0 {
+			return mp
+		}
+
+	case *geom.MultiPolygon:
+		if g != nil {
+			return quantize(*g)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package mvt_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/cmp"
+	"github.com/hahaking119/geom/encoding/mvt"
+	"github.com/hahaking119/geom/slippy"
+)
+
+func TestBuildTile(t *testing.T) {
+	type tcase struct {
+		layer    mvt.SourceLayer
+		expected []geom.Geometry
+		// ids are the expected feature IDs, not checked if nil
+		ids []*uint64
+	}
+
+	grid, err := slippy.NewGrid(3857)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tile := slippy.NewTile(0, 0, 0)
+	res, _ := slippy.Resolution(grid, 0, float64(mvt.DefaultExtent))
+
+	// px returns the native point of the pixel of the tile
+	px := func(x, y float64) [2]float64 {
+		return [2]float64{-slippy.WebMercatorMax + x*res, slippy.WebMercatorMax - y*res}
+	}
+	features := func(geos ...geom.Geometry) (fs []mvt.Feature) {
+		for _, g := range geos {
+			fs = append(fs, mvt.Feature{Geometry: g})
+		}
+		return fs
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			mvtTile, err := mvt.BuildTile(context.Background(), grid, tile, tc.layer)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			layers := mvtTile.Layers()
+			if len(layers) != 1 {
+				t.Fatalf("layers, expected 1 got %v", len(layers))
+			}
+			fs := layers[0].Features()
+			if len(fs) != len(tc.expected) {
+				t.Fatalf("features, expected %v got %v", len(tc.expected), fs)
+			}
+			for i := range fs {
+				if !cmp.GeometryEqual(fs[i].Geometry, tc.expected[i]) {
+					t.Errorf("feature %v, expected %v got %v", i, tc.expected[i], fs[i].Geometry)
+				}
+			}
+			for i := range tc.ids {
+				if (fs[i].ID == nil) != (tc.ids[i] == nil) || (fs[i].ID != nil && *fs[i].ID != *tc.ids[i]) {
+					t.Errorf("feature %v id, expected %v got %v", i, tc.ids[i], fs[i].ID)
+				}
+			}
+			if _, err = mvtTile.VTile(context.Background()); err != nil {
+				t.Errorf("vtile error, expected nil got %v", err)
+			}
+		}
+	}
+
+	id := func(i uint64) *uint64 { return &i }
+
+	tests := map[string]tcase{
+		"ids": {
+			layer: mvt.SourceLayer{
+				Name: "points",
+				Features: []mvt.Feature{
+					{ID: id(1), Geometry: geom.Point(px(1, 2))},
+					{ID: id(2), Geometry: geom.Collection{geom.Point(px(3, 4)), geom.Point(px(5, 6))}},
+					{ID: id(3), Geometry: geom.Collection{geom.Point(px(7, 8))}},
+					{Geometry: geom.Point(px(9, 10))},
+				},
+			},
+			expected: []geom.Geometry{
+				geom.Point{1, 2},
+				geom.Point{3, 4},
+				geom.Point{5, 6},
+				geom.Point{7, 8},
+				geom.Point{9, 10},
+			},
+			ids: []*uint64{id(1), nil, nil, id(3), nil},
+		},
+		"points": {
+			layer: mvt.SourceLayer{
+				Name:   "points",
+				Buffer: mvt.DefaultBuffer,
+				Features: features(
+					geom.Point(px(100.4, 200.6)),
+					geom.Point(px(-100, 10)),
+					geom.Point(px(-30, 10)),
+					geom.Collection{geom.Point(px(1, 2)), geom.Point(px(3, 4))},
+				),
+			},
+			expected: []geom.Geometry{
+				geom.Point{100, 201},
+				geom.Point{-30, 10},
+				geom.Point{1, 2},
+				geom.Point{3, 4},
+			},
+		},
+		"clipped line": {
+			layer: mvt.SourceLayer{
+				Name:     "lines",
+				Buffer:   mvt.DefaultBuffer,
+				Features: features(geom.LineString{px(10, 10), px(5000, 10)}),
+			},
+			expected: []geom.Geometry{
+				geom.MultiLineString{{{10, 10}, {4160, 10}}},
+			},
+		},
+		"unclipped line": {
+			layer: mvt.SourceLayer{
+				Name:     "lines",
+				DontClip: true,
+				Features: features(geom.LineString{px(10, 10), px(5000, 10)}),
+			},
+			expected: []geom.Geometry{
+				geom.LineString{{10, 10}, {5000, 10}},
+			},
+		},
+		"simplified line": {
+			layer: mvt.SourceLayer{
+				Name:              "lines",
+				SimplifyTolerance: mvt.DefaultSimplifyTolerance,
+				Features:          features(geom.LineString{px(0, 500), px(100, 500.8), px(200, 500)}),
+			},
+			expected: []geom.Geometry{
+				geom.MultiLineString{{{0, 500}, {200, 500}}},
+			},
+		},
+		"not simplified": {
+			layer: mvt.SourceLayer{
+				Name:     "lines",
+				DontClip: true,
+				Features: features(geom.LineString{px(0, 500), px(100, 500.8), px(200, 500)}),
+			},
+			expected: []geom.Geometry{
+				geom.LineString{{0, 500}, {100, 501}, {200, 500}},
+			},
+		},
+		"polygons": {
+			layer: mvt.SourceLayer{
+				Name:              "polygons",
+				Buffer:            mvt.DefaultBuffer,
+				Validation:        mvt.ValidationStrict,
+				SimplifyTolerance: mvt.DefaultSimplifyTolerance,
+				Features: features(
+					geom.Polygon{{px(10, 10), px(110, 10), px(110, 110), px(10, 110)}},
+					geom.Polygon{{px(1, 1), px(1.2, 1), px(1.2, 1.2)}},
+				),
+			},
+			expected: []geom.Geometry{
+				geom.MultiPolygon{{{{10, 10}, {110, 10}, {110, 110}, {10, 110}}}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -17,11 +17,6 @@ var (
 	ErrNilGeometryType     = fmt.Errorf("geometry is nil")
 )
 
-// TODO: Need to put in validation for the Geometry, as current the system
-// does not check to make sure that the geometry is following the rules as
-// laid out by the spec (i.e. polygons must not have the same start and end
-// point).
-
 // Feature describes a feature of a Layer. A layer will contain multiple features
 // each of which has a geometry describing the interesting thing, and the metadata
 // associated with it.
@@ -66,8 +61,15 @@ func NewFeatures(geo geom.Geometry, tags map[string]interface{}) (f []Feature) {
 	return f
 }
 
-// VTileFeature will return a vectorTile.Feature that would represent the Feature
+// VTileFeature will return a vectorTile.Feature that would represent the Feature.
+// Geometries that break the MVT 2.1 geometry rules are fixed, see ValidationFix; nil
+// is returned if nothing is left of the geometry.
 func (f *Feature) VTileFeature(ctx context.Context, keys []string, vals []interface{}) (tf *vectorTile.Tile_Feature, err error) {
+	return f.VTileFeatureWithValidation(ctx, keys, vals, ValidationFix)
+}
+
+// VTileFeatureWithValidation is VTileFeature with the given validation mode.
+func (f *Feature) VTileFeatureWithValidation(ctx context.Context, keys []string, vals []interface{}, mode ValidationMode) (tf *vectorTile.Tile_Feature, err error) {
 	tf = new(vectorTile.Tile_Feature)
 	tf.Id = f.ID
 
@@ -75,7 +77,15 @@ func (f *Feature) VTileFeature(ctx context.Context, keys []string, vals []interf
 		return tf, err
 	}
 
-	geo, gtype, err := encodeGeometry(ctx, f.Geometry)
+	vgeo, err := validateGeometry(f.Geometry, mode)
+	if err != nil {
+		return tf, err
+	}
+	if vgeo == nil {
+		return nil, nil
+	}
+
+	geo, gtype, err := encodeGeometry(ctx, vgeo)
 	if err != nil {
 		return tf, err
 	}
@@ -393,6 +403,14 @@ func keyvalMapsFromFeatures(features []Feature) (keyMap []string, valMap []inter
 					}
 				}
 
+			case intValue, uintValue, sintValue:
+				for _, mv := range valMap {
+					if mv == vt {
+						didFind = true
+						break
+					}
+				}
+
 			case fmt.Stringer:
 				for _, mv := range valMap {
 					tmv, ok := mv.(fmt.Stringer)
@@ -615,6 +633,10 @@ func keyvalTagsMap(keyMap []string, valueMap []interface{}, f *Feature) (tags []
 				if !ok || vmt != tv { // and that the values match
 					continue // if they don't match move to the next value.
 				}
+			case intValue, uintValue, sintValue:
+				if v != tv {
+					continue
+				}
 			case fmt.Stringer:
 				vmt, ok := v.(fmt.Stringer)
 				if !ok || vmt.String() != tv.String() {
@@ -0,0 +1,128 @@
+package mvt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hahaking119/geom"
+)
+
+// JoinOptions configures how tiles are joined
+type JoinOptions struct {
+	// Extent is the extent of the joined layers. If 0, the largest extent of
+	// the layers with the same name is used.
+	Extent uint32
+	// Layer, if set, is called with the name of each layer; the layer is left
+	// out of the joined tile if it returns false.
+	Layer func(name string) bool
+	// Tag, if set, is called with each tag of the features of the kept layers;
+	// the tag is left out of the feature if it returns false.
+	Tag func(layer, key string, value interface{}) bool
+}
+
+// Join merges the tiles, which should be for the same z/x/y, into one tile. Layers with the
+// same name are merged into one layer, in the order the tiles are given, taking the options
+// of the first of the layers. Layers with an extent different from the joined layer's extent
+// have their geometries rescaled to it. The key and value tables of the layers are rebuilt,
+// without duplicates, when the joined tile is encoded.
+func Join(ctx context.Context, opts JoinOptions, tiles ...*Tile) (*Tile, error) {
+	var (
+		names  []string
+		layers = make(map[string][]Layer)
+	)
+	for _, t := range tiles {
+		if t == nil {
+			continue
+		}
+		for _, l := range t.layers {
+			if opts.Layer != nil && !opts.Layer(l.Name) {
+				continue
+			}
+			if _, ok := layers[l.Name]; !ok {
+				names = append(names, l.Name)
+			}
+			layers[l.Name] = append(layers[l.Name], l)
+		}
+	}
+
+	joined := new(Tile)
+	for _, name := range names {
+		layer, err := joinLayers(ctx, opts, layers[name])
+		if err != nil {
+			return nil, err
+		}
+		if err = joined.AddLayers(layer); err != nil {
+			return nil, err
+		}
+	}
+	return joined, nil
+}
+
+// JoinBytes decodes the MVT encoded tiles and joins them. See Join.
+func JoinBytes(ctx context.Context, opts JoinOptions, tiles ...[]byte) (*Tile, error) {
+	decoded := make([]*Tile, len(tiles))
+	for i := range tiles {
+		t, err := DecodeByte(tiles[i])
+		if err != nil {
+			return nil, fmt.Errorf("tile %v: %v", i, err)
+		}
+		decoded[i] = t
+	}
+	return Join(ctx, opts, decoded...)
+}
+
+// joinLayers merges the layers, which all have the same name, into one layer
+func joinLayers(ctx context.Context, opts JoinOptions, layers []Layer) (*Layer, error) {
+	extent := int(opts.Extent)
+	if extent == 0 {
+		for i := range layers {
+			if e := layers[i].Extent(); e > extent {
+				extent = e
+			}
+		}
+	}
+
+	layer := layers[0]
+	layer.features = nil
+	layer.SetExtent(extent)
+
+	for i := range layers {
+		scale := float64(extent) / float64(layers[i].Extent())
+		for j, f := range layers[i].features {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			if opts.Tag != nil && len(f.Tags) > 0 {
+				tags := make(map[string]interface{}, len(f.Tags))
+				for k, v := range f.Tags {
+					if opts.Tag(layer.Name, k, v) {
+						tags[k] = v
+					}
+				}
+				f.Tags = tags
+			}
+
+			if scale != 1 && f.Geometry != nil {
+				geo := f.Geometry
+				if mp, ok := geo.(*geom.MultiPolygon); ok && mp != nil {
+					geo = *mp
+				}
+				geo, err := geom.ApplyToPoints(geo, func(coords ...float64) ([]float64, error) {
+					return []float64{coords[0] * scale, coords[1] * scale}, nil
+				})
+				if err != nil {
+					return nil, fmt.Errorf("layer %v feature %v: %v", layer.Name, j, err)
+				}
+				// features shrunk to nothing are dropped
+				if geo = quantize(geo); geo == nil {
+					continue
+				}
+				f.Geometry = geo
+			}
+
+			layer.features = append(layer.features, f)
+		}
+	}
+	return &layer, nil
+}
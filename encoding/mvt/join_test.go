@@ -0,0 +1,155 @@
+package mvt_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/cmp"
+	"github.com/hahaking119/geom/encoding/mvt"
+)
+
+func TestJoinBytes(t *testing.T) {
+	type tcase struct {
+		opts mvt.JoinOptions
+		// expected are the geometries of each joined layer, by name
+		expected map[string][]geom.Geometry
+		// keys are the keys expected in the key table of each joined layer
+		keys    map[string][]string
+		extents map[string]uint32
+	}
+
+	// encode returns the encoded tile of the layers
+	encode := func(t *testing.T, layers ...*mvt.Layer) []byte {
+		tile := new(mvt.Tile)
+		if err := tile.AddLayers(layers...); err != nil {
+			t.Fatal(err)
+		}
+		vt, err := tile.VTile(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := proto.Marshal(vt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	layer := func(name string, extent int, geos ...geom.Geometry) *mvt.Layer {
+		l := &mvt.Layer{Name: name}
+		l.SetExtent(extent)
+		for _, g := range geos {
+			l.AddFeatures(mvt.Feature{
+				Tags:     map[string]interface{}{"name": name, "secret": "x"},
+				Geometry: g,
+			})
+		}
+		return l
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			a := encode(t,
+				layer("roads", 4096, geom.LineString{{0, 0}, {100, 100}}),
+				layer("water", 4096, geom.Point{10, 10}),
+			)
+			b := encode(t,
+				layer("roads", 2048, geom.Point{100, 100}),
+				layer("pois", 4096, geom.Point{20, 20}),
+			)
+
+			tile, err := mvt.JoinBytes(context.Background(), tc.opts, a, b)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			vt, err := tile.VTile(context.Background())
+			if err != nil {
+				t.Fatalf("vtile error, expected nil got %v", err)
+			}
+
+			layers := tile.Layers()
+			if len(layers) != len(tc.expected) {
+				t.Fatalf("layers, expected %v got %v", len(tc.expected), len(layers))
+			}
+			for i, l := range layers {
+				expected, ok := tc.expected[l.Name]
+				if !ok {
+					t.Errorf("layer %v, not expected", l.Name)
+					continue
+				}
+				fs := l.Features()
+				if len(fs) != len(expected) {
+					t.Errorf("layer %v features, expected %v got %v", l.Name, len(expected), len(fs))
+					continue
+				}
+				for j := range fs {
+					if !cmp.GeometryEqual(fs[j].Geometry, expected[j]) {
+						t.Errorf("layer %v feature %v, expected %v got %v", l.Name, j, expected[j], fs[j].Geometry)
+					}
+				}
+				if vt.Layers[i].GetExtent() != tc.extents[l.Name] {
+					t.Errorf("layer %v extent, expected %v got %v", l.Name, tc.extents[l.Name], vt.Layers[i].GetExtent())
+				}
+				// the order of the keys follows the iteration of the tags
+				keys := append([]string(nil), vt.Layers[i].Keys...)
+				sort.Strings(keys)
+				if !reflect.DeepEqual(keys, tc.keys[l.Name]) {
+					t.Errorf("layer %v keys, expected %v got %v", l.Name, tc.keys[l.Name], keys)
+				}
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"all": {
+			expected: map[string][]geom.Geometry{
+				"roads": {geom.LineString{{0, 0}, {100, 100}}, geom.Point{200, 200}},
+				"water": {geom.Point{10, 10}},
+				"pois":  {geom.Point{20, 20}},
+			},
+			keys: map[string][]string{
+				"roads": {"name", "secret"},
+				"water": {"name", "secret"},
+				"pois":  {"name", "secret"},
+			},
+			extents: map[string]uint32{"roads": 4096, "water": 4096, "pois": 4096},
+		},
+		"extent": {
+			opts: mvt.JoinOptions{Extent: 2048},
+			expected: map[string][]geom.Geometry{
+				"roads": {geom.LineString{{0, 0}, {50, 50}}, geom.Point{100, 100}},
+				"water": {geom.Point{5, 5}},
+				"pois":  {geom.Point{10, 10}},
+			},
+			keys: map[string][]string{
+				"roads": {"name", "secret"},
+				"water": {"name", "secret"},
+				"pois":  {"name", "secret"},
+			},
+			extents: map[string]uint32{"roads": 2048, "water": 2048, "pois": 2048},
+		},
+		"filtered": {
+			opts: mvt.JoinOptions{
+				Layer: func(name string) bool { return name != "water" },
+				Tag:   func(_, key string, _ interface{}) bool { return key != "secret" },
+			},
+			expected: map[string][]geom.Geometry{
+				"roads": {geom.LineString{{0, 0}, {100, 100}}, geom.Point{200, 200}},
+				"pois":  {geom.Point{20, 20}},
+			},
+			keys: map[string][]string{
+				"roads": {"name"},
+				"pois":  {"name"},
+			},
+			extents: map[string]uint32{"roads": 4096, "pois": 4096},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -15,6 +15,11 @@ import (
 type Layer struct {
 	// Name is the unique name of the layer within the tile
 	Name string
+	// Validation is how geometries that break the MVT 2.1 rules are handled,
+	// defaults to ValidationFix
+	Validation ValidationMode
+	// ValueOptions configures how the values of the features' tags are encoded
+	ValueOptions ValueOptions
 	// The set of features
 	features []Feature
 	// default is 4096
@@ -31,21 +36,32 @@ func valMapToVTileValue(valMap []interface{}) (vt []*vectorTile.Tile_Value) {
 
 // VTileLayer returns a vectorTile Tile_Layer object that represents this layer.
 func (l *Layer) VTileLayer(ctx context.Context) (*vectorTile.Tile_Layer, error) {
-	kmap, vmap, err := keyvalMapsFromFeatures(l.features)
+	// convert the tag values to the values that are encoded
+	normalized := make([]Feature, len(l.features))
+	for i, f := range l.features {
+		tags, err := l.ValueOptions.normalizeTags(f.Tags)
+		if err != nil {
+			return nil, err
+		}
+		f.Tags = tags
+		normalized[i] = f
+	}
+
+	kmap, vmap, err := keyvalMapsFromFeatures(normalized)
 	if err != nil {
 		return nil, err
 	}
 
 	valmap := valMapToVTileValue(vmap)
 
-	var features = make([]*vectorTile.Tile_Feature, 0, len(l.features))
-	for _, f := range l.features {
+	var features = make([]*vectorTile.Tile_Feature, 0, len(normalized))
+	for _, f := range normalized {
 		// context check
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
 
-		vtf, err := f.VTileFeature(ctx, kmap, vmap)
+		vtf, err := f.VTileFeatureWithValidation(ctx, kmap, vmap, l.Validation)
 		if err != nil {
 			switch err {
 			case context.Canceled:
@@ -144,6 +160,18 @@ func vectorTileValue(i interface{}) *vectorTile.Tile_Value {
 	case string:
 		tv.StringValue = &t
 
+	case intValue:
+		intv := int64(t)
+		tv.IntValue = &intv
+
+	case uintValue:
+		uintv := uint64(t)
+		tv.UintValue = &uintv
+
+	case sintValue:
+		intv := int64(t)
+		tv.SintValue = &intv
+
 	case fmt.Stringer:
 		str := t.String()
 		tv.StringValue = &str
@@ -25,6 +25,13 @@ To encode:
 	5. Get the `protobuf` tile by calling `(*Tile).VTile`
 	6. Encode the `protobuf` into bytes with `proto.Marshal`
 
+BuildTile does all of the above, as well as simplifying, clipping and making
+the geometries valid, for layers of features in the grid's coordinate system.
+How each layer is processed is set on its `SourceLayer`.
+
+Overzoom generates the tile of a descendant from a decoded tile, for zooms
+the source data was not tiled for.
+
 For an example, check the use of this package in tegola/atlas/map.go (https://github.com/go-spatial/tegola/blob/master/atlas/map.go)
 */
 package mvt
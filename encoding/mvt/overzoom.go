@@ -0,0 +1,112 @@
+package mvt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/planar/clip"
+	"github.com/hahaking119/geom/planar/makevalid"
+	"github.com/hahaking119/geom/slippy"
+)
+
+// Overzoom returns the tile for child, a descendant of parentTile, from the decoded tile of
+// parentTile. The geometries of each layer are clipped to the part of the parent the child
+// covers, expanded by buffer pixels of the child, and scaled up to the layer's extent. Lines
+// and points are clipped with the clip package and polygons with makevalid. The layers keep
+// their options, and the features their tags and IDs; features with nothing left are dropped.
+func Overzoom(ctx context.Context, g slippy.Grid, parent *Tile, parentTile, child *slippy.Tile, buffer uint) (*Tile, error) {
+	if parent == nil || parentTile == nil || child == nil {
+		return nil, fmt.Errorf("parent, parent tile and child are required")
+	}
+	if child.Z <= parentTile.Z {
+		return nil, fmt.Errorf("tile %v is not a descendant of %v", child, parentTile)
+	}
+	pext, ok := slippy.Extent(g, parentTile)
+	if !ok {
+		return nil, fmt.Errorf("tile %v not valid for grid", parentTile)
+	}
+	cext, ok := slippy.Extent(g, child)
+	if !ok {
+		return nil, fmt.Errorf("tile %v not valid for grid", child)
+	}
+	if !pext.Contains(cext) {
+		return nil, fmt.Errorf("tile %v is not a descendant of %v", child, parentTile)
+	}
+
+	// the part of the parent covered by the child, as a fraction of the parent,
+	// with y positive down like the pixel coordinates.
+	minx := (cext.MinX() - pext.MinX()) / pext.XSpan()
+	maxx := (cext.MaxX() - pext.MinX()) / pext.XSpan()
+	miny := (pext.MaxY() - cext.MaxY()) / pext.YSpan()
+	maxy := (pext.MaxY() - cext.MinY()) / pext.YSpan()
+
+	tile := &Tile{MaxSize: parent.MaxSize}
+	for _, l := range parent.layers {
+		extent := float64(l.Extent())
+		sub := geom.NewExtent(
+			[2]float64{minx * extent, miny * extent},
+			[2]float64{maxx * extent, maxy * extent},
+		)
+		scalex, scaley := extent/sub.XSpan(), extent/sub.YSpan()
+		clipbox := sub.ExpandBy(float64(buffer) / scalex)
+
+		mv := makevalid.Makevalid{Clipper: clip.Default}
+
+		layer := l
+		layer.features = nil
+		for i, f := range l.features {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			geo := f.Geometry
+			if mp, ok := geo.(*geom.MultiPolygon); ok && mp != nil {
+				geo = *mp
+			}
+
+			var err error
+			switch geo.(type) {
+			case geom.Polygon, geom.MultiPolygon:
+				geo, _, err = mv.Makevalid(ctx, geo, clipbox)
+				if mp, ok := geo.(*geom.MultiPolygon); ok {
+					// a nil multi polygon is outside of the clipbox
+					if mp == nil {
+						geo = nil
+					} else {
+						geo = *mp
+					}
+				}
+			default:
+				geo, err = clip.Geometry(ctx, geo, clipbox)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("layer %v feature %v: %v", l.Name, i, err)
+			}
+			if geo == nil {
+				continue
+			}
+
+			geo, err = geom.ApplyToPoints(geo, func(coords ...float64) ([]float64, error) {
+				return []float64{
+					(coords[0] - sub.MinX()) * scalex,
+					(coords[1] - sub.MinY()) * scaley,
+				}, nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("layer %v feature %v: %v", l.Name, i, err)
+			}
+
+			if geo = quantize(geo); geo == nil {
+				continue
+			}
+			f.Geometry = geo
+			layer.features = append(layer.features, f)
+		}
+
+		if err := tile.AddLayers(&layer); err != nil {
+			return nil, err
+		}
+	}
+	return tile, nil
+}
@@ -0,0 +1,147 @@
+package mvt_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding/mvt"
+	"github.com/hahaking119/geom/slippy"
+)
+
+func TestOverzoom(t *testing.T) {
+	type tcase struct {
+		child    *slippy.Tile
+		buffer   uint
+		features []mvt.Feature
+		// expected is the extent of the geometry of each feature left
+		expected [][4]float64
+		err      bool
+	}
+
+	grid, err := slippy.NewGrid(3857)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentTile := slippy.NewTile(0, 0, 0)
+
+	id := uint64(7)
+	tags := map[string]interface{}{"name": "a"}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			parent := new(mvt.Tile)
+			layer := &mvt.Layer{Name: "test"}
+			layer.AddFeatures(tc.features...)
+			if err := parent.AddLayers(layer); err != nil {
+				t.Fatal(err)
+			}
+
+			tile, err := mvt.Overzoom(context.Background(), grid, parent, parentTile, tc.child, tc.buffer)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+
+			layers := tile.Layers()
+			if len(layers) != 1 || layers[0].Name != "test" {
+				t.Fatalf("layers, expected [test] got %v", layers)
+			}
+			fs := layers[0].Features()
+			if len(fs) != len(tc.expected) {
+				t.Fatalf("features, expected %v got %v", len(tc.expected), fs)
+			}
+			for i := range fs {
+				ext, err := geom.NewExtentFromGeometry(fs[i].Geometry)
+				if err != nil {
+					t.Fatalf("feature %v extent error, expected nil got %v", i, err)
+				}
+				if ext.Extent() != tc.expected[i] {
+					t.Errorf("feature %v extent, expected %v got %v", i, tc.expected[i], ext.Extent())
+				}
+				if fs[i].ID == nil || *fs[i].ID != id {
+					t.Errorf("feature %v id, expected %v got %v", i, id, fs[i].ID)
+				}
+				if !reflect.DeepEqual(fs[i].Tags, tags) {
+					t.Errorf("feature %v tags, expected %v got %v", i, tags, fs[i].Tags)
+				}
+			}
+			if _, err = tile.VTile(context.Background()); err != nil {
+				t.Errorf("vtile error, expected nil got %v", err)
+			}
+		}
+	}
+
+	feature := func(geo geom.Geometry) mvt.Feature {
+		return mvt.Feature{ID: &id, Tags: tags, Geometry: geo}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			child:    slippy.NewTile(1, 1, 0),
+			features: []mvt.Feature{feature(geom.Point{3072, 1024})},
+			expected: [][4]float64{{2048, 2048, 2048, 2048}},
+		},
+		"line": {
+			child:    slippy.NewTile(1, 1, 0),
+			features: []mvt.Feature{feature(geom.LineString{{2148, 100}, {4000, 100}})},
+			expected: [][4]float64{{200, 200, 3904, 200}},
+		},
+		"line clipped": {
+			child:    slippy.NewTile(1, 1, 0),
+			features: []mvt.Feature{feature(geom.LineString{{1000, 100}, {3000, 100}})},
+			expected: [][4]float64{{0, 200, 1904, 200}},
+		},
+		"line clipped buffer": {
+			child:    slippy.NewTile(1, 1, 0),
+			buffer:   64,
+			features: []mvt.Feature{feature(geom.LineString{{1000, 100}, {3000, 100}})},
+			expected: [][4]float64{{-64, 200, 1904, 200}},
+		},
+		"outside dropped": {
+			child: slippy.NewTile(1, 1, 0),
+			features: []mvt.Feature{
+				feature(geom.LineString{{100, 3000}, {200, 3000}}),
+				feature(geom.Point{3072, 1024}),
+			},
+			expected: [][4]float64{{2048, 2048, 2048, 2048}},
+		},
+		"polygon": {
+			child: slippy.NewTile(1, 1, 0),
+			features: []mvt.Feature{feature(geom.Polygon{
+				{{2148, 100}, {2248, 100}, {2248, 200}, {2148, 200}},
+			})},
+			expected: [][4]float64{{200, 200, 400, 400}},
+		},
+		"polygon clipped": {
+			child: slippy.NewTile(2, 2, 1),
+			features: []mvt.Feature{feature(geom.Polygon{
+				{{1000, 1000}, {3000, 1000}, {3000, 3000}, {1000, 3000}},
+			})},
+			expected: [][4]float64{{0, 0, 3808, 4096}},
+		},
+		"polygon outside dropped": {
+			child: slippy.NewTile(1, 1, 0),
+			features: []mvt.Feature{
+				feature(geom.Polygon{{{100, 3000}, {200, 3000}, {200, 3100}, {100, 3100}}}),
+				feature(geom.MultiPolygon{{{{100, 3000}, {200, 3000}, {200, 3100}}}}),
+				feature(geom.Point{3072, 1024}),
+			},
+			expected: [][4]float64{{2048, 2048, 2048, 2048}},
+		},
+		"not descendant": {
+			child: slippy.NewTile(0, 0, 0),
+			err:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -1,9 +1,11 @@
 package mvt
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/hahaking119/geom/cmp"
+	"github.com/hahaking119/geom/slippy"
 
 	"github.com/hahaking119/geom/winding"
 
@@ -19,9 +21,53 @@ import (
 // projection would then flip the geomtery. To properly render these coordinate systems, simply
 // swap the X's or Y's in the tile extent.
 func PrepareGeo(geo geom.Geometry, tile *geom.Extent, pixelExtent float64) geom.Geometry {
+	return prepareGeo(geo, newPixelTransform(tile, pixelExtent))
+}
+
+// PrepareTileGeo converts the geometry's coordinates, which should be in the grid's coordinate
+// system, to the pixel coordinates of the tile. pixelExtent is the dimension of the (square)
+// tile in pixels, usually 4096, see DefaultExtent. An error is returned if the tile is not in the grid.
+func PrepareTileGeo(geo geom.Geometry, g slippy.Grid, tile *slippy.Tile, pixelExtent float64) (geom.Geometry, error) {
+	if tile == nil {
+		return nil, fmt.Errorf("nil tile")
+	}
+	res, ok := slippy.Resolution(g, tile.Z, pixelExtent)
+	if !ok {
+		return nil, fmt.Errorf("invalid zoom %v or pixel extent %v", tile.Z, pixelExtent)
+	}
+	if !tile.IsValid(g) {
+		return nil, fmt.Errorf("tile %v not valid for grid", tile)
+	}
+	topLeft, _ := g.ToNative(tile)
+	return prepareGeo(geo, pixelTransform{
+		minx:   topLeft.X(),
+		maxy:   topLeft.Y(),
+		scalex: 1 / res,
+		scaley: 1 / res,
+	}), nil
+}
+
+// pixelTransform converts coordinates to the pixel coordinates of a tile, which are y
+// positive down from the top left of the tile
+type pixelTransform struct {
+	minx, maxy     float64
+	scalex, scaley float64
+}
+
+// newPixelTransform returns the transform of the tile extent, which is pixelExtent pixels wide and tall
+func newPixelTransform(tile *geom.Extent, pixelExtent float64) pixelTransform {
+	return pixelTransform{
+		minx:   tile.MinX(),
+		maxy:   tile.MaxY(),
+		scalex: pixelExtent / tile.XSpan(),
+		scaley: pixelExtent / tile.YSpan(),
+	}
+}
+
+func prepareGeo(geo geom.Geometry, t pixelTransform) geom.Geometry {
 	switch g := geo.(type) {
 	case geom.Point:
-		return preparept(g, tile, pixelExtent)
+		return preparept(g, t)
 
 	case geom.MultiPoint:
 		pts := g.Points()
@@ -31,18 +77,18 @@ func PrepareGeo(geo geom.Geometry, tile *geom.Extent, pixelExtent float64) geom.
 
 		mp := make(geom.MultiPoint, len(pts))
 		for i, pt := range g {
-			mp[i] = preparept(pt, tile, pixelExtent)
+			mp[i] = preparept(pt, t)
 		}
 
 		return mp
 
 	case geom.LineString:
-		return preparelinestr(g, tile, pixelExtent)
+		return preparelinestr(g, t)
 
 	case geom.MultiLineString:
 		var ml geom.MultiLineString
 		for _, l := range g.LineStrings() {
-			nl := preparelinestr(l, tile, pixelExtent)
+			nl := preparelinestr(l, t)
 			if len(nl) > 0 {
 				ml = append(ml, nl)
 			}
@@ -50,12 +96,12 @@ func PrepareGeo(geo geom.Geometry, tile *geom.Extent, pixelExtent float64) geom.
 		return ml
 
 	case geom.Polygon:
-		return preparePolygon(g, tile, pixelExtent)
+		return preparePolygon(g, t)
 
 	case geom.MultiPolygon:
 		var mp geom.MultiPolygon
 		for _, p := range g.Polygons() {
-			np := preparePolygon(p, tile, pixelExtent)
+			np := preparePolygon(p, t)
 			if len(np) > 0 {
 				mp = append(mp, np)
 			}
@@ -67,7 +113,7 @@ func PrepareGeo(geo geom.Geometry, tile *geom.Extent, pixelExtent float64) geom.
 		}
 		var mp geom.MultiPolygon
 		for _, p := range g.Polygons() {
-			np := preparePolygon(p, tile, pixelExtent)
+			np := preparePolygon(p, t)
 			if len(np) > 0 {
 				mp = append(mp, np)
 			}
@@ -78,15 +124,15 @@ func PrepareGeo(geo geom.Geometry, tile *geom.Extent, pixelExtent float64) geom.
 	return nil
 }
 
-func preparept(g geom.Point, tile *geom.Extent, pixelExtent float64) geom.Point {
+func preparept(g geom.Point, t pixelTransform) geom.Point {
 
-	px := (g.X() - tile.MinX()) / tile.XSpan() * pixelExtent
-	py := (tile.MaxY() - g.Y()) / tile.YSpan() * pixelExtent
+	px := (g.X() - t.minx) * t.scalex
+	py := (t.maxy - g.Y()) * t.scaley
 
 	return geom.Point{float64(px), float64(py)}
 }
 
-func preparelinestr(g geom.LineString, tile *geom.Extent, pixelExtent float64) (ls geom.LineString) {
+func preparelinestr(g geom.LineString, t pixelTransform) (ls geom.LineString) {
 	pts := g
 	// If the linestring
 	if len(pts) < 2 {
@@ -96,13 +142,13 @@ func preparelinestr(g geom.LineString, tile *geom.Extent, pixelExtent float64) (
 
 	ls = make(geom.LineString, 0, len(pts))
 	for i := 0; i < len(pts); i++ {
-		npt := preparept(pts[i], tile, pixelExtent)
+		npt := preparept(pts[i], t)
 
 		if i != 0 && cmp.HiCMP.GeomPointEqual(ls[len(ls)-1], npt) {
 			// skip points that are equivalent due to precision truncation
 			continue
 		}
-		ls = append(ls, preparept(pts[i], tile, pixelExtent))
+		ls = append(ls, preparept(pts[i], t))
 	}
 	if len(ls) < 2 {
 		return nil
@@ -111,7 +157,7 @@ func preparelinestr(g geom.LineString, tile *geom.Extent, pixelExtent float64) (
 	return ls
 }
 
-func preparePolygon(g geom.Polygon, tile *geom.Extent, pixelExtent float64) (p geom.Polygon) {
+func preparePolygon(g geom.Polygon, t pixelTransform) (p geom.Polygon) {
 	lines := geom.MultiLineString(g.LinearRings())
 	p = make(geom.Polygon, 0, len(lines))
 
@@ -128,7 +174,7 @@ func preparePolygon(g geom.Polygon, tile *geom.Extent, pixelExtent float64) (p g
 			}
 			continue
 		}
-		ln := preparelinestr(line, tile, pixelExtent)
+		ln := preparelinestr(line, t)
 		if cmp.HiCMP.GeomPointEqual(ln[0], ln[len(ln)-1]) {
 			// first and last is the same, need to remove the last point.
 			ln = ln[:len(ln)-1]
@@ -143,8 +189,9 @@ func preparePolygon(g geom.Polygon, tile *geom.Extent, pixelExtent float64) (p g
 		p = append(p, ln)
 	}
 
+	// pixel coordinates are y positive down
 	order := winding.Order{
-		YPositiveDown: false,
+		YPositiveDown: true,
 	}
 	return geom.Polygon(order.RectifyPolygon([][][2]float64(p)))
 }
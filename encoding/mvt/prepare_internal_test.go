@@ -5,6 +5,7 @@ import (
 
 	"github.com/hahaking119/geom"
 	"github.com/hahaking119/geom/cmp"
+	"github.com/hahaking119/geom/slippy"
 )
 
 func TestPrepareLinestring(t *testing.T) {
@@ -17,7 +18,7 @@ func TestPrepareLinestring(t *testing.T) {
 
 	fn := func(tc tcase) func(t *testing.T) {
 		return func(t *testing.T) {
-			got := preparelinestr(tc.in, &tc.tile, float64(DefaultExtent))
+			got := preparelinestr(tc.in, newPixelTransform(&tc.tile, float64(DefaultExtent)))
 
 			if len(got) != len(tc.out) {
 				t.Errorf("expected %v got %v", tc.out, got)
@@ -73,3 +74,37 @@ func TestPrepareLinestring(t *testing.T) {
 		t.Run(name, fn(tc))
 	}
 }
+
+func TestPrepareTileGeo(t *testing.T) {
+	grid, err := slippy.NewGrid(3857)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := PrepareTileGeo(geom.Point{0, 0}, grid, slippy.NewTile(1, 0, 0), float64(DefaultExtent))
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	if !cmp.GeometryEqual(got, geom.Point{4096, 4096}) {
+		t.Errorf("expected %v got %v", geom.Point{4096, 4096}, got)
+	}
+
+	if _, err = PrepareTileGeo(geom.Point{0, 0}, grid, slippy.NewTile(1, 3, 0), float64(DefaultExtent)); err == nil {
+		t.Errorf("error, expected error for tile outside the grid")
+	}
+	if _, err = PrepareTileGeo(geom.Point{0, 0}, grid, nil, float64(DefaultExtent)); err == nil {
+		t.Errorf("error, expected error for nil tile")
+	}
+
+	// the same transform as PrepareGeo with the extent of the tile
+	tile := slippy.NewTile(3, 5, 2)
+	ext, _ := slippy.Extent(grid, tile)
+	line := geom.LineString{{ext.MinX() + 1000, ext.MaxY() - 2000}, {ext.MaxX() - 10, ext.MinY() + 10}}
+	got, err = PrepareTileGeo(line, grid, tile, float64(DefaultExtent))
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	if expected := PrepareGeo(line, ext, float64(DefaultExtent)); !cmp.GeometryEqual(got, expected) {
+		t.Errorf("expected %v got %v", expected, got)
+	}
+}
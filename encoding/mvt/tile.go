@@ -10,6 +10,10 @@ import (
 
 // Tile describes a Mapbox Vector Tile
 type Tile struct {
+	// MaxSize is the maximum size, in bytes, of the encoded tile; 0 means
+	// there is no limit. See Encode.
+	MaxSize int
+
 	layers []Layer
 }
 
@@ -0,0 +1,207 @@
+package mvt
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/winding"
+)
+
+var (
+	ErrRepeatedPoint = fmt.Errorf("geometry has repeated points")
+	ErrTooFewPoints  = fmt.Errorf("geometry has too few points")
+	ErrZeroAreaRing  = fmt.Errorf("polygon ring has zero area")
+	ErrWrongWinding  = fmt.Errorf("polygon ring has the wrong winding order")
+)
+
+// ValidationMode is how geometries that break the geometry rules of the
+// MVT 2.1 spec are handled when a feature is encoded. The rules are checked
+// on the integer tile coordinates that are encoded:
+//
+//	lines have at least two points
+//	rings have at least three points, four with the implied closing point
+//	there are no repeated consecutive points
+//	rings have an area
+//	exterior rings are clockwise and interior rings counterclockwise, with y positive down
+type ValidationMode uint8
+
+const (
+	// ValidationFix fixes geometries that break the rules. Repeated points are removed,
+	// lines and rings with too few points or no area are dropped and the winding order
+	// of rings is corrected with winding.Order.RectifyPolygon. A polygon without its
+	// exterior ring is dropped. This is the default.
+	ValidationFix ValidationMode = iota
+	// ValidationStrict returns an error for a geometry that breaks the rules
+	ValidationStrict
+)
+
+func (m ValidationMode) String() string {
+	switch m {
+	case ValidationFix:
+		return "fix"
+	case ValidationStrict:
+		return "strict"
+	default:
+		return "unknown"
+	}
+}
+
+// tileOrder is the winding order of the tile coordinate system
+var tileOrder = winding.Order{YPositiveDown: true}
+
+// validateGeometry checks the geometry against the MVT 2.1 rules, returning the geometry
+// in the integer coordinates it will be encoded with. In fix mode nil is returned if there
+// is nothing left of the geometry.
+func validateGeometry(geo geom.Geometry, mode ValidationMode) (geom.Geometry, error) {
+	switch g := geo.(type) {
+	case geom.Point:
+		return geom.Point(truncPoint(g)), nil
+
+	case geom.MultiPoint:
+		if len(g) == 0 {
+			if mode == ValidationStrict {
+				return nil, ErrTooFewPoints
+			}
+			return nil, nil
+		}
+		mp := make(geom.MultiPoint, len(g))
+		for i := range g {
+			mp[i] = truncPoint(g[i])
+		}
+		return mp, nil
+
+	case geom.LineString:
+		ls, err := validateLine(g, 2, mode)
+		if ls == nil {
+			return nil, err
+		}
+		return geom.LineString(ls), nil
+
+	case geom.MultiLineString:
+		var mls geom.MultiLineString
+		for _, l := range g {
+			ls, err := validateLine(l, 2, mode)
+			if err != nil {
+				return nil, err
+			}
+			if ls != nil {
+				mls = append(mls, ls)
+			}
+		}
+		if len(mls) == 0 {
+			if mode == ValidationStrict {
+				return nil, ErrTooFewPoints
+			}
+			return nil, nil
+		}
+		return mls, nil
+
+	case geom.Polygon:
+		ply, err := validatePolygon(g, mode)
+		if ply == nil {
+			return nil, err
+		}
+		return geom.Polygon(ply), nil
+
+	case geom.MultiPolygon:
+		var mp geom.MultiPolygon
+		for _, p := range g {
+			ply, err := validatePolygon(p, mode)
+			if err != nil {
+				return nil, err
+			}
+			if ply != nil {
+				mp = append(mp, ply)
+			}
+		}
+		if len(mp) == 0 {
+			if mode == ValidationStrict {
+				return nil, ErrTooFewPoints
+			}
+			return nil, nil
+		}
+		return mp, nil
+
+	case *geom.MultiPolygon:
+		if g == nil {
+			return nil, nil
+		}
+		return validateGeometry(*g, mode)
+
+	case nil:
+		return nil, ErrNilGeometryType
+
+	default:
+		return nil, ErrUnknownGeometryType
+	}
+}
+
+func truncPoint(pt [2]float64) [2]float64 {
+	return [2]float64{math.Trunc(pt[0]), math.Trunc(pt[1])}
+}
+
+// validateLine returns the line in integer coordinates without repeated points. For rings, a
+// min of 3, the closing point is removed if the ring is explicitly closed. nil is returned if the
+// line has less than min points.
+func validateLine(line [][2]float64, min int, mode ValidationMode) ([][2]float64, error) {
+	ls := make([][2]float64, 0, len(line))
+	for _, pt := range line {
+		pt = truncPoint(pt)
+		if len(ls) > 0 && ls[len(ls)-1] == pt {
+			if mode == ValidationStrict {
+				return nil, ErrRepeatedPoint
+			}
+			continue
+		}
+		ls = append(ls, pt)
+	}
+	if min > 2 && len(ls) > 1 && ls[0] == ls[len(ls)-1] {
+		// rings are implicitly closed, so this isn't a repeated point
+		ls = ls[:len(ls)-1]
+	}
+	if len(ls) < min {
+		if mode == ValidationStrict {
+			return nil, ErrTooFewPoints
+		}
+		return nil, nil
+	}
+	return ls, nil
+}
+
+// validatePolygon returns the polygon in integer coordinates with its rings in the
+// correct winding order.
+func validatePolygon(rings [][][2]float64, mode ValidationMode) ([][][2]float64, error) {
+	ply := make([][][2]float64, 0, len(rings))
+	for i := range rings {
+		ring, err := validateLine(rings[i], 3, mode)
+		if err != nil {
+			return nil, err
+		}
+		if ring == nil {
+			if i == 0 {
+				return nil, nil
+			}
+			continue
+		}
+
+		if mode == ValidationStrict {
+			wo := tileOrder.OfPoints(ring...)
+			switch {
+			case wo.IsColinear():
+				return nil, ErrZeroAreaRing
+			case i == 0 && !wo.IsClockwise(), i != 0 && !wo.IsCounterClockwise():
+				return nil, ErrWrongWinding
+			}
+		}
+		ply = append(ply, ring)
+	}
+	if mode == ValidationStrict {
+		return ply, nil
+	}
+	// an empty polygon is returned as nil, so the feature is dropped
+	if ply = tileOrder.RectifyPolygon(ply); len(ply) == 0 {
+		return nil, nil
+	}
+	return ply, nil
+}
@@ -0,0 +1,119 @@
+package mvt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/cmp"
+)
+
+func TestValidateGeometry(t *testing.T) {
+	type tcase struct {
+		geo    geom.Geometry
+		fixed  geom.Geometry
+		strict error
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := validateGeometry(tc.geo, ValidationFix)
+			if err != nil {
+				t.Fatalf("fix error, expected nil got %v", err)
+			}
+			if tc.fixed == nil {
+				if got != nil {
+					t.Errorf("fix, expected nil got %v", got)
+				}
+			} else if !cmp.GeometryEqual(got, tc.fixed) {
+				t.Errorf("fix, expected %v got %v", tc.fixed, got)
+			}
+
+			got, err = validateGeometry(tc.geo, ValidationStrict)
+			if err != tc.strict {
+				t.Fatalf("strict error, expected %v got %v", tc.strict, err)
+			}
+			if tc.strict == nil && !cmp.GeometryEqual(got, tc.fixed) {
+				t.Errorf("strict, expected %v got %v", tc.fixed, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"valid polygon": {
+			geo: geom.Polygon{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+				{{2, 2}, {2, 8}, {8, 8}, {8, 2}},
+			},
+			fixed: geom.Polygon{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+				{{2, 2}, {2, 8}, {8, 8}, {8, 2}},
+			},
+		},
+		"explicitly closed ring": {
+			geo:   geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}},
+			fixed: geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}},
+		},
+		"truncated coordinates": {
+			geo:   geom.LineString{{0.7, 1.2}, {5.5, 6.9}},
+			fixed: geom.LineString{{0, 1}, {5, 6}},
+		},
+		"wrong winding": {
+			geo: geom.Polygon{
+				{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+				{{2, 2}, {8, 2}, {8, 8}, {2, 8}},
+			},
+			fixed: geom.Polygon{
+				{{10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{2, 8}, {8, 8}, {8, 2}, {2, 2}},
+			},
+			strict: ErrWrongWinding,
+		},
+		"repeated points": {
+			geo:    geom.Polygon{{{0, 0}, {10, 0}, {10, 0.5}, {10, 10}, {0, 10}}},
+			fixed:  geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}},
+			strict: ErrRepeatedPoint,
+		},
+		"too few points": {
+			geo:    geom.Polygon{{{0, 0}, {10, 0}, {10, 0.2}}},
+			strict: ErrRepeatedPoint,
+		},
+		"zero area polygon": {
+			geo:    geom.Polygon{{{0, 0}, {5, 5}, {10, 10}}},
+			strict: ErrZeroAreaRing,
+		},
+		"degenerate hole": {
+			geo: geom.Polygon{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+				{{2, 2}, {2, 8}},
+			},
+			fixed:  geom.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}},
+			strict: ErrTooFewPoints,
+		},
+		"short line": {
+			geo:    geom.MultiLineString{{{1, 1}}, {{1, 1}, {2, 2}}},
+			fixed:  geom.MultiLineString{{{1, 1}, {2, 2}}},
+			strict: ErrTooFewPoints,
+		},
+		"empty multipoint": {
+			geo:    geom.MultiPoint{},
+			strict: ErrTooFewPoints,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestVTileFeatureWithValidation(t *testing.T) {
+	f := Feature{Geometry: geom.Polygon{{{0, 0}, {5, 5}, {10, 10}}}}
+
+	vtf, err := f.VTileFeature(context.Background(), nil, nil)
+	if err != nil || vtf != nil {
+		t.Errorf("fix, expected nil feature and error got %v, %v", vtf, err)
+	}
+	if _, err = f.VTileFeatureWithValidation(context.Background(), nil, nil, ValidationStrict); err != ErrZeroAreaRing {
+		t.Errorf("strict error, expected %v got %v", ErrZeroAreaRing, err)
+	}
+}
@@ -0,0 +1,293 @@
+package mvt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// NilMode is how nil tag values are encoded
+type NilMode uint8
+
+const (
+	// NilSkip leaves the tag out of the feature, see https://github.com/mapbox/vector-tile-spec/issues/62
+	NilSkip NilMode = iota
+	// NilEmptyString encodes nil as an empty string
+	NilEmptyString
+	// NilNullString encodes nil as the string "null"
+	NilNullString
+)
+
+// CompositeMode is how slice, array and map tag values are encoded
+type CompositeMode uint8
+
+const (
+	// CompositeJSON encodes the value as a JSON string
+	CompositeJSON CompositeMode = iota
+	// CompositeFlatten flattens the value into a tag for each element, the key of an
+	// element is the tag's key, FlattenSeparator and the index or map key of the element.
+	// e.g. {"names": {"en": "Vienna"}} becomes {"names.en": "Vienna"}
+	CompositeFlatten
+)
+
+// IntType is the MVT value type an integer is encoded as
+type IntType uint8
+
+const (
+	// IntTypeDefault encodes int64 and int as int_value and smaller signed values as sint_value,
+	// and uint64 and uint as uint_value and smaller unsigned values as sint_value.
+	IntTypeDefault IntType = iota
+	// IntTypeInt encodes as int_value
+	IntTypeInt
+	// IntTypeUint encodes as uint_value, negative values can not be represented
+	IntTypeUint
+	// IntTypeSint encodes as sint_value, which is zigzag encoded and so smaller for negative values
+	IntTypeSint
+)
+
+// DefaultFlattenSeparator is the separator used between flattened keys
+const DefaultFlattenSeparator = "."
+
+// ValueOptions configures how tag values are encoded into MVT values. The zero value
+// skips nil values, encodes slices and maps as JSON and time.Time values as RFC 3339.
+type ValueOptions struct {
+	// Nil is how nil values, including nil pointers, are encoded
+	Nil NilMode
+	// Composite is how slices, arrays and maps are encoded
+	Composite CompositeMode
+	// FlattenSeparator is the separator used with CompositeFlatten, defaults to DefaultFlattenSeparator
+	FlattenSeparator string
+	// TimeFormat is the layout time.Time values are formatted with, defaults to time.RFC3339
+	TimeFormat string
+	// Signed is the value type signed integers are encoded as
+	Signed IntType
+	// Unsigned is the value type unsigned integers are encoded as
+	Unsigned IntType
+	// OnUnsupported is called with the key and value of values that can not be represented.
+	// If it returns nil the tag is dropped, otherwise encoding fails with the error. If it is
+	// not set encoding fails.
+	OnUnsupported func(key string, value interface{}) error
+}
+
+// the integer value types, so the MVT value type is known when encoding
+type (
+	intValue  int64
+	uintValue uint64
+	sintValue int64
+)
+
+// unsupported handles a value that can not be represented
+func (o ValueOptions) unsupported(key string, value interface{}) error {
+	if o.OnUnsupported == nil {
+		return fmt.Errorf("value (%[1]v) of type (%[1]T) for key (%[2]v) is not supported", value, key)
+	}
+	return o.OnUnsupported(key, value)
+}
+
+// normalizeTags returns the tags with the values converted to the values that will be encoded
+func (o ValueOptions) normalizeTags(tags map[string]interface{}) (map[string]interface{}, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+	normalized := make(map[string]interface{}, len(tags))
+	for k, v := range tags {
+		if err := o.normalize(normalized, k, v); err != nil {
+			return nil, err
+		}
+	}
+	return normalized, nil
+}
+
+// normalize adds the value, converted to the value to be encoded, to tags
+func (o ValueOptions) normalize(tags map[string]interface{}, key string, value interface{}) error {
+	// pointers are handled before the Stringer and time cases, which would call
+	// methods on nil pointers and skip the TimeFormat of *time.Time
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return o.normalize(tags, key, nil)
+		}
+		elem := rv.Elem().Interface()
+		// the String method may only be defined on the pointer
+		if _, ok := elem.(fmt.Stringer); !ok {
+			if s, ok := value.(fmt.Stringer); ok {
+				tags[key] = s.String()
+				return nil
+			}
+		}
+		return o.normalize(tags, key, elem)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		switch o.Nil {
+		case NilEmptyString:
+			tags[key] = ""
+		case NilNullString:
+			tags[key] = "null"
+		}
+		return nil
+
+	case string, bool, float32, float64, intValue, uintValue, sintValue:
+		tags[key] = v
+		return nil
+
+	case int:
+		return o.signed(tags, key, int64(v), IntTypeInt)
+	case int8:
+		return o.signed(tags, key, int64(v), IntTypeSint)
+	case int16:
+		return o.signed(tags, key, int64(v), IntTypeSint)
+	case int32:
+		return o.signed(tags, key, int64(v), IntTypeSint)
+	case int64:
+		return o.signed(tags, key, v, IntTypeInt)
+
+	case uint:
+		return o.unsigned(tags, key, uint64(v), IntTypeUint)
+	case uint8:
+		return o.unsigned(tags, key, uint64(v), IntTypeSint)
+	case uint16:
+		return o.unsigned(tags, key, uint64(v), IntTypeSint)
+	case uint32:
+		return o.unsigned(tags, key, uint64(v), IntTypeSint)
+	case uint64:
+		return o.unsigned(tags, key, v, IntTypeUint)
+
+	case time.Time:
+		layout := o.TimeFormat
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		tags[key] = v.Format(layout)
+		return nil
+
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return o.signed(tags, key, i, IntTypeInt)
+		}
+		if f, err := v.Float64(); err == nil {
+			tags[key] = f
+			return nil
+		}
+		tags[key] = v.String()
+		return nil
+
+	case fmt.Stringer:
+		tags[key] = v.String()
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if o.Composite == CompositeFlatten {
+			return o.flatten(tags, key, rv)
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return o.unsupported(key, value)
+		}
+		tags[key] = string(b)
+		return nil
+
+	// named types of the basic types, e.g. type Kind string
+	case reflect.String:
+		tags[key] = rv.String()
+		return nil
+	case reflect.Bool:
+		tags[key] = rv.Bool()
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return o.normalize(tags, key, rv.Convert(basicTypes[rv.Kind()]).Interface())
+	}
+
+	return o.unsupported(key, value)
+}
+
+// basicTypes are the types of the numeric kinds
+var basicTypes = map[reflect.Kind]reflect.Type{
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+}
+
+// flatten adds a tag for each of the elements of the slice, array or map
+func (o ValueOptions) flatten(tags map[string]interface{}, key string, rv reflect.Value) error {
+	sep := o.FlattenSeparator
+	if sep == "" {
+		sep = DefaultFlattenSeparator
+	}
+
+	if rv.Kind() == reflect.Map {
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := key + sep + fmt.Sprint(iter.Key().Interface())
+			if err := o.normalize(tags, k, iter.Value().Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		k := key + sep + strconv.Itoa(i)
+		if err := o.normalize(tags, k, rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signed adds the signed integer as the configured type, or def for IntTypeDefault
+func (o ValueOptions) signed(tags map[string]interface{}, key string, v int64, def IntType) error {
+	typ := o.Signed
+	if typ == IntTypeDefault {
+		typ = def
+	}
+	switch typ {
+	case IntTypeInt:
+		tags[key] = intValue(v)
+	case IntTypeSint:
+		tags[key] = sintValue(v)
+	case IntTypeUint:
+		if v < 0 {
+			return o.unsupported(key, v)
+		}
+		tags[key] = uintValue(v)
+	}
+	return nil
+}
+
+// unsigned adds the unsigned integer as the configured type, or def for IntTypeDefault
+func (o ValueOptions) unsigned(tags map[string]interface{}, key string, v uint64, def IntType) error {
+	typ := o.Unsigned
+	if typ == IntTypeDefault {
+		typ = def
+	}
+	if typ != IntTypeUint && v > math.MaxInt64 {
+		return o.unsupported(key, v)
+	}
+	switch typ {
+	case IntTypeInt:
+		tags[key] = intValue(v)
+	case IntTypeSint:
+		tags[key] = sintValue(v)
+	case IntTypeUint:
+		tags[key] = uintValue(v)
+	}
+	return nil
+}
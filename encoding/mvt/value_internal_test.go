@@ -0,0 +1,230 @@
+package mvt
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hahaking119/geom"
+)
+
+// ptrStringer only has a String method on the pointer
+type ptrStringer struct{}
+
+func (*ptrStringer) String() string { return "ptr" }
+
+func TestNormalizeTags(t *testing.T) {
+	type tcase struct {
+		opts     ValueOptions
+		tags     map[string]interface{}
+		expected map[string]interface{}
+		err      bool
+	}
+
+	type kind string
+	name := "vienna"
+	when := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+	errUnsupported := errors.New("unsupported")
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := tc.opts.normalizeTags(tc.tags)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("tags, expected %#v got %#v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"scalars": {
+			tags: map[string]interface{}{
+				"s": "a", "b": true, "f": 1.5, "i": 3, "i8": int8(-3), "u": uint(4), "u16": uint16(5), "k": kind("road"),
+			},
+			expected: map[string]interface{}{
+				"s": "a", "b": true, "f": 1.5, "i": intValue(3), "i8": sintValue(-3), "u": uintValue(4), "u16": sintValue(5), "k": "road",
+			},
+		},
+		"explicit int types": {
+			opts: ValueOptions{Signed: IntTypeSint, Unsigned: IntTypeInt},
+			tags: map[string]interface{}{"i": int64(-3), "u": uint64(4)},
+			expected: map[string]interface{}{
+				"i": sintValue(-3), "u": intValue(4),
+			},
+		},
+		"negative as uint": {
+			opts: ValueOptions{Signed: IntTypeUint},
+			tags: map[string]interface{}{"i": -3},
+			err:  true,
+		},
+		"nil": {
+			tags:     map[string]interface{}{"a": nil, "b": (*string)(nil), "c": &name},
+			expected: map[string]interface{}{"c": "vienna"},
+		},
+		"nil as string": {
+			opts:     ValueOptions{Nil: NilNullString},
+			tags:     map[string]interface{}{"a": nil},
+			expected: map[string]interface{}{"a": "null"},
+		},
+		"json": {
+			tags: map[string]interface{}{
+				"list":  []interface{}{1, "a"},
+				"names": map[string]interface{}{"en": "Vienna"},
+			},
+			expected: map[string]interface{}{
+				"list":  `[1,"a"]`,
+				"names": `{"en":"Vienna"}`,
+			},
+		},
+		"flatten": {
+			opts: ValueOptions{Composite: CompositeFlatten, FlattenSeparator: ":"},
+			tags: map[string]interface{}{
+				"list":  []interface{}{1, "a"},
+				"names": map[string]interface{}{"en": "Vienna", "alt": []string{"Wien"}},
+			},
+			expected: map[string]interface{}{
+				"list:0":      intValue(1),
+				"list:1":      "a",
+				"names:en":    "Vienna",
+				"names:alt:0": "Wien",
+			},
+		},
+		"time": {
+			opts:     ValueOptions{TimeFormat: "2006-01-02"},
+			tags:     map[string]interface{}{"t": time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)},
+			expected: map[string]interface{}{"t": "2020-03-04"},
+		},
+		"time pointers": {
+			opts:     ValueOptions{TimeFormat: "2006-01-02", Nil: NilNullString},
+			tags:     map[string]interface{}{"t": &when, "n": (*time.Time)(nil)},
+			expected: map[string]interface{}{"t": "2020-03-04", "n": "null"},
+		},
+		"nil time pointer skipped": {
+			tags:     map[string]interface{}{"n": (*time.Time)(nil), "a": "b"},
+			expected: map[string]interface{}{"a": "b"},
+		},
+		"pointer stringer": {
+			tags:     map[string]interface{}{"s": &ptrStringer{}, "n": (*ptrStringer)(nil)},
+			expected: map[string]interface{}{"s": "ptr"},
+		},
+		"unsupported": {
+			tags: map[string]interface{}{"c": struct{}{}},
+			err:  true,
+		},
+		"unsupported hook drop": {
+			opts:     ValueOptions{OnUnsupported: func(string, interface{}) error { return nil }},
+			tags:     map[string]interface{}{"c": struct{}{}, "a": "b"},
+			expected: map[string]interface{}{"a": "b"},
+		},
+		"unsupported hook error": {
+			opts: ValueOptions{OnUnsupported: func(string, interface{}) error { return errUnsupported }},
+			tags: map[string]interface{}{"c": make(chan int)},
+			err:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestLayerValueOptions(t *testing.T) {
+	layer := Layer{
+		Name:         "layer",
+		ValueOptions: ValueOptions{Signed: IntTypeSint},
+	}
+	layer.AddFeatures(Feature{
+		Geometry: geom.Point{1, 1},
+		Tags:     map[string]interface{}{"i": -1, "u": uint64(2), "l": []string{"a"}},
+	})
+
+	vtl, err := layer.VTileLayer(context.Background())
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	if len(vtl.Values) != 3 {
+		t.Fatalf("values, expected 3 got %v", vtl.Values)
+	}
+	for _, v := range vtl.Values {
+		switch {
+		case v.SintValue != nil:
+			if *v.SintValue != -1 {
+				t.Errorf("sint value, expected -1 got %v", *v.SintValue)
+			}
+		case v.UintValue != nil:
+			if *v.UintValue != 2 {
+				t.Errorf("uint value, expected 2 got %v", *v.UintValue)
+			}
+		case v.StringValue != nil:
+			if *v.StringValue != `["a"]` {
+				t.Errorf("string value, expected [\"a\"] got %v", *v.StringValue)
+			}
+		default:
+			t.Errorf("value, unexpected %v", v)
+		}
+	}
+}
+
+func TestDefaultIntEncoding(t *testing.T) {
+	type tcase struct {
+		value interface{}
+		// expected is the field of the value that is set, int, uint or sint
+		expected string
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			layer := Layer{Name: "layer"}
+			layer.AddFeatures(Feature{
+				Geometry: geom.Point{1, 1},
+				Tags:     map[string]interface{}{"v": tc.value},
+			})
+
+			vtl, err := layer.VTileLayer(context.Background())
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if len(vtl.Values) != 1 {
+				t.Fatalf("values, expected 1 got %v", vtl.Values)
+			}
+
+			var got string
+			switch v := vtl.Values[0]; {
+			case v.IntValue != nil:
+				got = "int"
+			case v.UintValue != nil:
+				got = "uint"
+			case v.SintValue != nil:
+				got = "sint"
+			}
+			if got != tc.expected {
+				t.Errorf("value type, expected %v got %v (%v)", tc.expected, got, vtl.Values[0])
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"int":    {value: -1, expected: "int"},
+		"int64":  {value: int64(-1), expected: "int"},
+		"int32":  {value: int32(-1), expected: "sint"},
+		"int8":   {value: int8(-1), expected: "sint"},
+		"uint":   {value: uint(1), expected: "uint"},
+		"uint64": {value: uint64(1), expected: "uint"},
+		"uint32": {value: uint32(1), expected: "sint"},
+		"uint8":  {value: uint8(1), expected: "sint"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -0,0 +1,209 @@
+// Package polyline implements the encoded polyline algorithm format used by
+// Google Maps and many routing engines, as described at
+// https://developers.google.com/maps/documentation/utilities/polylinealgorithm.
+//
+// Each coordinate is scaled by the precision, rounded, delta encoded from the previous
+// coordinate and written as printable ASCII. Geometries have x as the longitude and y
+// as the latitude; polylines are usually written latitude first.
+package polyline
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+)
+
+// DefaultPrecision is the precision, in decimal digits, of Google's polylines. Some
+// routing engines, such as OSRM and Valhalla, also use a precision of 6.
+const DefaultPrecision = 5
+
+// Order is the order of the axes in the polyline
+type Order uint8
+
+const (
+	// LatLng writes the latitude, the y value, first
+	LatLng Order = iota
+	// LngLat writes the longitude, the x value, first
+	LngLat
+)
+
+// Options are the options for encoding and decoding polylines
+type Options struct {
+	// Precision is the number of decimal digits of the latitudes and longitudes,
+	// defaults to DefaultPrecision if nil
+	Precision *int
+	// PrecisionZ is the number of decimal digits of the elevations
+	PrecisionZ int
+	// Order is the order of the latitude and longitude
+	Order Order
+}
+
+func (o Options) precision() int {
+	if o.Precision == nil {
+		return DefaultPrecision
+	}
+	return *o.Precision
+}
+
+// ErrInvalidPolyline is returned when decoding a string that is not a valid polyline
+type ErrInvalidPolyline struct {
+	// Pos is the position of the invalid character, or the length of the polyline if
+	// it ended early
+	Pos   int
+	Issue string
+}
+
+func (e ErrInvalidPolyline) Error() string {
+	return fmt.Sprintf("invalid polyline at %v: %v", e.Pos, e.Issue)
+}
+
+// Encode returns the polyline of the line string or multi point. The elevations of a
+// geom.LineStringZ or geom.MultiPointZ are written as a third value of each point.
+func Encode(geo geom.Geometry, opts Options) (string, error) {
+	var pts [][]float64
+	var precisions []int
+	switch g := geo.(type) {
+	case geom.LineStringZ:
+		pts = coordsZ(g, opts.Order)
+		precisions = []int{opts.precision(), opts.precision(), opts.PrecisionZ}
+	case geom.MultiPointZ:
+		pts = coordsZ(g, opts.Order)
+		precisions = []int{opts.precision(), opts.precision(), opts.PrecisionZ}
+	case geom.LineStringer:
+		pts = coords(g.Vertices(), opts.Order)
+		precisions = []int{opts.precision(), opts.precision()}
+	case geom.MultiPointer:
+		pts = coords(g.Points(), opts.Order)
+		precisions = []int{opts.precision(), opts.precision()}
+	default:
+		return "", encoding.ErrUnknownGeometry{Geom: geo}
+	}
+
+	var s strings.Builder
+	last := make([]int64, len(precisions))
+	for _, pt := range pts {
+		for d := range precisions {
+			v := int64(math.Round(pt[d] * math.Pow10(precisions[d])))
+			writeValue(&s, v-last[d])
+			last[d] = v
+		}
+	}
+	return s.String(), nil
+}
+
+// Decode returns the line string of the polyline
+func Decode(polyline string, opts Options) (geom.LineString, error) {
+	vs, err := decodeValues(polyline, []int{opts.precision(), opts.precision()})
+	if err != nil {
+		return nil, err
+	}
+	ls := make(geom.LineString, len(vs))
+	for i, v := range vs {
+		ls[i] = [2]float64{v[0], v[1]}
+		if opts.Order == LatLng {
+			ls[i] = [2]float64{v[1], v[0]}
+		}
+	}
+	return ls, nil
+}
+
+// DecodeZ returns the line string of the polyline, which has an elevation as the third
+// value of each point.
+func DecodeZ(polyline string, opts Options) (geom.LineStringZ, error) {
+	vs, err := decodeValues(polyline, []int{opts.precision(), opts.precision(), opts.PrecisionZ})
+	if err != nil {
+		return nil, err
+	}
+	ls := make(geom.LineStringZ, len(vs))
+	for i, v := range vs {
+		ls[i] = [3]float64{v[0], v[1], v[2]}
+		if opts.Order == LatLng {
+			ls[i] = [3]float64{v[1], v[0], v[2]}
+		}
+	}
+	return ls, nil
+}
+
+// writeValue writes the value as chunks of 5 bits, with the sign in the lowest bit
+func writeValue(s *strings.Builder, v int64) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		s.WriteByte(byte(0x20|(u&0x1f)) + 63)
+		u >>= 5
+	}
+	s.WriteByte(byte(u) + 63)
+}
+
+// decodeValues returns the points of the polyline, with a value for each precision
+func decodeValues(polyline string, precisions []int) ([][]float64, error) {
+	var (
+		pts  [][]float64
+		last = make([]int64, len(precisions))
+	)
+	for i := 0; i < len(polyline); {
+		pt := make([]float64, len(precisions))
+		for d := range precisions {
+			var (
+				u     uint64
+				shift uint
+			)
+			for {
+				if i >= len(polyline) {
+					return nil, ErrInvalidPolyline{Pos: i, Issue: "unexpected end"}
+				}
+				c := polyline[i]
+				if c < 63 || c > 126 {
+					return nil, ErrInvalidPolyline{Pos: i, Issue: fmt.Sprintf("invalid character %q", c)}
+				}
+				if shift > 60 {
+					return nil, ErrInvalidPolyline{Pos: i, Issue: "value overflows"}
+				}
+				i++
+				c -= 63
+				u |= uint64(c&0x1f) << shift
+				shift += 5
+				if c < 0x20 {
+					break
+				}
+			}
+			v := int64(u >> 1)
+			if u&1 != 0 {
+				v = ^v
+			}
+			last[d] += v
+			pt[d] = float64(last[d]) / math.Pow10(precisions[d])
+		}
+		pts = append(pts, pt)
+	}
+	return pts, nil
+}
+
+// coords returns the points with the axes in the order
+func coords(pts [][2]float64, order Order) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i, pt := range pts {
+		cs[i] = []float64{pt[0], pt[1]}
+		if order == LatLng {
+			cs[i] = []float64{pt[1], pt[0]}
+		}
+	}
+	return cs
+}
+
+// coordsZ returns the points with the axes in the order, and the elevation last
+func coordsZ(pts [][3]float64, order Order) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i, pt := range pts {
+		cs[i] = []float64{pt[0], pt[1], pt[2]}
+		if order == LatLng {
+			cs[i] = []float64{pt[1], pt[0], pt[2]}
+		}
+	}
+	return cs
+}
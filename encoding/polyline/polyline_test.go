@@ -0,0 +1,165 @@
+package polyline_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding/polyline"
+)
+
+func precision(p int) *int { return &p }
+
+func TestEncode(t *testing.T) {
+	type tcase struct {
+		geo      geom.Geometry
+		opts     polyline.Options
+		expected string
+		err      bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := polyline.Encode(tc.geo, tc.opts)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("polyline, expected %q got %q", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		// the example of the algorithm's documentation
+		"line string": {
+			geo:      geom.LineString{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}},
+			expected: "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+		},
+		"multi point": {
+			geo:      geom.MultiPoint{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}},
+			expected: "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+		},
+		"lng lat": {
+			geo:      geom.LineString{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}},
+			opts:     polyline.Options{Order: polyline.LngLat},
+			expected: "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+		},
+		"precision 6": {
+			geo:      geom.LineString{{-120.2, 38.5}, {-120.95, 40.7}},
+			opts:     polyline.Options{Precision: precision(6)},
+			expected: "_izlhA~rlgdF_{geC~ywl@",
+		},
+		"precision 0": {
+			geo:      geom.LineString{{-120.2, 38.5}, {-120.95, 40.7}},
+			opts:     polyline.Options{Precision: precision(0)},
+			expected: "mAnFC@",
+		},
+		"z": {
+			geo:      geom.LineStringZ{{-120.2, 38.5, 100}, {-120.95, 40.7, 90}},
+			expected: "_p~iF~ps|UgE_ulLnnqCR",
+		},
+		"empty": {
+			geo:      geom.LineString{},
+			expected: "",
+		},
+		"polygon": {
+			geo: geom.Polygon{{{0, 0}, {1, 0}, {1, 1}}},
+			err: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type tcase struct {
+		polyline string
+		opts     polyline.Options
+		expected geom.LineString
+		err      bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := polyline.Decode(tc.polyline, tc.opts)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("line string, expected %v got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"line string": {
+			polyline: "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+			expected: geom.LineString{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}},
+		},
+		"lng lat": {
+			polyline: "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+			opts:     polyline.Options{Order: polyline.LngLat},
+			expected: geom.LineString{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}},
+		},
+		"precision 6": {
+			polyline: "_izlhA~rlgdF_{geC~ywl@",
+			opts:     polyline.Options{Precision: precision(6)},
+			expected: geom.LineString{{-120.2, 38.5}, {-120.95, 40.7}},
+		},
+		"precision 0": {
+			polyline: "mAnFC@",
+			opts:     polyline.Options{Precision: precision(0)},
+			expected: geom.LineString{{-120, 39}, {-121, 41}},
+		},
+		"empty": {
+			polyline: "",
+			expected: geom.LineString{},
+		},
+		"odd number of values": {
+			polyline: "_p~iF~ps|U_ulL",
+			err:      true,
+		},
+		"truncated value": {
+			polyline: "_p~iF~ps|",
+			err:      true,
+		},
+		"invalid character": {
+			polyline: "_p~iF ps|U",
+			err:      true,
+		},
+		"delete character": {
+			polyline: "?\x7f?",
+			err:      true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestDecodeZ(t *testing.T) {
+	got, err := polyline.DecodeZ("_p~iF~ps|UgE_ulLnnqCR", polyline.Options{})
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	expected := geom.LineStringZ{{-120.2, 38.5, 100}, {-120.95, 40.7, 90}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("line string, expected %v got %v", expected, got)
+	}
+}
@@ -0,0 +1,319 @@
+package shapefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of the values of a field of the .dbf file
+type FieldType byte
+
+// field types and the Go types of their values
+const (
+	Character FieldType = 'C' // string
+	Numeric   FieldType = 'N' // int64 without decimals, otherwise float64
+	Float     FieldType = 'F' // float64
+	Logical   FieldType = 'L' // bool
+	Date      FieldType = 'D' // time.Time
+)
+
+// Field describes the values of an attribute
+type Field struct {
+	// Name is at most 10 bytes
+	Name string
+	Type FieldType
+	// Length is the number of bytes of the values
+	Length uint8
+	// Decimals is the number of digits after the decimal point of numeric values
+	Decimals uint8
+}
+
+// dbf constants
+const (
+	dbfVersion     = 0x03
+	dbfHeaderEnd   = 0x0d
+	dbfFileEnd     = 0x1a
+	dbfFieldSize   = 32
+	dbfNameSize    = 11
+	dbfNotDeleted  = ' '
+	dbfMaxFieldLen = 254
+)
+
+// dbfReader reads the records of a .dbf file
+type dbfReader struct {
+	r          *bufio.Reader
+	fields     []Field
+	numRecords uint32
+	recordSize int
+	read       uint32
+}
+
+func newDBFReader(r io.Reader) (*dbfReader, error) {
+	br := bufio.NewReader(r)
+	var hdr [32]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		return nil, err
+	}
+	d := &dbfReader{
+		r:          br,
+		numRecords: binary.LittleEndian.Uint32(hdr[4:]),
+		recordSize: int(binary.LittleEndian.Uint16(hdr[10:])),
+	}
+	headerLen := int(binary.LittleEndian.Uint16(hdr[8:]))
+
+	read := len(hdr)
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] == dbfHeaderEnd {
+			break
+		}
+		var fd [dbfFieldSize]byte
+		if _, err := io.ReadFull(br, fd[:]); err != nil {
+			return nil, err
+		}
+		read += dbfFieldSize
+		name := fd[:dbfNameSize]
+		if i := bytes.IndexByte(name, 0); i >= 0 {
+			name = name[:i]
+		}
+		d.fields = append(d.fields, Field{
+			Name:     string(name),
+			Type:     FieldType(fd[11]),
+			Length:   fd[16],
+			Decimals: fd[17],
+		})
+	}
+	// the rest of the header, starting with the terminator
+	if headerLen > read {
+		if _, err := io.CopyN(ioutil.Discard, br, int64(headerLen-read)); err != nil {
+			return nil, err
+		}
+	}
+
+	size := 1
+	for _, f := range d.fields {
+		size += int(f.Length)
+	}
+	if size != d.recordSize {
+		return nil, fmt.Errorf("record size %v differs from the size %v of the fields", d.recordSize, size)
+	}
+	return d, nil
+}
+
+// next returns the attributes of the next record, io.EOF after the last record
+func (d *dbfReader) next() (map[string]interface{}, error) {
+	if d.read >= d.numRecords {
+		return nil, io.EOF
+	}
+	rec := make([]byte, d.recordSize)
+	if _, err := io.ReadFull(d.r, rec); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	d.read++
+
+	props := make(map[string]interface{}, len(d.fields))
+	pos := 1
+	for _, f := range d.fields {
+		v, err := decodeValue(f, rec[pos:pos+int(f.Length)])
+		if err != nil {
+			return nil, err
+		}
+		pos += int(f.Length)
+		if v != nil {
+			props[f.Name] = v
+		}
+	}
+	return props, nil
+}
+
+// decodeValue returns the value of the field, nil if it is blank
+func decodeValue(f Field, b []byte) (interface{}, error) {
+	s := strings.TrimRight(string(b), " \x00")
+	if f.Type != Character {
+		s = strings.TrimSpace(s)
+	}
+	if s == "" {
+		return nil, nil
+	}
+
+	switch f.Type {
+	case Numeric, Float:
+		if strings.Trim(s, "*") == "" {
+			// the value did not fit the field
+			return nil, nil
+		}
+		if f.Type == Numeric && f.Decimals == 0 {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q of field %q", s, f.Name)
+		}
+		return v, nil
+
+	case Logical:
+		switch s {
+		case "T", "t", "Y", "y":
+			return true, nil
+		case "F", "f", "N", "n":
+			return false, nil
+		}
+		return nil, nil
+
+	case Date:
+		if strings.Trim(s, "0") == "" {
+			return nil, nil
+		}
+		t, err := time.Parse("20060102", s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q of field %q", s, f.Name)
+		}
+		return t, nil
+
+	default:
+		return s, nil
+	}
+}
+
+// encodeDBF writes the .dbf file of the attributes of the features
+func encodeDBF(w io.Writer, fields []Field, features []Feature, modified time.Time) error {
+	recordSize := 1
+	for _, f := range fields {
+		if len(f.Name) == 0 || len(f.Name) >= dbfNameSize {
+			return fmt.Errorf("invalid field name %q", f.Name)
+		}
+		if f.Length == 0 || f.Length > dbfMaxFieldLen {
+			return fmt.Errorf("invalid length %v of field %q", f.Length, f.Name)
+		}
+		recordSize += int(f.Length)
+	}
+	if uint64(len(features)) > math.MaxUint32 {
+		return fmt.Errorf("too many records %v", len(features))
+	}
+	headerLen := 32 + dbfFieldSize*len(fields) + 1
+	if headerLen > math.MaxUint16 || recordSize > math.MaxUint16 {
+		return fmt.Errorf("too many fields %v", len(fields))
+	}
+
+	var buf bytes.Buffer
+	hdr := make([]byte, 32)
+	hdr[0] = dbfVersion
+	hdr[1], hdr[2], hdr[3] = byte(modified.Year()-1900), byte(modified.Month()), byte(modified.Day())
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(len(features)))
+	binary.LittleEndian.PutUint16(hdr[8:], uint16(headerLen))
+	binary.LittleEndian.PutUint16(hdr[10:], uint16(recordSize))
+	buf.Write(hdr)
+	for _, f := range fields {
+		fd := make([]byte, dbfFieldSize)
+		copy(fd, f.Name)
+		fd[11] = byte(f.Type)
+		fd[16], fd[17] = f.Length, f.Decimals
+		buf.Write(fd)
+	}
+	buf.WriteByte(dbfHeaderEnd)
+
+	for _, feat := range features {
+		for name := range feat.Properties {
+			if fieldIndex(fields, name) < 0 {
+				return fmt.Errorf("unknown field %q", name)
+			}
+		}
+		buf.WriteByte(dbfNotDeleted)
+		for _, f := range fields {
+			v := feat.Properties[f.Name]
+			s, ok := encodeValue(f, v)
+			if !ok || len(s) > int(f.Length) {
+				return ErrInvalidValue{Field: f.Name, Value: v}
+			}
+			// numbers are right aligned, everything else left aligned
+			pad := strings.Repeat(" ", int(f.Length)-len(s))
+			if f.Type == Numeric || f.Type == Float {
+				s = pad + s
+			} else {
+				s += pad
+			}
+			buf.WriteString(s)
+		}
+	}
+	buf.WriteByte(dbfFileEnd)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func fieldIndex(fields []Field, name string) int {
+	for i := range fields {
+		if fields[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// encodeValue returns the text of the value for the field
+func encodeValue(f Field, v interface{}) (string, bool) {
+	if v == nil {
+		if f.Type == Logical {
+			return "?", true
+		}
+		return "", true
+	}
+	rv := reflect.ValueOf(v)
+
+	switch f.Type {
+	case Character:
+		s, ok := v.(string)
+		return s, ok
+
+	case Numeric, Float:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if f.Decimals == 0 {
+				return strconv.FormatInt(rv.Int(), 10), true
+			}
+			return strconv.FormatFloat(float64(rv.Int()), 'f', int(f.Decimals), 64), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if f.Decimals == 0 {
+				return strconv.FormatUint(rv.Uint(), 10), true
+			}
+			return strconv.FormatFloat(float64(rv.Uint()), 'f', int(f.Decimals), 64), true
+		case reflect.Float32, reflect.Float64:
+			return strconv.FormatFloat(rv.Float(), 'f', int(f.Decimals), 64), true
+		}
+		return "", false
+
+	case Logical:
+		b, ok := v.(bool)
+		if !ok {
+			return "", false
+		}
+		if b {
+			return "T", true
+		}
+		return "F", true
+
+	case Date:
+		t, ok := v.(time.Time)
+		if !ok {
+			return "", false
+		}
+		return t.Format("20060102"), true
+	}
+	return "", false
+}
@@ -0,0 +1,142 @@
+package shapefile
+
+import (
+	"strconv"
+	"strings"
+)
+
+// esriSRIDs are the SRIDs of the names of common coordinate systems in the .prj files
+// written by ESRI software, which have no authority
+var esriSRIDs = map[string]uint32{
+	"GCS_WGS_1984":                           4326,
+	"WGS 84":                                 4326,
+	"GCS_North_American_1983":                4269,
+	"GCS_North_American_1927":                4267,
+	"GCS_ETRS_1989":                          4258,
+	"WGS_1984_Web_Mercator_Auxiliary_Sphere": 3857,
+	"WGS_84_Pseudo_Mercator":                 3857,
+	"WGS 84 / Pseudo-Mercator":               3857,
+	"ETRS_1989_LAEA":                         3035,
+	"NAD_1983_Contiguous_USA_Albers":         5070,
+	"WGS_1984_World_Mercator":                3395,
+	"ETRS_1989_UTM_Zone_32N":                 25832,
+	"ETRS_1989_UTM_Zone_33N":                 25833,
+	"British_National_Grid":                  27700,
+	"OSGB_1936_British_National_Grid":        27700,
+	"RGF_1993_Lambert_93":                    2154,
+	"GDA_1994_MGA_Zone_55":                   28355,
+}
+
+// SRID returns the SRID of the WKT of a .prj file: the EPSG code of the authority of
+// the coordinate system or, for .prj files without one, the code of a few well known
+// names including the UTM zones of WGS 84.
+func SRID(prj string) (uint32, bool) {
+	name, authority, code := parseWKT(prj)
+	if strings.EqualFold(authority, "EPSG") {
+		if srid, err := strconv.ParseUint(code, 10, 32); err == nil {
+			return uint32(srid), true
+		}
+	}
+	if srid, ok := esriSRIDs[name]; ok {
+		return srid, true
+	}
+
+	// WGS_1984_UTM_Zone_33N and WGS 84 / UTM zone 33N
+	upper := strings.ToUpper(strings.NewReplacer("_", " ", "/", " ").Replace(name))
+	fields := strings.Fields(upper)
+	if len(fields) == 5 && (fields[0]+fields[1] == "WGS1984" || fields[0]+fields[1] == "WGS84") &&
+		fields[2] == "UTM" && fields[3] == "ZONE" {
+		zone := fields[4]
+		if len(zone) < 2 {
+			return 0, false
+		}
+		n, err := strconv.Atoi(zone[:len(zone)-1])
+		if err != nil || n < 1 || n > 60 {
+			return 0, false
+		}
+		switch zone[len(zone)-1] {
+		case 'N':
+			return uint32(32600 + n), true
+		case 'S':
+			return uint32(32700 + n), true
+		}
+	}
+	return 0, false
+}
+
+// parseWKT returns the name of the outermost object of the WKT, and the authority and
+// code of its AUTHORITY or ID
+func parseWKT(wkt string) (name, authority, code string) {
+	var (
+		depth   int
+		keyword strings.Builder
+		// the keyword and values of the object at depth 2
+		inner  string
+		values []string
+	)
+	for i := 0; i < len(wkt); i++ {
+		c := wkt[i]
+		switch {
+		case c == '"':
+			end := strings.IndexByte(wkt[i+1:], '"')
+			if end < 0 {
+				return name, authority, code
+			}
+			s := wkt[i+1 : i+1+end]
+			i += end + 1
+			switch depth {
+			case 1:
+				if name == "" {
+					name = s
+				}
+			case 2:
+				values = append(values, s)
+			}
+
+		case c == '[' || c == '(':
+			depth++
+			if depth == 2 {
+				inner = strings.ToUpper(strings.TrimSpace(keyword.String()))
+				values = values[:0]
+			}
+			keyword.Reset()
+
+		case c == ']' || c == ')':
+			if depth == 2 && (inner == "AUTHORITY" || inner == "ID") {
+				// an unquoted code follows the last comma
+				if rest := strings.TrimSpace(keyword.String()); rest != "" {
+					values = append(values, rest)
+				}
+				if len(values) >= 2 {
+					authority, code = values[0], values[1]
+				}
+			}
+			depth--
+			keyword.Reset()
+
+		case c == ',':
+			if depth == 2 {
+				if rest := strings.TrimSpace(keyword.String()); rest != "" {
+					values = append(values, rest)
+				}
+			}
+			keyword.Reset()
+
+		default:
+			keyword.WriteByte(c)
+		}
+	}
+	return name, authority, code
+}
+
+// PRJ returns the WKT of the .prj file of the SRID, for the SRIDs of web maps: 4326
+// and 3857.
+func PRJ(srid uint32) (string, bool) {
+	prj, ok := prjs[srid]
+	return prj, ok
+}
+
+var prjs = map[uint32]string{
+	4326: `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`,
+	3857: `PROJCS["WGS_1984_Web_Mercator_Auxiliary_Sphere",GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]],PROJECTION["Mercator_Auxiliary_Sphere"],PARAMETER["False_Easting",0.0],PARAMETER["False_Northing",0.0],PARAMETER["Central_Meridian",0.0],PARAMETER["Standard_Parallel_1",0.0],PARAMETER["Auxiliary_Sphere_Type",0.0],UNIT["Meter",1.0]]`,
+}
@@ -0,0 +1,247 @@
+package shapefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hahaking119/geom"
+)
+
+// Reader reads the records of a .shp file and the attributes of a .dbf file one at a time
+type Reader struct {
+	Header Header
+	// Fields are the fields of the .dbf file, nil without one
+	Fields []Field
+
+	shp *bufio.Reader
+	dbf *dbfReader
+	// pos is the number of bytes read of the .shp file
+	pos int64
+}
+
+// NewReader reads the headers of the .shp and .dbf files; dbf may be nil
+func NewReader(shp, dbf io.Reader) (*Reader, error) {
+	r := &Reader{shp: bufio.NewReader(shp), pos: headerSize}
+	var err error
+	if r.Header, err = readHeader(r.shp); err != nil {
+		return nil, err
+	}
+	if dbf != nil {
+		if r.dbf, err = newDBFReader(dbf); err != nil {
+			return nil, err
+		}
+		r.Fields = r.dbf.fields
+	}
+	return r, nil
+}
+
+// Next returns the next feature, or io.EOF after the last one
+func (r *Reader) Next() (Feature, error) {
+	if r.pos >= r.Header.FileLength {
+		return Feature{}, io.EOF
+	}
+	var rh [8]byte
+	if _, err := io.ReadFull(r.shp, rh[:]); err != nil {
+		return Feature{}, err
+	}
+	content, err := readBytes(r.shp, 2*int64(binary.BigEndian.Uint32(rh[4:])))
+	if err != nil {
+		return Feature{}, err
+	}
+	r.pos += int64(len(rh) + len(content))
+
+	var f Feature
+	if f.Geometry, err = decodeShape(content); err != nil {
+		return Feature{}, fmt.Errorf("record %v: %v", binary.BigEndian.Uint32(rh[:]), err)
+	}
+	if r.dbf != nil {
+		if f.Properties, err = r.dbf.next(); err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("fewer attribute records than shapes")
+			}
+			return Feature{}, err
+		}
+	}
+	return f, nil
+}
+
+// readBytes returns the next n bytes, which are read as they come so a corrupt
+// length does not allocate them all up front
+func readBytes(r io.Reader, n int64) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, n); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readHeader reads the header of a .shp or .shx file
+func readHeader(r io.Reader) (Header, error) {
+	var b [headerSize]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return Header{}, err
+	}
+	if code := int32(binary.BigEndian.Uint32(b[0:])); code != fileCode {
+		return Header{}, ErrInvalidFileCode(code)
+	}
+	f := func(i int) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b[36+8*i:])) }
+	return Header{
+		Type:       ShapeType(binary.LittleEndian.Uint32(b[32:])),
+		FileLength: 2 * int64(binary.BigEndian.Uint32(b[24:])),
+		BBox:       geom.Extent{f(0), f(1), f(2), f(3)},
+		ZRange:     [2]float64{f(4), f(5)},
+		MRange:     [2]float64{f(6), f(7)},
+	}, nil
+}
+
+// IndexRecord is the position of a record of the .shp file
+type IndexRecord struct {
+	// Offset is the offset of the record in bytes
+	Offset int64
+	// Length is the length of the content of the record in bytes, without the record header
+	Length int64
+}
+
+// ReadIndex reads the records of a .shx file
+func ReadIndex(shx io.Reader) (Header, []IndexRecord, error) {
+	br := bufio.NewReader(shx)
+	hdr, err := readHeader(br)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	var recs []IndexRecord
+	for pos := int64(headerSize); pos < hdr.FileLength; pos += 8 {
+		var b [8]byte
+		if _, err := io.ReadFull(br, b[:]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return Header{}, nil, err
+		}
+		recs = append(recs, IndexRecord{
+			Offset: 2 * int64(binary.BigEndian.Uint32(b[0:])),
+			Length: 2 * int64(binary.BigEndian.Uint32(b[4:])),
+		})
+	}
+	return hdr, recs, nil
+}
+
+// ReadRecordAt returns the geometry of the record of the .shp file
+func ReadRecordAt(shp io.ReaderAt, rec IndexRecord) (geom.Geometry, error) {
+	content, err := readBytes(io.NewSectionReader(shp, rec.Offset+8, rec.Length), rec.Length)
+	if err != nil {
+		return nil, err
+	}
+	return decodeShape(content)
+}
+
+// File is the contents of a shapefile
+type File struct {
+	Header Header
+	// Fields are the fields of the .dbf file, nil without one
+	Fields   []Field
+	Features []Feature
+	// Projection is the text of the .prj file, empty without one
+	Projection string
+	// SRID is the SRID of the projection, 0 if it is not known
+	SRID uint32
+}
+
+// ReadFile reads the shapefile at the path, with or without the .shp extension. The
+// .shx file, if there is one, is used to find the records of the .shp file; the .dbf
+// and .prj files are optional.
+func ReadFile(path string) (*File, error) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	shp, err := openSibling(base, ".shp")
+	if err != nil {
+		return nil, err
+	}
+	defer shp.Close()
+
+	var dbf io.Reader
+	if f, err := openSibling(base, ".dbf"); err == nil {
+		defer f.Close()
+		dbf = f
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	file := new(File)
+	if f, err := openSibling(base, ".prj"); err == nil {
+		prj, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		file.Projection = strings.TrimSpace(string(prj))
+		file.SRID, _ = SRID(file.Projection)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	r, err := NewReader(shp, dbf)
+	if err != nil {
+		return nil, err
+	}
+	file.Header, file.Fields = r.Header, r.Fields
+
+	shx, err := openSibling(base, ".shx")
+	switch {
+	case err == nil:
+		defer shx.Close()
+		_, recs, err := ReadIndex(shx)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range recs {
+			var f Feature
+			if f.Geometry, err = ReadRecordAt(shp, rec); err != nil {
+				return nil, err
+			}
+			if r.dbf != nil {
+				if f.Properties, err = r.dbf.next(); err != nil {
+					return nil, err
+				}
+			}
+			file.Features = append(file.Features, f)
+		}
+		return file, nil
+
+	case os.IsNotExist(err):
+		for {
+			f, err := r.Next()
+			if err == io.EOF {
+				return file, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			file.Features = append(file.Features, f)
+		}
+
+	default:
+		return nil, err
+	}
+}
+
+// openSibling opens the file with the base path and extension, in lower or upper case
+func openSibling(base, ext string) (*os.File, error) {
+	f, err := os.Open(base + ext)
+	if os.IsNotExist(err) {
+		if uf, uerr := os.Open(base + strings.ToUpper(ext)); uerr == nil {
+			return uf, nil
+		}
+	}
+	return f, err
+}
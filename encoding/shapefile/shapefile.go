@@ -0,0 +1,170 @@
+// Package shapefile implements reading and writing of ESRI shapefiles as described at
+// https://www.esri.com/content/dam/esrisites/sitecore-archive/Files/Pdfs/library/whitepapers/pdfs/shapefile.pdf.
+// A shapefile is a .shp file with the geometries, a .shx file with the offsets of
+// the records of the .shp file, a .dbf file with the attributes and an optional .prj
+// file with the projection.
+//
+// Records are decoded to geom types: points, line strings or multi line strings,
+// polygons or multi polygons, and multi points, with the Z and M variants. The outer
+// rings of polygons are clockwise and their holes counter clockwise; as there is no
+// multi polygon with Z or M values, several of those polygons are decoded as a
+// geom.Collection. MultiPatch records are decoded as polygons, with each triangle of
+// the triangle strips and fans a polygon of its own.
+package shapefile
+
+import (
+	"fmt"
+
+	"github.com/hahaking119/geom"
+)
+
+// ShapeType is the type of the shapes of a shapefile
+type ShapeType int32
+
+// shape types
+const (
+	Null        ShapeType = 0
+	Point       ShapeType = 1
+	PolyLine    ShapeType = 3
+	Polygon     ShapeType = 5
+	MultiPoint  ShapeType = 8
+	PointZ      ShapeType = 11
+	PolyLineZ   ShapeType = 13
+	PolygonZ    ShapeType = 15
+	MultiPointZ ShapeType = 18
+	PointM      ShapeType = 21
+	PolyLineM   ShapeType = 23
+	PolygonM    ShapeType = 25
+	MultiPointM ShapeType = 28
+	MultiPatch  ShapeType = 31
+)
+
+func (t ShapeType) String() string {
+	switch t {
+	case Null:
+		return "Null"
+	case Point:
+		return "Point"
+	case PolyLine:
+		return "PolyLine"
+	case Polygon:
+		return "Polygon"
+	case MultiPoint:
+		return "MultiPoint"
+	case PointZ:
+		return "PointZ"
+	case PolyLineZ:
+		return "PolyLineZ"
+	case PolygonZ:
+		return "PolygonZ"
+	case MultiPointZ:
+		return "MultiPointZ"
+	case PointM:
+		return "PointM"
+	case PolyLineM:
+		return "PolyLineM"
+	case PolygonM:
+		return "PolygonM"
+	case MultiPointM:
+		return "MultiPointM"
+	case MultiPatch:
+		return "MultiPatch"
+	default:
+		return fmt.Sprintf("ShapeType(%d)", int32(t))
+	}
+}
+
+// hasZ is whether the shapes have z values, and optionally m values
+func (t ShapeType) hasZ() bool {
+	switch t {
+	case PointZ, PolyLineZ, PolygonZ, MultiPointZ, MultiPatch:
+		return true
+	}
+	return false
+}
+
+// hasM is whether the shapes have m values
+func (t ShapeType) hasM() bool {
+	switch t {
+	case PointM, PolyLineM, PolygonM, MultiPointM:
+		return true
+	}
+	return false
+}
+
+// base returns the 2D shape type
+func (t ShapeType) base() ShapeType {
+	switch t {
+	case PointZ, PointM:
+		return Point
+	case PolyLineZ, PolyLineM:
+		return PolyLine
+	case PolygonZ, PolygonM:
+		return Polygon
+	case MultiPointZ, MultiPointM:
+		return MultiPoint
+	}
+	return t
+}
+
+// fileCode is the first value of the .shp and .shx files
+const fileCode = 9994
+
+// version is the version of the .shp and .shx files
+const version = 1000
+
+// headerSize is the size of the header of the .shp and .shx files
+const headerSize = 100
+
+// noData is the largest m value that is not a measure, any smaller value is "no data"
+const noData = -1e38
+
+// Header is the header of the .shp and .shx files
+type Header struct {
+	Type ShapeType
+	// FileLength is the length of the file in bytes
+	FileLength int64
+	// BBox is the extent of the x and y values
+	BBox           geom.Extent
+	ZRange, MRange [2]float64
+}
+
+// Feature is a geometry and its attributes, keyed by field name
+type Feature struct {
+	Geometry   geom.Geometry
+	Properties map[string]interface{}
+}
+
+// ErrInvalidFileCode is returned when reading a .shp or .shx file with the wrong
+// file code
+type ErrInvalidFileCode int32
+
+func (e ErrInvalidFileCode) Error() string {
+	return fmt.Sprintf("invalid file code %v", int32(e))
+}
+
+// ErrUnknownShapeType is returned when reading a record of an unknown shape type
+type ErrUnknownShapeType ShapeType
+
+func (e ErrUnknownShapeType) Error() string {
+	return fmt.Sprintf("unknown shape type %v", int32(e))
+}
+
+// ErrMixedShapeTypes is returned when writing geometries of different shape types
+type ErrMixedShapeTypes struct {
+	Expected, Got ShapeType
+}
+
+func (e ErrMixedShapeTypes) Error() string {
+	return fmt.Sprintf("shape type %v differs from the shape type %v of the file", e.Got, e.Expected)
+}
+
+// ErrInvalidValue is returned when writing an attribute value that does not fit its field
+type ErrInvalidValue struct {
+	Field string
+	Value interface{}
+}
+
+func (e ErrInvalidValue) Error() string {
+	return fmt.Sprintf("invalid value %v (%T) for field %q", e.Value, e.Value, e.Field)
+}
@@ -0,0 +1,428 @@
+package shapefile_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding/shapefile"
+)
+
+func TestRoundTrip(t *testing.T) {
+	type tcase struct {
+		geoms []geom.Geometry
+		typ   shapefile.ShapeType
+		// expected are the decoded geometries, if different from geoms
+		expected []geom.Geometry
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			features := make([]shapefile.Feature, len(tc.geoms))
+			for i, g := range tc.geoms {
+				features[i] = shapefile.Feature{Geometry: g}
+			}
+			var shp, shx bytes.Buffer
+			if err := shapefile.Encode(&shp, &shx, nil, nil, features); err != nil {
+				t.Fatalf("encode error, expected nil got %v", err)
+			}
+			r, err := shapefile.NewReader(&shp, nil)
+			if err != nil {
+				t.Fatalf("reader error, expected nil got %v", err)
+			}
+			if r.Header.Type != tc.typ {
+				t.Errorf("shape type, expected %v got %v", tc.typ, r.Header.Type)
+			}
+
+			expected := tc.expected
+			if expected == nil {
+				expected = tc.geoms
+			}
+			var got []geom.Geometry
+			for {
+				f, err := r.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("next error, expected nil got %v", err)
+				}
+				got = append(got, f.Geometry)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("geometries, expected %v got %v", expected, got)
+			}
+
+			_, recs, err := shapefile.ReadIndex(&shx)
+			if err != nil {
+				t.Fatalf("index error, expected nil got %v", err)
+			}
+			if len(recs) != len(expected) {
+				t.Errorf("index records, expected %v got %v", len(expected), len(recs))
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			geoms: []geom.Geometry{geom.Point{1, 2}, nil, geom.Point{3, 4}},
+			typ:   shapefile.Point,
+		},
+		"point z": {
+			geoms: []geom.Geometry{geom.PointZ{1, 2, 3}},
+			typ:   shapefile.PointZ,
+		},
+		"point zm": {
+			geoms: []geom.Geometry{geom.PointZM{1, 2, 3, 4}},
+			typ:   shapefile.PointZ,
+		},
+		"point m": {
+			geoms: []geom.Geometry{geom.PointM{1, 2, 4}},
+			typ:   shapefile.PointM,
+		},
+		"multi point": {
+			geoms: []geom.Geometry{geom.MultiPoint{{1, 2}, {3, 4}}},
+			typ:   shapefile.MultiPoint,
+		},
+		"multi point m": {
+			geoms: []geom.Geometry{geom.MultiPointM{{1, 2, 3}, {3, 4, 5}}},
+			typ:   shapefile.MultiPointM,
+		},
+		"line string": {
+			geoms: []geom.Geometry{geom.LineString{{1, 2}, {3, 4}}},
+			typ:   shapefile.PolyLine,
+		},
+		"line string z": {
+			geoms: []geom.Geometry{geom.LineStringZ{{1, 2, 3}, {3, 4, 5}}},
+			typ:   shapefile.PolyLineZ,
+		},
+		"multi line string zm": {
+			geoms: []geom.Geometry{geom.MultiLineStringZM{{{1, 2, 3, 4}, {3, 4, 5, 6}}, {{0, 0, 0, 0}, {1, 1, 1, 1}}}},
+			typ:   shapefile.PolyLineZ,
+		},
+		"multi line string m": {
+			geoms: []geom.Geometry{geom.MultiLineStringM{{{1, 2, 4}, {3, 4, 6}}, {{0, 0, 0}, {1, 1, 1}}}},
+			typ:   shapefile.PolyLineM,
+		},
+		"empty line string": {
+			geoms:    []geom.Geometry{geom.LineString{}, geom.LineString{{1, 2}, {3, 4}}},
+			typ:      shapefile.PolyLine,
+			expected: []geom.Geometry{nil, geom.LineString{{1, 2}, {3, 4}}},
+		},
+		"polygon": {
+			geoms: []geom.Geometry{
+				geom.Polygon{
+					{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+					{{2, 2}, {4, 2}, {4, 4}, {2, 4}},
+				},
+			},
+			typ: shapefile.Polygon,
+		},
+		"polygon reoriented": {
+			geoms: []geom.Geometry{
+				geom.Polygon{
+					{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+					{{2, 2}, {2, 4}, {4, 4}, {4, 2}},
+				},
+			},
+			typ: shapefile.Polygon,
+			expected: []geom.Geometry{
+				geom.Polygon{
+					{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+					{{2, 2}, {4, 2}, {4, 4}, {2, 4}},
+				},
+			},
+		},
+		"multi polygon": {
+			geoms: []geom.Geometry{
+				geom.MultiPolygon{
+					{{{0, 0}, {0, 1}, {1, 1}, {1, 0}}},
+					{
+						{{5, 5}, {5, 9}, {9, 9}, {9, 5}},
+						{{6, 6}, {7, 6}, {7, 7}, {6, 7}},
+					},
+				},
+			},
+			typ: shapefile.Polygon,
+		},
+		"polygon z": {
+			geoms: []geom.Geometry{geom.PolygonZ{{{0, 0, 1}, {0, 1, 2}, {1, 1, 3}, {1, 0, 4}}}},
+			typ:   shapefile.PolygonZ,
+		},
+		"polygons zm": {
+			geoms: []geom.Geometry{
+				geom.Collection{
+					geom.PolygonZM{{{0, 0, 1, 1}, {0, 1, 2, 1}, {1, 1, 3, 1}}},
+					geom.PolygonZM{{{5, 5, 1, 1}, {5, 6, 2, 1}, {6, 6, 3, 1}}},
+				},
+			},
+			typ: shapefile.PolygonZ,
+		},
+		"polygon m": {
+			geoms: []geom.Geometry{geom.PolygonM{{{0, 0, 1}, {0, 1, 2}, {1, 1, 3}, {1, 0, 4}}}},
+			typ:   shapefile.PolygonM,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	type tcase struct {
+		fields   []shapefile.Field
+		features []shapefile.Feature
+		err      error
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			var shp, shx, dbf bytes.Buffer
+			err := shapefile.Encode(&shp, &shx, &dbf, tc.fields, tc.features)
+			if !reflect.DeepEqual(err, tc.err) {
+				t.Errorf("error, expected %v got %v", tc.err, err)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"mixed shape types": {
+			features: []shapefile.Feature{
+				{Geometry: geom.Point{1, 2}},
+				{Geometry: geom.LineString{{1, 2}, {3, 4}}},
+			},
+			err: shapefile.ErrMixedShapeTypes{Expected: shapefile.Point, Got: shapefile.PolyLine},
+		},
+		"value too long": {
+			fields:   []shapefile.Field{{Name: "name", Type: shapefile.Character, Length: 3}},
+			features: []shapefile.Feature{{Properties: map[string]interface{}{"name": "abcd"}}},
+			err:      shapefile.ErrInvalidValue{Field: "name", Value: "abcd"},
+		},
+		"invalid value": {
+			fields:   []shapefile.Field{{Name: "count", Type: shapefile.Numeric, Length: 3}},
+			features: []shapefile.Feature{{Properties: map[string]interface{}{"count": "a"}}},
+			err:      shapefile.ErrInvalidValue{Field: "count", Value: "a"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shapefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fields := []shapefile.Field{
+		{Name: "name", Type: shapefile.Character, Length: 10},
+		{Name: "count", Type: shapefile.Numeric, Length: 5},
+		{Name: "ratio", Type: shapefile.Numeric, Length: 8, Decimals: 3},
+		{Name: "value", Type: shapefile.Float, Length: 12, Decimals: 4},
+		{Name: "valid", Type: shapefile.Logical, Length: 1},
+		{Name: "date", Type: shapefile.Date, Length: 8},
+	}
+	features := []shapefile.Feature{
+		{
+			Geometry: geom.Point{1, 2},
+			Properties: map[string]interface{}{
+				"name":  "één",
+				"count": int64(-12),
+				"ratio": 0.125,
+				"value": 1234.5,
+				"valid": true,
+				"date":  time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			Geometry:   geom.Point{3, 4},
+			Properties: map[string]interface{}{"name": "two", "valid": false},
+		},
+		{
+			Properties: map[string]interface{}{},
+		},
+	}
+	prj, _ := shapefile.PRJ(3857)
+
+	path := filepath.Join(dir, "test.shp")
+	if err := shapefile.WriteFile(path, fields, features, prj); err != nil {
+		t.Fatalf("write error, expected nil got %v", err)
+	}
+	file, err := shapefile.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read error, expected nil got %v", err)
+	}
+	if !reflect.DeepEqual(file.Fields, fields) {
+		t.Errorf("fields, expected %v got %v", fields, file.Fields)
+	}
+	if !reflect.DeepEqual(file.Features, features) {
+		t.Errorf("features, expected %v got %v", features, file.Features)
+	}
+	if file.Projection != prj || file.SRID != 3857 {
+		t.Errorf("projection, expected %v and 3857 got %v and %v", prj, file.Projection, file.SRID)
+	}
+	if expected := (geom.Extent{1, 2, 3, 4}); file.Header.BBox != expected {
+		t.Errorf("bbox, expected %v got %v", expected, file.Header.BBox)
+	}
+
+	// without the .shx file the records are read one after the other
+	if err := os.Remove(filepath.Join(dir, "test.shx")); err != nil {
+		t.Fatal(err)
+	}
+	file, err = shapefile.ReadFile(filepath.Join(dir, "test"))
+	if err != nil {
+		t.Fatalf("read error, expected nil got %v", err)
+	}
+	if !reflect.DeepEqual(file.Features, features) {
+		t.Errorf("features, expected %v got %v", features, file.Features)
+	}
+}
+
+func TestSRID(t *testing.T) {
+	type tcase struct {
+		prj      string
+		expected uint32
+		ok       bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, ok := shapefile.SRID(tc.prj)
+			if got != tc.expected || ok != tc.ok {
+				t.Errorf("srid, expected %v %v got %v %v", tc.expected, tc.ok, got, ok)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"authority": {
+			prj:      `PROJCS["NAD83 / UTM zone 15N",GEOGCS["NAD83",DATUM["North_American_Datum_1983",SPHEROID["GRS 1980",6378137,298.257222101,AUTHORITY["EPSG","7019"]],AUTHORITY["EPSG","6269"]],AUTHORITY["EPSG","4269"]],UNIT["metre",1,AUTHORITY["EPSG","9001"]],AUTHORITY["EPSG","26915"]]`,
+			expected: 26915,
+			ok:       true,
+		},
+		"wkt2 id": {
+			prj:      `GEOGCRS["WGS 84",DATUM["World Geodetic System 1984",ELLIPSOID["WGS 84",6378137,298.257223563]],CS[ellipsoidal,2],ID["EPSG",4326]]`,
+			expected: 4326,
+			ok:       true,
+		},
+		"esri": {
+			prj:      `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`,
+			expected: 4326,
+			ok:       true,
+		},
+		"esri utm": {
+			prj:      `PROJCS["WGS_1984_UTM_Zone_33S",GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]]],PROJECTION["Transverse_Mercator"]]`,
+			expected: 32733,
+			ok:       true,
+		},
+		"unknown": {
+			prj: `PROJCS["Local",GEOGCS["Local"]]`,
+		},
+		"empty": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestReadFileTestdata(t *testing.T) {
+	type tcase struct {
+		path     string
+		typ      shapefile.ShapeType
+		fields   []shapefile.Field
+		expected []shapefile.Feature
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			file, err := shapefile.ReadFile(tc.path)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if file.Header.Type != tc.typ {
+				t.Errorf("type, expected %v got %v", tc.typ, file.Header.Type)
+			}
+			if !reflect.DeepEqual(file.Fields, tc.fields) {
+				t.Errorf("fields, expected %v got %v", tc.fields, file.Fields)
+			}
+			if len(file.Features) != len(tc.expected) {
+				t.Fatalf("features, expected %v got %v", len(tc.expected), len(file.Features))
+			}
+			for i := range tc.expected {
+				if !reflect.DeepEqual(file.Features[i], tc.expected[i]) {
+					t.Errorf("feature %v, expected %v got %v", i, tc.expected[i], file.Features[i])
+				}
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		// outer rings are clockwise and holes counter clockwise; the hole of the
+		// second record comes before its outer ring
+		"holes": {
+			path: filepath.Join("testdata", "holes.shp"),
+			typ:  shapefile.Polygon,
+			fields: []shapefile.Field{
+				{Name: "NAME", Type: shapefile.Character, Length: 16},
+				{Name: "RINGS", Type: shapefile.Numeric, Length: 4},
+				{Name: "AREA", Type: shapefile.Numeric, Length: 12, Decimals: 3},
+			},
+			expected: []shapefile.Feature{
+				{
+					Geometry: geom.Polygon{
+						{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+						{{2, 2}, {4, 2}, {4, 4}, {2, 4}},
+					},
+					Properties: map[string]interface{}{"NAME": "square", "RINGS": int64(2), "AREA": 96.0},
+				},
+				{
+					Geometry: geom.MultiPolygon{
+						{{{20, 0}, {20, 5}, {25, 5}, {25, 0}}},
+						{{{30, 0}, {30, 10}, {40, 10}, {40, 0}}, {{32, 2}, {34, 2}, {34, 4}, {32, 4}}},
+					},
+					Properties: map[string]interface{}{"NAME": "islands", "RINGS": int64(3), "AREA": 121.0},
+				},
+			},
+		},
+		// the m values of PolygonZ records are optional and may be "no data"
+		"polygonz": {
+			path: filepath.Join("testdata", "polygonz.shp"),
+			typ:  shapefile.PolygonZ,
+			fields: []shapefile.Field{
+				{Name: "NAME", Type: shapefile.Character, Length: 16},
+			},
+			expected: []shapefile.Feature{
+				{
+					Geometry: geom.PolygonZ{
+						{{0, 0, 1}, {0, 10, 2}, {10, 10, 3}, {10, 0, 4}},
+						{{2, 2, 5}, {4, 2, 5}, {4, 4, 5}, {2, 4, 5}},
+					},
+					Properties: map[string]interface{}{"NAME": "no measures"},
+				},
+				{
+					Geometry:   geom.PolygonZ{{{20, 0, 1}, {20, 10, 2}, {30, 10, 3}, {30, 0, 4}}},
+					Properties: map[string]interface{}{"NAME": "no data"},
+				},
+				{
+					Geometry:   geom.PolygonZM{{{40, 0, 1, 10}, {40, 10, 2, 11}, {50, 10, 3, 12}, {50, 0, 4, 13}}},
+					Properties: map[string]interface{}{"NAME": "measures"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
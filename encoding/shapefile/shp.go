@@ -0,0 +1,709 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+	"github.com/hahaking119/geom/winding"
+)
+
+// part types of MultiPatch records
+const (
+	triangleStrip = 0
+	triangleFan   = 1
+	outerRing     = 2
+	innerRing     = 3
+	firstRing     = 4
+	ring          = 5
+)
+
+// kinds of geom types a record is decoded to
+const (
+	kindPoint = iota
+	kindLineString
+	kindMultiPoint
+	kindMultiLineString
+	kindPolygon
+)
+
+// cursor reads little endian values of a record, the first read past the end sets err
+type cursor struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (c *cursor) remaining() int { return len(c.b) - c.pos }
+
+func (c *cursor) next(n int) []byte {
+	if c.err != nil {
+		return make([]byte, n)
+	}
+	if n > c.remaining() {
+		c.err = fmt.Errorf("record too short, %v bytes at %v of %v", n, c.pos, len(c.b))
+		return make([]byte, n)
+	}
+	c.pos += n
+	return c.b[c.pos-n : c.pos]
+}
+
+func (c *cursor) int32() int32 { return int32(binary.LittleEndian.Uint32(c.next(4))) }
+
+func (c *cursor) float64() float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(c.next(8)))
+}
+
+func (c *cursor) float64s(n int) []float64 {
+	vs := make([]float64, n)
+	for i := range vs {
+		vs[i] = c.float64()
+	}
+	return vs
+}
+
+// decodeShape returns the geometry of the content of a record
+func decodeShape(b []byte) (geom.Geometry, error) {
+	c := &cursor{b: b}
+	typ := ShapeType(c.int32())
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	switch typ {
+	case Null:
+		return nil, nil
+
+	case Point, PointZ, PointM:
+		pt := []float64{c.float64(), c.float64()}
+		hasZ, hasM := typ == PointZ, typ == PointM
+		if hasZ {
+			pt = append(pt, c.float64())
+			// the m value of a PointZ is optional
+			if c.remaining() >= 8 {
+				if m := c.float64(); m > noData {
+					pt, hasM = append(pt, m), true
+				}
+			}
+		}
+		if typ == PointM {
+			pt = append(pt, c.float64())
+		}
+		if c.err != nil {
+			return nil, c.err
+		}
+		return newGeometry(kindPoint, hasZ, hasM, [][][]float64{{pt}}), nil
+
+	case MultiPoint, MultiPointZ, MultiPointM, PolyLine, PolyLineZ, PolyLineM, Polygon, PolygonZ, PolygonM, MultiPatch:
+		return decodeParts(c, typ)
+
+	default:
+		return nil, ErrUnknownShapeType(typ)
+	}
+}
+
+// decodeParts returns the geometry of a record with a bounding box and points
+func decodeParts(c *cursor, typ ShapeType) (geom.Geometry, error) {
+	// the bounding box
+	c.next(32)
+	numParts := int32(1)
+	if typ.base() != MultiPoint {
+		numParts = c.int32()
+	}
+	numPoints := c.int32()
+	if c.err != nil {
+		return nil, c.err
+	}
+	size := int64(numPoints) * 16
+	if typ.base() != MultiPoint {
+		size += int64(numParts) * 4
+	}
+	if typ == MultiPatch {
+		size += int64(numParts) * 4
+	}
+	if numParts < 0 || numPoints < 0 || size > int64(c.remaining()) {
+		return nil, fmt.Errorf("invalid number of parts %v or points %v", numParts, numPoints)
+	}
+	np := int(numPoints)
+
+	parts := []int{0}
+	if typ.base() != MultiPoint {
+		parts = make([]int, numParts)
+		for i := range parts {
+			parts[i] = int(c.int32())
+		}
+	}
+	var partTypes []int32
+	if typ == MultiPatch {
+		partTypes = make([]int32, numParts)
+		for i := range partTypes {
+			partTypes[i] = c.int32()
+		}
+	}
+	xy := c.float64s(2 * np)
+
+	hasZ, hasM := typ.hasZ(), typ.hasM()
+	var z, m []float64
+	if hasZ {
+		c.next(16)
+		z = c.float64s(np)
+	}
+	// the m values of the z types are optional
+	if hasM || (hasZ && c.remaining() >= 16+8*np) {
+		c.next(16)
+		m = c.float64s(np)
+		if hasZ {
+			for _, v := range m {
+				hasM = hasM || v > noData
+			}
+		}
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	pts := make([][]float64, np)
+	for i := range pts {
+		pt := []float64{xy[2*i], xy[2*i+1]}
+		if hasZ {
+			pt = append(pt, z[i])
+		}
+		if hasM {
+			pt = append(pt, m[i])
+		}
+		pts[i] = pt
+	}
+	rings := make([][][]float64, len(parts))
+	for i, start := range parts {
+		end := np
+		if i+1 < len(parts) {
+			end = parts[i+1]
+		}
+		if start < 0 || start > end || end > np {
+			return nil, fmt.Errorf("invalid part %v of %v points", start, np)
+		}
+		rings[i] = pts[start:end]
+	}
+
+	switch typ.base() {
+	case MultiPoint:
+		return newGeometry(kindMultiPoint, hasZ, hasM, rings), nil
+	case PolyLine:
+		if len(rings) == 1 {
+			return newGeometry(kindLineString, hasZ, hasM, rings), nil
+		}
+		return newGeometry(kindMultiLineString, hasZ, hasM, rings), nil
+	case Polygon:
+		return newPolygons(groupRings(rings), hasZ, hasM), nil
+	default:
+		polys, err := patchPolygons(rings, partTypes)
+		if err != nil {
+			return nil, err
+		}
+		return newPolygons(polys, hasZ, hasM), nil
+	}
+}
+
+// groupRings returns the polygons of the rings: clockwise rings are outer rings and
+// counter clockwise rings the holes of the outer ring they are in.
+func groupRings(rings [][][]float64) [][][][]float64 {
+	var (
+		polys [][][][]float64
+		holes [][][]float64
+	)
+	for _, r := range rings {
+		switch winding.OfPoints(xy(r)...) {
+		case winding.Clockwise:
+			polys = append(polys, [][][]float64{openRing(r)})
+		case winding.CounterClockwise:
+			holes = append(holes, openRing(r))
+		}
+	}
+
+nextHole:
+	for _, h := range holes {
+		for i := range polys {
+			if containsPoint(polys[i][0], h[0]) {
+				polys[i] = append(polys[i], h)
+				continue nextHole
+			}
+		}
+		// a hole that is in no outer ring is wound the wrong way
+		polys = append(polys, [][][]float64{h})
+	}
+	return polys
+}
+
+// patchPolygons returns the polygons of the parts of a MultiPatch
+func patchPolygons(parts [][][]float64, partTypes []int32) ([][][][]float64, error) {
+	var polys [][][][]float64
+	// ringPoly is the polygon the inner rings are added to
+	ringPoly := -1
+	for i, p := range parts {
+		switch partTypes[i] {
+		case triangleStrip:
+			for j := 0; j+2 < len(p); j++ {
+				polys = append(polys, [][][]float64{{p[j], p[j+1], p[j+2]}})
+			}
+		case triangleFan:
+			for j := 1; j+1 < len(p); j++ {
+				polys = append(polys, [][][]float64{{p[0], p[j], p[j+1]}})
+			}
+		case outerRing, firstRing:
+			polys = append(polys, [][][]float64{openRing(p)})
+			ringPoly = len(polys) - 1
+		case innerRing, ring:
+			if ringPoly < 0 {
+				polys = append(polys, [][][]float64{openRing(p)})
+				ringPoly = len(polys) - 1
+				continue
+			}
+			polys[ringPoly] = append(polys[ringPoly], openRing(p))
+		default:
+			return nil, fmt.Errorf("unknown part type %v", partTypes[i])
+		}
+	}
+	return polys, nil
+}
+
+// containsPoint returns whether the point is in the ring, by the even odd rule
+func containsPoint(r [][]float64, pt []float64) bool {
+	in := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		if (r[i][1] > pt[1]) != (r[j][1] > pt[1]) &&
+			pt[0] < (r[j][0]-r[i][0])*(pt[1]-r[i][1])/(r[j][1]-r[i][1])+r[i][0] {
+			in = !in
+		}
+	}
+	return in
+}
+
+func xy(r [][]float64) [][2]float64 {
+	pts := make([][2]float64, len(r))
+	for i := range r {
+		pts[i] = [2]float64{r[i][0], r[i][1]}
+	}
+	return pts
+}
+
+// openRing returns the ring without the repeated last point
+func openRing(r [][]float64) [][]float64 {
+	if len(r) < 2 {
+		return r
+	}
+	first, last := r[0], r[len(r)-1]
+	for d := range first {
+		if first[d] != last[d] {
+			return r
+		}
+	}
+	return r[:len(r)-1]
+}
+
+// closeRing returns the ring with the first point repeated at the end
+func closeRing(r [][]float64) [][]float64 {
+	if len(r) == 0 {
+		return r
+	}
+	first, last := r[0], r[len(r)-1]
+	for d := range first {
+		if first[d] != last[d] {
+			return append(r[:len(r):len(r)], first)
+		}
+	}
+	return r
+}
+
+// newPolygons returns a polygon for a single polygon, otherwise a multi polygon or,
+// with z or m values, a collection of polygons
+func newPolygons(polys [][][][]float64, hasZ, hasM bool) geom.Geometry {
+	if len(polys) == 1 {
+		return newGeometry(kindPolygon, hasZ, hasM, polys[0])
+	}
+	if !hasZ && !hasM {
+		mp := make(geom.MultiPolygon, len(polys))
+		for i := range polys {
+			mp[i] = lines2(polys[i])
+		}
+		return mp
+	}
+	col := make(geom.Collection, len(polys))
+	for i := range polys {
+		col[i] = newGeometry(kindPolygon, hasZ, hasM, polys[i])
+	}
+	return col
+}
+
+// newGeometry returns the geom type of the kind and dimensions
+func newGeometry(kind int, hasZ, hasM bool, rings [][][]float64) geom.Geometry {
+	var pts [][]float64
+	if len(rings) > 0 {
+		pts = rings[0]
+	}
+
+	switch {
+	case hasZ && hasM:
+		switch kind {
+		case kindPoint:
+			return geom.PointZM(pts4(pts)[0])
+		case kindLineString:
+			return geom.LineStringZM(pts4(pts))
+		case kindMultiPoint:
+			return geom.MultiPointZM(pts4(pts))
+		case kindMultiLineString:
+			return geom.MultiLineStringZM(lines4(rings))
+		default:
+			return geom.PolygonZM(lines4(rings))
+		}
+	case hasZ:
+		switch kind {
+		case kindPoint:
+			return geom.PointZ(pts3(pts)[0])
+		case kindLineString:
+			return geom.LineStringZ(pts3(pts))
+		case kindMultiPoint:
+			return geom.MultiPointZ(pts3(pts))
+		case kindMultiLineString:
+			return geom.MultiLineStringZ(lines3(rings))
+		default:
+			return geom.PolygonZ(lines3(rings))
+		}
+	case hasM:
+		switch kind {
+		case kindPoint:
+			return geom.PointM(pts3(pts)[0])
+		case kindLineString:
+			return geom.LineStringM(pts3(pts))
+		case kindMultiPoint:
+			return geom.MultiPointM(pts3(pts))
+		case kindMultiLineString:
+			return geom.MultiLineStringM(lines3(rings))
+		default:
+			return geom.PolygonM(lines3(rings))
+		}
+	default:
+		switch kind {
+		case kindPoint:
+			return geom.Point(pts2(pts)[0])
+		case kindLineString:
+			return geom.LineString(pts2(pts))
+		case kindMultiPoint:
+			return geom.MultiPoint(pts2(pts))
+		case kindMultiLineString:
+			return geom.MultiLineString(lines2(rings))
+		default:
+			return geom.Polygon(lines2(rings))
+		}
+	}
+}
+
+// shape is a geometry as the parts of a record, with the z and m values following
+// the x and y values
+type shape struct {
+	typ ShapeType
+	// hasM is whether the z types have m values
+	hasM  bool
+	parts [][][]float64
+}
+
+// newShape returns the shape of the geometry; the rings of polygons are closed and
+// oriented.
+func newShape(geo geom.Geometry) (shape, error) {
+	one := func(pts [][]float64) [][][]float64 { return [][][]float64{pts} }
+
+	switch g := geo.(type) {
+	// the z and m types implement the 2d interfaces, so are matched first
+	case geom.PointZ:
+		return shape{typ: PointZ, parts: one(coords3(g))}, nil
+	case geom.PointM:
+		return shape{typ: PointM, parts: one(coords3(g))}, nil
+	case geom.PointZM:
+		return shape{typ: PointZ, hasM: true, parts: one(coords4(g))}, nil
+	case geom.LineStringZ:
+		return shape{typ: PolyLineZ, parts: one(coords3(g...))}, nil
+	case geom.LineStringM:
+		return shape{typ: PolyLineM, parts: one(coords3(g...))}, nil
+	case geom.LineStringZM:
+		return shape{typ: PolyLineZ, hasM: true, parts: one(coords4(g...))}, nil
+	case geom.MultiPointZ:
+		return shape{typ: MultiPointZ, parts: one(coords3(g...))}, nil
+	case geom.MultiPointM:
+		return shape{typ: MultiPointM, parts: one(coords3(g...))}, nil
+	case geom.MultiPointZM:
+		return shape{typ: MultiPointZ, hasM: true, parts: one(coords4(g...))}, nil
+	case geom.MultiLineStringZ:
+		return shape{typ: PolyLineZ, parts: rings3(g)}, nil
+	case geom.MultiLineStringM:
+		return shape{typ: PolyLineM, parts: rings3(g)}, nil
+	case geom.MultiLineStringZM:
+		return shape{typ: PolyLineZ, hasM: true, parts: rings4(g)}, nil
+	case geom.PolygonZ:
+		return shape{typ: PolygonZ, parts: orient(rings3(g))}, nil
+	case geom.PolygonM:
+		return shape{typ: PolygonM, parts: orient(rings3(g))}, nil
+	case geom.PolygonZM:
+		return shape{typ: PolygonZ, hasM: true, parts: orient(rings4(g))}, nil
+
+	case *geom.MultiPolygon:
+		if g == nil {
+			return shape{}, encoding.ErrUnknownGeometry{Geom: geo}
+		}
+		return newShape(*g)
+
+	case geom.Pointer:
+		return shape{typ: Point, parts: one(coords2(g.XY()))}, nil
+	case geom.MultiPointer:
+		return shape{typ: MultiPoint, parts: one(coords2(g.Points()...))}, nil
+	case geom.LineStringer:
+		return shape{typ: PolyLine, parts: one(coords2(g.Vertices()...))}, nil
+	case geom.MultiLineStringer:
+		return shape{typ: PolyLine, parts: rings2(g.LineStrings())}, nil
+	case geom.Polygoner:
+		return shape{typ: Polygon, parts: orient(rings2(g.LinearRings()))}, nil
+	case geom.MultiPolygoner:
+		s := shape{typ: Polygon}
+		for _, p := range g.Polygons() {
+			s.parts = append(s.parts, orient(rings2(p))...)
+		}
+		return s, nil
+
+	case geom.Collectioner:
+		// a collection of polygons, as several polygons with z or m values are decoded
+		var s shape
+		for i, cg := range g.Geometries() {
+			cs, err := newShape(cg)
+			if err != nil {
+				return shape{}, err
+			}
+			if cs.typ.base() != Polygon || (i > 0 && (cs.typ != s.typ || cs.hasM != s.hasM)) {
+				return shape{}, encoding.ErrUnknownGeometry{Geom: geo}
+			}
+			s.typ, s.hasM = cs.typ, cs.hasM
+			s.parts = append(s.parts, cs.parts...)
+		}
+		if s.typ == Null {
+			return shape{}, encoding.ErrUnknownGeometry{Geom: geo}
+		}
+		return s, nil
+
+	default:
+		return shape{}, encoding.ErrUnknownGeometry{Geom: geo}
+	}
+}
+
+// orient returns the closed rings with the first ring clockwise and the others
+// counter clockwise
+func orient(rings [][][]float64) [][][]float64 {
+	oriented := make([][][]float64, len(rings))
+	for i, r := range rings {
+		r = closeRing(r)
+		w := winding.OfPoints(xy(r)...)
+		if (i == 0 && w.IsCounterClockwise()) || (i > 0 && w.IsClockwise()) {
+			rev := make([][]float64, len(r))
+			for j := range r {
+				rev[len(r)-1-j] = r[j]
+			}
+			r = rev
+		}
+		oriented[i] = r
+	}
+	return oriented
+}
+
+// empty returns whether the shape has no points
+func (s shape) empty() bool {
+	for _, p := range s.parts {
+		if len(p) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// extents returns the extent of the x and y values, and the ranges of the z and m values
+func (s shape) extents() (ext geom.Extent, zr, mr [2]float64) {
+	first, firstM := true, true
+	for _, p := range s.parts {
+		for _, pt := range p {
+			if first {
+				ext = geom.Extent{pt[0], pt[1], pt[0], pt[1]}
+			}
+			ext.AddPoints([2]float64{pt[0], pt[1]})
+			d := 2
+			if s.typ.hasZ() {
+				if first {
+					zr = [2]float64{pt[d], pt[d]}
+				}
+				zr = [2]float64{math.Min(zr[0], pt[d]), math.Max(zr[1], pt[d])}
+				d++
+			}
+			if s.typ.hasM() || s.hasM {
+				if firstM {
+					mr = [2]float64{pt[d], pt[d]}
+					firstM = false
+				}
+				mr = [2]float64{math.Min(mr[0], pt[d]), math.Max(mr[1], pt[d])}
+			}
+			first = false
+		}
+	}
+	return ext, zr, mr
+}
+
+// noDataValue is written for missing m values
+const noDataValue = -math.MaxFloat64
+
+// encode returns the content of the record of the shape
+func (s shape) encode() []byte {
+	var buf bytes.Buffer
+	w := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+
+	if s.empty() {
+		w(int32(Null))
+		return buf.Bytes()
+	}
+	w(int32(s.typ))
+	hasZ, hasM := s.typ.hasZ(), s.typ.hasM() || s.hasM
+
+	if s.typ.base() == Point {
+		pt := s.parts[0][0]
+		w(pt)
+		if s.typ == PointZ && !s.hasM {
+			w(noDataValue)
+		}
+		return buf.Bytes()
+	}
+
+	ext, zr, mr := s.extents()
+	w(ext)
+	var pts [][]float64
+	if s.typ.base() != MultiPoint {
+		w(int32(len(s.parts)))
+	}
+	for _, p := range s.parts {
+		pts = append(pts, p...)
+	}
+	w(int32(len(pts)))
+	if s.typ.base() != MultiPoint {
+		start := int32(0)
+		for _, p := range s.parts {
+			w(start)
+			start += int32(len(p))
+		}
+	}
+	for _, pt := range pts {
+		w(pt[:2])
+	}
+	if hasZ {
+		w(zr)
+		for _, pt := range pts {
+			w(pt[2])
+		}
+	}
+	if hasM {
+		w(mr)
+		for _, pt := range pts {
+			w(pt[len(pt)-1])
+		}
+	}
+	return buf.Bytes()
+}
+
+func coords2(pts ...[2]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func coords3(pts ...[3]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func coords4(pts ...[4]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func rings2(rs [][][2]float64) [][][]float64 {
+	cs := make([][][]float64, len(rs))
+	for i := range rs {
+		cs[i] = coords2(rs[i]...)
+	}
+	return cs
+}
+
+func rings3(rs [][][3]float64) [][][]float64 {
+	cs := make([][][]float64, len(rs))
+	for i := range rs {
+		cs[i] = coords3(rs[i]...)
+	}
+	return cs
+}
+
+func rings4(rs [][][4]float64) [][][]float64 {
+	cs := make([][][]float64, len(rs))
+	for i := range rs {
+		cs[i] = coords4(rs[i]...)
+	}
+	return cs
+}
+
+func pts2(cs [][]float64) [][2]float64 {
+	pts := make([][2]float64, len(cs))
+	for i, c := range cs {
+		copy(pts[i][:], c)
+	}
+	return pts
+}
+
+func pts3(cs [][]float64) [][3]float64 {
+	pts := make([][3]float64, len(cs))
+	for i, c := range cs {
+		copy(pts[i][:], c)
+	}
+	return pts
+}
+
+func pts4(cs [][]float64) [][4]float64 {
+	pts := make([][4]float64, len(cs))
+	for i, c := range cs {
+		copy(pts[i][:], c)
+	}
+	return pts
+}
+
+func lines2(rs [][][]float64) [][][2]float64 {
+	ls := make([][][2]float64, len(rs))
+	for i := range rs {
+		ls[i] = pts2(rs[i])
+	}
+	return ls
+}
+
+func lines3(rs [][][]float64) [][][3]float64 {
+	ls := make([][][3]float64, len(rs))
+	for i := range rs {
+		ls[i] = pts3(rs[i])
+	}
+	return ls
+}
+
+func lines4(rs [][][]float64) [][][4]float64 {
+	ls := make([][][4]float64, len(rs))
+	for i := range rs {
+		ls[i] = pts4(rs[i])
+	}
+	return ls
+}
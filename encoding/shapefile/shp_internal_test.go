@@ -0,0 +1,111 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/hahaking119/geom"
+)
+
+// record returns the content of a record with parts of points with z values
+func record(typ ShapeType, partTypes []int32, parts ...[][3]float64) []byte {
+	var buf bytes.Buffer
+	w := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+	var pts [][3]float64
+	for _, p := range parts {
+		pts = append(pts, p...)
+	}
+	w(int32(typ))
+	w([4]float64{})
+	w(int32(len(parts)))
+	w(int32(len(pts)))
+	start := int32(0)
+	for _, p := range parts {
+		w(start)
+		start += int32(len(p))
+	}
+	if typ == MultiPatch {
+		w(partTypes)
+	}
+	for _, pt := range pts {
+		w(pt[:2])
+	}
+	if typ.hasZ() {
+		w([2]float64{})
+		for _, pt := range pts {
+			w(pt[2])
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeShape(t *testing.T) {
+	type tcase struct {
+		content  []byte
+		expected geom.Geometry
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := decodeShape(tc.content)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("geometry, expected %v got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"holes before shells": {
+			content: record(Polygon, nil,
+				// a counter clockwise hole of the second shell
+				[][3]float64{{6, 6}, {7, 6}, {7, 7}, {6, 7}, {6, 6}},
+				[][3]float64{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}},
+				[][3]float64{{5, 5}, {5, 9}, {9, 9}, {9, 5}, {5, 5}},
+			),
+			expected: geom.MultiPolygon{
+				{{{0, 0}, {0, 1}, {1, 1}, {1, 0}}},
+				{
+					{{5, 5}, {5, 9}, {9, 9}, {9, 5}},
+					{{6, 6}, {7, 6}, {7, 7}, {6, 7}},
+				},
+			},
+		},
+		"multipatch triangle strip": {
+			content: record(MultiPatch, []int32{triangleStrip},
+				[][3]float64{{0, 0, 1}, {0, 1, 2}, {1, 0, 3}, {1, 1, 4}},
+			),
+			expected: geom.Collection{
+				geom.PolygonZ{{{0, 0, 1}, {0, 1, 2}, {1, 0, 3}}},
+				geom.PolygonZ{{{0, 1, 2}, {1, 0, 3}, {1, 1, 4}}},
+			},
+		},
+		"multipatch triangle fan": {
+			content: record(MultiPatch, []int32{triangleFan},
+				[][3]float64{{0, 0, 1}, {0, 1, 2}, {1, 1, 3}, {1, 0, 4}},
+			),
+			expected: geom.Collection{
+				geom.PolygonZ{{{0, 0, 1}, {0, 1, 2}, {1, 1, 3}}},
+				geom.PolygonZ{{{0, 0, 1}, {1, 1, 3}, {1, 0, 4}}},
+			},
+		},
+		"multipatch rings": {
+			content: record(MultiPatch, []int32{outerRing, innerRing},
+				[][3]float64{{0, 0, 1}, {0, 9, 1}, {9, 9, 1}, {9, 0, 1}, {0, 0, 1}},
+				[][3]float64{{2, 2, 1}, {3, 2, 1}, {3, 3, 1}, {2, 2, 1}},
+			),
+			expected: geom.PolygonZ{
+				{{0, 0, 1}, {0, 9, 1}, {9, 9, 1}, {9, 0, 1}},
+				{{2, 2, 1}, {3, 2, 1}, {3, 3, 1}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
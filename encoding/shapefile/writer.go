@@ -0,0 +1,142 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Encode writes the .shp, .shx and .dbf files of the features; dbf may be nil. The
+// shape type is that of the geometries, which must all be of the same type, and
+// nil or empty geometries are written as null shapes. The outer rings of polygons are
+// written clockwise and their holes counter clockwise.
+func Encode(shp, shx, dbf io.Writer, fields []Field, features []Feature) error {
+	var (
+		hdr     = Header{Type: Null}
+		records [][]byte
+		first   = true
+		firstM  = true
+	)
+	for i, f := range features {
+		content := []byte{0, 0, 0, 0}
+		if f.Geometry != nil {
+			s, err := newShape(f.Geometry)
+			if err != nil {
+				return err
+			}
+			content = s.encode()
+			if !s.empty() {
+				if hdr.Type != Null && hdr.Type != s.typ {
+					return ErrMixedShapeTypes{Expected: hdr.Type, Got: s.typ}
+				}
+				hdr.Type = s.typ
+
+				ext, zr, mr := s.extents()
+				if first {
+					hdr.BBox, hdr.ZRange = ext, zr
+					first = false
+				}
+				hdr.BBox.Add(&ext)
+				hdr.ZRange = [2]float64{math.Min(hdr.ZRange[0], zr[0]), math.Max(hdr.ZRange[1], zr[1])}
+				if s.typ.hasM() || s.hasM {
+					if firstM {
+						hdr.MRange = mr
+						firstM = false
+					}
+					hdr.MRange = [2]float64{math.Min(hdr.MRange[0], mr[0]), math.Max(hdr.MRange[1], mr[1])}
+				}
+			}
+		}
+		if len(content)/2 > math.MaxInt32 {
+			return fmt.Errorf("record %v too large", i+1)
+		}
+		records = append(records, content)
+	}
+
+	var shpBuf, shxBuf bytes.Buffer
+	length := int64(headerSize)
+	for _, rec := range records {
+		length += 8 + int64(len(rec))
+	}
+	if length/2 > math.MaxInt32 {
+		return fmt.Errorf("file too large, %v bytes", length)
+	}
+	hdr.FileLength = length
+	writeHeader(&shpBuf, hdr)
+	hdr.FileLength = headerSize + 8*int64(len(records))
+	writeHeader(&shxBuf, hdr)
+
+	offset := int64(headerSize)
+	for i, rec := range records {
+		binary.Write(&shpBuf, binary.BigEndian, [2]int32{int32(i + 1), int32(len(rec) / 2)})
+		shpBuf.Write(rec)
+		binary.Write(&shxBuf, binary.BigEndian, [2]int32{int32(offset / 2), int32(len(rec) / 2)})
+		offset += 8 + int64(len(rec))
+	}
+
+	if dbf != nil {
+		if err := encodeDBF(dbf, fields, features, time.Now()); err != nil {
+			return err
+		}
+	}
+	if _, err := shp.Write(shpBuf.Bytes()); err != nil {
+		return err
+	}
+	_, err := shx.Write(shxBuf.Bytes())
+	return err
+}
+
+// writeHeader writes the header of a .shp or .shx file
+func writeHeader(buf *bytes.Buffer, hdr Header) {
+	binary.Write(buf, binary.BigEndian, [6]int32{fileCode})
+	binary.Write(buf, binary.BigEndian, int32(hdr.FileLength/2))
+	binary.Write(buf, binary.LittleEndian, [2]int32{version, int32(hdr.Type)})
+	binary.Write(buf, binary.LittleEndian, hdr.BBox)
+	binary.Write(buf, binary.LittleEndian, hdr.ZRange)
+	binary.Write(buf, binary.LittleEndian, hdr.MRange)
+}
+
+// WriteFile writes the .shp, .shx, .dbf and, when prj is not empty, .prj files of the
+// features at the path, with or without the .shp extension. A .cpg file declares the
+// strings of the .dbf file as UTF-8.
+func WriteFile(path string, fields []Field, features []Feature, prj string) error {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	var shp, shx, dbf bytes.Buffer
+	if err := Encode(&shp, &shx, &dbf, fields, features); err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		".shp": shp.Bytes(),
+		".shx": shx.Bytes(),
+		".dbf": dbf.Bytes(),
+		".cpg": []byte("UTF-8"),
+	}
+	if prj != "" {
+		files[".prj"] = []byte(prj)
+	}
+	for ext, b := range files {
+		if err := writeFile(base+ext, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(name string, b []byte) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
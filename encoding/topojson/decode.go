@@ -0,0 +1,236 @@
+package topojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding/geojson"
+)
+
+// Unmarshal returns the objects of the topology. An object that is a geometry
+// collection is a feature collection of its geometries and any other object a feature
+// collection of one feature. Ids that are unsigned integers are read as the ids of
+// the features and other ids are dropped.
+func Unmarshal(data []byte) (map[string]geojson.FeatureCollection, error) {
+	var topo topologyJSON
+	if err := json.Unmarshal(data, &topo); err != nil {
+		return nil, err
+	}
+	if topo.Type != "Topology" {
+		return nil, fmt.Errorf("type %q is not Topology", topo.Type)
+	}
+
+	d := decoder{t: topo.Transform, arcs: make([][][2]float64, len(topo.Arcs))}
+	for i, arc := range topo.Arcs {
+		pts := make([][2]float64, len(arc))
+		var prev [2]float64
+		for j, pos := range arc {
+			if len(pos) < 2 {
+				return nil, fmt.Errorf("arc %v: position of %v values", i, len(pos))
+			}
+			pt := [2]float64{pos[0], pos[1]}
+			if d.t != nil {
+				// delta encoded from the previous position
+				pt = [2]float64{prev[0] + pt[0], prev[1] + pt[1]}
+				prev = pt
+			}
+			pts[j] = d.transform(pt)
+		}
+		d.arcs[i] = pts
+	}
+
+	objects := make(map[string]geojson.FeatureCollection, len(topo.Objects))
+	for name, obj := range topo.Objects {
+		if obj == nil {
+			return nil, fmt.Errorf("object %q: null", name)
+		}
+		geos := []*geometryJSON{obj}
+		if obj.Type != nil && *obj.Type == typeGeometryCollection {
+			geos = obj.Geometries
+		}
+		fc := geojson.FeatureCollection{Features: make([]geojson.Feature, len(geos))}
+		for i, g := range geos {
+			f, err := d.feature(g)
+			if err != nil {
+				return nil, fmt.Errorf("object %q: %v", name, err)
+			}
+			fc.Features[i] = f
+		}
+		objects[name] = fc
+	}
+	return objects, nil
+}
+
+// decoder returns the geometries of geometry objects
+type decoder struct {
+	t    *transformJSON
+	arcs [][][2]float64
+}
+
+// transform returns the position of a quantized position
+func (d decoder) transform(pt [2]float64) [2]float64 {
+	if d.t == nil {
+		return pt
+	}
+	return [2]float64{
+		pt[0]*d.t.Scale[0] + d.t.Translate[0],
+		pt[1]*d.t.Scale[1] + d.t.Translate[1],
+	}
+}
+
+func (d decoder) feature(g *geometryJSON) (geojson.Feature, error) {
+	if g == nil {
+		return geojson.Feature{}, nil
+	}
+	f := geojson.Feature{Properties: g.Properties}
+	if id, ok := g.ID.(float64); ok && id >= 0 && id == math.Trunc(id) && id <= math.MaxUint64 {
+		uid := uint64(id)
+		f.ID = &uid
+	}
+	geo, err := d.geometry(g)
+	if err != nil {
+		return geojson.Feature{}, err
+	}
+	f.Geometry = geojson.Geometry{Geometry: geo}
+	return f, nil
+}
+
+// geometry returns the geometry of the geometry object, nil for a null type
+func (d decoder) geometry(g *geometryJSON) (geom.Geometry, error) {
+	if g == nil || g.Type == nil {
+		return nil, nil
+	}
+
+	switch *g.Type {
+	case typePoint:
+		var pt [2]float64
+		if err := unmarshal(g.Coordinates, &pt); err != nil {
+			return nil, err
+		}
+		return geom.Point(d.transform(pt)), nil
+
+	case typeMultiPoint:
+		var pts [][2]float64
+		if err := unmarshal(g.Coordinates, &pts); err != nil {
+			return nil, err
+		}
+		mp := make(geom.MultiPoint, len(pts))
+		for i, pt := range pts {
+			mp[i] = d.transform(pt)
+		}
+		return mp, nil
+
+	case typeLineString:
+		var arcs []int
+		if err := unmarshal(g.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		line, err := d.line(arcs)
+		return geom.LineString(line), err
+
+	case typeMultiLineString:
+		var arcs [][]int
+		if err := unmarshal(g.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		mls := make(geom.MultiLineString, len(arcs))
+		for i, a := range arcs {
+			var err error
+			if mls[i], err = d.line(a); err != nil {
+				return nil, err
+			}
+		}
+		return mls, nil
+
+	case typePolygon:
+		var arcs [][]int
+		if err := unmarshal(g.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		return d.polygon(arcs)
+
+	case typeMultiPolygon:
+		var arcs [][][]int
+		if err := unmarshal(g.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		mp := make(geom.MultiPolygon, len(arcs))
+		for i, a := range arcs {
+			p, err := d.polygon(a)
+			if err != nil {
+				return nil, err
+			}
+			mp[i] = p
+		}
+		return mp, nil
+
+	case typeGeometryCollection:
+		col := make(geom.Collection, 0, len(g.Geometries))
+		for _, gg := range g.Geometries {
+			geo, err := d.geometry(gg)
+			if err != nil {
+				return nil, err
+			}
+			if geo != nil {
+				col = append(col, geo)
+			}
+		}
+		return col, nil
+
+	default:
+		return nil, ErrUnknownType(*g.Type)
+	}
+}
+
+// unmarshal unmarshals the coordinates or arcs, which are required
+func unmarshal(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("missing coordinates or arcs")
+	}
+	return json.Unmarshal(data, v)
+}
+
+// line returns the positions of the arcs, which share their end points
+func (d decoder) line(arcs []int) ([][2]float64, error) {
+	line := [][2]float64{}
+	for _, i := range arcs {
+		idx := i
+		if i < 0 {
+			idx = ^i
+		}
+		if idx >= len(d.arcs) {
+			return nil, ErrInvalidArc(i)
+		}
+		arc := d.arcs[idx]
+		if len(line) > 0 && len(arc) > 0 {
+			line = line[:len(line)-1]
+		}
+		if i >= 0 {
+			line = append(line, arc...)
+			continue
+		}
+		for j := len(arc) - 1; j >= 0; j-- {
+			line = append(line, arc[j])
+		}
+	}
+	return line, nil
+}
+
+// polygon returns the polygon of the arcs of its rings, which are closed in TopoJSON
+// and open in geom
+func (d decoder) polygon(arcs [][]int) (geom.Polygon, error) {
+	poly := make(geom.Polygon, len(arcs))
+	for i, a := range arcs {
+		ring, err := d.line(a)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(ring); n > 1 && ring[0] == ring[n-1] {
+			ring = ring[:n-1]
+		}
+		poly[i] = ring
+	}
+	return poly, nil
+}
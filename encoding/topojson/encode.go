@@ -0,0 +1,441 @@
+package topojson
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+	"github.com/hahaking119/geom/encoding/geojson"
+)
+
+// Marshal returns the topology of the objects. The lines and rings of the geometries
+// are cut at the points where they meet other lines or rings, or themselves, and
+// each arc is written once. With quantization, consecutive positions that fall in
+// the same grid cell are merged and rings with fewer than three positions left are
+// dropped. Feature ids are written as the ids of the geometry objects.
+func Marshal(objects map[string]geojson.FeatureCollection, opts Options) ([]byte, error) {
+	if opts.Quantization < 0 || opts.Quantization == 1 {
+		return nil, fmt.Errorf("invalid quantization %v", opts.Quantization)
+	}
+
+	// the objects are visited in the order of their names so the arcs are too
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		shapes = make(map[string][]*shape, len(objects))
+		ext    *geom.Extent
+	)
+	for _, name := range names {
+		for _, f := range objects[name].Features {
+			s, err := newShape(f.Geometry.Geometry)
+			if err != nil {
+				return nil, err
+			}
+			if s != nil {
+				s.extent(&ext)
+			}
+			shapes[name] = append(shapes[name], s)
+		}
+	}
+
+	topo := topologyJSON{Type: "Topology", Objects: make(map[string]*geometryJSON, len(objects)), Arcs: [][][]float64{}}
+	if ext != nil {
+		topo.BBox = ext[:]
+	}
+
+	quantize := func(pt [2]float64) [2]float64 { return pt }
+	if opts.Quantization > 0 && ext != nil {
+		t := &transformJSON{Scale: [2]float64{1, 1}, Translate: [2]float64{ext.MinX(), ext.MinY()}}
+		if w := ext.MaxX() - ext.MinX(); w > 0 {
+			t.Scale[0] = w / float64(opts.Quantization-1)
+		}
+		if h := ext.MaxY() - ext.MinY(); h > 0 {
+			t.Scale[1] = h / float64(opts.Quantization-1)
+		}
+		quantize = func(pt [2]float64) [2]float64 {
+			return [2]float64{
+				math.Round((pt[0] - t.Translate[0]) / t.Scale[0]),
+				math.Round((pt[1] - t.Translate[1]) / t.Scale[1]),
+			}
+		}
+		topo.Transform = t
+	}
+
+	b := newBuilder()
+	for _, name := range names {
+		for _, s := range shapes[name] {
+			if s != nil {
+				s.prepare(quantize)
+				s.visit(b)
+			}
+		}
+	}
+
+	for _, name := range names {
+		fc := objects[name]
+		obj := &geometryJSON{Type: stringPtr(typeGeometryCollection), Geometries: []*geometryJSON{}}
+		for i, f := range fc.Features {
+			g, err := shapes[name][i].encode(b)
+			if err != nil {
+				return nil, err
+			}
+			if f.ID != nil {
+				g.ID = *f.ID
+			}
+			g.Properties = f.Properties
+			obj.Geometries = append(obj.Geometries, g)
+		}
+		topo.Objects[name] = obj
+	}
+
+	for _, arc := range b.arcs {
+		out := make([][]float64, len(arc))
+		var prev [2]float64
+		for i, pt := range arc {
+			if topo.Transform != nil {
+				// delta encoded from the previous position
+				pt, prev = [2]float64{pt[0] - prev[0], pt[1] - prev[1]}, pt
+			}
+			out[i] = []float64{pt[0], pt[1]}
+		}
+		topo.Arcs = append(topo.Arcs, out)
+	}
+	return json.Marshal(topo)
+}
+
+func stringPtr(s string) *string { return &s }
+
+// shape is a geometry as its points, lines or polygons, with closed rings, or its
+// geometries for collections
+type shape struct {
+	typ    string
+	points [][2]float64
+	lines  [][][2]float64
+	polys  [][][][2]float64
+	shapes []*shape
+}
+
+// newShape returns the shape of the geometry, nil for a nil geometry. The slices of
+// the geometry are copied as the shape is modified in place.
+func newShape(geo geom.Geometry) (*shape, error) {
+	switch g := geo.(type) {
+	case nil:
+		return nil, nil
+	case geom.Pointer:
+		return &shape{typ: typePoint, points: [][2]float64{g.XY()}}, nil
+	case geom.MultiPointer:
+		return &shape{typ: typeMultiPoint, points: append([][2]float64(nil), g.Points()...)}, nil
+	case geom.LineStringer:
+		return &shape{typ: typeLineString, lines: [][][2]float64{g.Vertices()}}, nil
+	case geom.MultiLineStringer:
+		return &shape{typ: typeMultiLineString, lines: append([][][2]float64(nil), g.LineStrings()...)}, nil
+	case geom.Polygoner:
+		return &shape{typ: typePolygon, polys: [][][][2]float64{copyRings(g.LinearRings())}}, nil
+	case geom.MultiPolygoner:
+		s := &shape{typ: typeMultiPolygon}
+		for _, p := range g.Polygons() {
+			s.polys = append(s.polys, copyRings(p))
+		}
+		return s, nil
+	case geom.Collectioner:
+		s := &shape{typ: typeGeometryCollection}
+		for _, gg := range g.Geometries() {
+			if gg == nil {
+				continue
+			}
+			ss, err := newShape(gg)
+			if err != nil {
+				return nil, err
+			}
+			s.shapes = append(s.shapes, ss)
+		}
+		return s, nil
+	default:
+		return nil, encoding.ErrUnknownGeometry{Geom: geo}
+	}
+}
+
+func copyRings(rings [][][2]float64) [][][2]float64 {
+	return append([][][2]float64(nil), rings...)
+}
+
+// extent adds the points of the shape to the extent, which is created for the first point
+func (s *shape) extent(ext **geom.Extent) {
+	add := func(pts [][2]float64) {
+		if len(pts) == 0 {
+			return
+		}
+		if *ext == nil {
+			*ext = geom.NewExtent(pts[0])
+		}
+		(*ext).AddPoints(pts...)
+	}
+	add(s.points)
+	for _, line := range s.lines {
+		add(line)
+	}
+	for _, p := range s.polys {
+		for _, ring := range p {
+			add(ring)
+		}
+	}
+	for _, ss := range s.shapes {
+		ss.extent(ext)
+	}
+}
+
+// prepare quantizes the points of the shape, drops the repeated positions of lines
+// and closes rings, dropping those with fewer than three positions
+func (s *shape) prepare(quantize func([2]float64) [2]float64) {
+	clean := func(pts [][2]float64) [][2]float64 {
+		out := make([][2]float64, 0, len(pts)+1)
+		for _, pt := range pts {
+			pt = quantize(pt)
+			if len(out) == 0 || out[len(out)-1] != pt {
+				out = append(out, pt)
+			}
+		}
+		return out
+	}
+
+	for i, pt := range s.points {
+		s.points[i] = quantize(pt)
+	}
+	for i, line := range s.lines {
+		s.lines[i] = clean(line)
+		if len(s.lines[i]) == 1 {
+			s.lines[i] = append(s.lines[i], s.lines[i][0])
+		}
+	}
+
+	polys := s.polys[:0]
+	for _, p := range s.polys {
+		rings := p[:0]
+		for _, ring := range p {
+			ring = clean(ring)
+			if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+				ring = ring[:len(ring)-1]
+			}
+			if len(ring) < 3 {
+				// the exterior is dropped with its holes
+				if len(rings) == 0 {
+					break
+				}
+				continue
+			}
+			rings = append(rings, append(ring, ring[0]))
+		}
+		if len(rings) > 0 || s.typ == typePolygon {
+			polys = append(polys, rings)
+		}
+	}
+	s.polys = polys
+
+	for _, ss := range s.shapes {
+		ss.prepare(quantize)
+	}
+}
+
+// visit adds the lines and rings of the shape to the junctions of the builder
+func (s *shape) visit(b *builder) {
+	for _, line := range s.lines {
+		b.visitLine(line)
+	}
+	for _, p := range s.polys {
+		for _, ring := range p {
+			b.visitRing(ring)
+		}
+	}
+	for _, ss := range s.shapes {
+		ss.visit(b)
+	}
+}
+
+// encode returns the geometry object of the shape, with a null type for a nil shape
+func (s *shape) encode(b *builder) (*geometryJSON, error) {
+	if s == nil {
+		return &geometryJSON{}, nil
+	}
+	g := &geometryJSON{Type: stringPtr(s.typ)}
+
+	var (
+		v   interface{}
+		err error
+	)
+	switch s.typ {
+	case typePoint:
+		v = s.points[0]
+	case typeMultiPoint:
+		v = append([][2]float64{}, s.points...)
+	case typeLineString:
+		v = b.lineArcs(s.lines[0])
+	case typeMultiLineString:
+		arcs := make([][]int, len(s.lines))
+		for i, line := range s.lines {
+			arcs[i] = b.lineArcs(line)
+		}
+		v = arcs
+	case typePolygon:
+		v = b.polygonArcs(s.polys[0])
+	case typeMultiPolygon:
+		arcs := make([][][]int, len(s.polys))
+		for i, p := range s.polys {
+			arcs[i] = b.polygonArcs(p)
+		}
+		v = arcs
+	case typeGeometryCollection:
+		g.Geometries = make([]*geometryJSON, len(s.shapes))
+		for i, ss := range s.shapes {
+			if g.Geometries[i], err = ss.encode(b); err != nil {
+				return nil, err
+			}
+		}
+		return g, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if s.typ == typePoint || s.typ == typeMultiPoint {
+		g.Coordinates = raw
+	} else {
+		g.Arcs = raw
+	}
+	return g, nil
+}
+
+// builder finds the junctions of lines and rings and cuts them into shared arcs
+type builder struct {
+	// neighbors are the positions before and after the first occurrence of each position
+	neighbors map[[2]float64][2][2]float64
+	// junctions are where arcs start and end
+	junctions map[[2]float64]bool
+	arcs      [][][2]float64
+	// index is the index of each arc by its positions
+	index map[string]int
+}
+
+func newBuilder() *builder {
+	return &builder{
+		neighbors: make(map[[2]float64][2][2]float64),
+		junctions: make(map[[2]float64]bool),
+		index:     make(map[string]int),
+	}
+}
+
+// visitPoint marks the point as a junction when it was visited with other neighbors
+func (b *builder) visitPoint(pt, prev, next [2]float64) {
+	n, ok := b.neighbors[pt]
+	if !ok {
+		b.neighbors[pt] = [2][2]float64{prev, next}
+		return
+	}
+	if n != [2][2]float64{prev, next} && n != [2][2]float64{next, prev} {
+		b.junctions[pt] = true
+	}
+}
+
+func (b *builder) visitLine(line [][2]float64) {
+	if len(line) == 0 {
+		return
+	}
+	b.junctions[line[0]] = true
+	b.junctions[line[len(line)-1]] = true
+	for i := 1; i < len(line)-1; i++ {
+		b.visitPoint(line[i], line[i-1], line[i+1])
+	}
+}
+
+// visitRing visits a closed ring
+func (b *builder) visitRing(ring [][2]float64) {
+	n := len(ring) - 1
+	for i := 0; i < n; i++ {
+		b.visitPoint(ring[i], ring[(i+n-1)%n], ring[i+1])
+	}
+}
+
+// lineArcs returns the indices of the arcs of the line
+func (b *builder) lineArcs(line [][2]float64) []int {
+	arcs := []int{}
+	if len(line) == 0 {
+		return arcs
+	}
+	start := 0
+	for i := 1; i < len(line); i++ {
+		if i == len(line)-1 || b.junctions[line[i]] {
+			arcs = append(arcs, b.arc(line[start:i+1]))
+			start = i
+		}
+	}
+	return arcs
+}
+
+// polygonArcs returns the indices of the arcs of the rings
+func (b *builder) polygonArcs(rings [][][2]float64) [][]int {
+	arcs := make([][]int, len(rings))
+	for i, ring := range rings {
+		n := len(ring) - 1
+		// start at the first junction, or at the least position of rings without
+		// one so rings of the same positions share their arc
+		start, least := -1, 0
+		for j := 0; j < n; j++ {
+			if b.junctions[ring[j]] {
+				start = j
+				break
+			}
+			if less(ring[j], ring[least]) {
+				least = j
+			}
+		}
+		if start == -1 {
+			start = least
+		}
+		rotated := make([][2]float64, 0, len(ring))
+		rotated = append(rotated, ring[start:n]...)
+		rotated = append(rotated, ring[:start+1]...)
+		arcs[i] = b.lineArcs(rotated)
+	}
+	return arcs
+}
+
+func less(a, b [2]float64) bool {
+	return a[0] < b[0] || (a[0] == b[0] && a[1] < b[1])
+}
+
+// arc returns the index of the arc of the positions, or the ones' complement of the
+// index of the reversed arc
+func (b *builder) arc(pts [][2]float64) int {
+	if i, ok := b.index[key(pts, false)]; ok {
+		return i
+	}
+	if i, ok := b.index[key(pts, true)]; ok {
+		return ^i
+	}
+	i := len(b.arcs)
+	b.arcs = append(b.arcs, pts)
+	b.index[key(pts, false)] = i
+	return i
+}
+
+// key returns the positions, or the reversed positions, as a string
+func key(pts [][2]float64, reversed bool) string {
+	buf := make([]byte, 16*len(pts))
+	for i := range pts {
+		pt := pts[i]
+		if reversed {
+			pt = pts[len(pts)-1-i]
+		}
+		binary.LittleEndian.PutUint64(buf[16*i:], math.Float64bits(pt[0]))
+		binary.LittleEndian.PutUint64(buf[16*i+8:], math.Float64bits(pt[1]))
+	}
+	return string(buf)
+}
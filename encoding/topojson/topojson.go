@@ -0,0 +1,74 @@
+// Package topojson implements encoding and decoding of TopoJSON topologies as
+// described at https://github.com/topojson/topojson-specification.
+//
+// A topology has named objects, each a geojson.FeatureCollection of features whose
+// geometries have properties and an optional id. The lines and polygon rings of the
+// geometries are cut where they meet and stored once as arcs, so the border shared
+// by two polygons is written once. When quantized, the coordinates are integers on a
+// grid over the bounding box of all geometries and the positions of the arcs are
+// delta encoded.
+//
+// Only the x and y values of geometries are encoded.
+package topojson
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Options are the options for encoding a topology
+type Options struct {
+	// Quantization is the number of values of the grid along each axis, such as 1e4
+	// or 1e6; 0 for no quantization, otherwise at least 2
+	Quantization int
+}
+
+// topologyJSON is a topology document
+type topologyJSON struct {
+	Type      string                   `json:"type"`
+	BBox      []float64                `json:"bbox,omitempty"`
+	Transform *transformJSON           `json:"transform,omitempty"`
+	Objects   map[string]*geometryJSON `json:"objects"`
+	Arcs      [][][]float64            `json:"arcs"`
+}
+
+type transformJSON struct {
+	Scale     [2]float64 `json:"scale"`
+	Translate [2]float64 `json:"translate"`
+}
+
+// geometryJSON is a geometry object; a null type is a geometry object without a geometry
+type geometryJSON struct {
+	Type        *string                `json:"type"`
+	ID          interface{}            `json:"id,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+	Coordinates json.RawMessage        `json:"coordinates,omitempty"`
+	Arcs        json.RawMessage        `json:"arcs,omitempty"`
+	Geometries  []*geometryJSON        `json:"geometries,omitempty"`
+}
+
+// geometry types
+const (
+	typePoint              = "Point"
+	typeMultiPoint         = "MultiPoint"
+	typeLineString         = "LineString"
+	typeMultiLineString    = "MultiLineString"
+	typePolygon            = "Polygon"
+	typeMultiPolygon       = "MultiPolygon"
+	typeGeometryCollection = "GeometryCollection"
+)
+
+// ErrInvalidArc is returned when decoding a geometry with the index of an arc that
+// is not in the topology
+type ErrInvalidArc int
+
+func (e ErrInvalidArc) Error() string {
+	return fmt.Sprintf("invalid arc index %v", int(e))
+}
+
+// ErrUnknownType is returned when decoding a geometry object of an unknown type
+type ErrUnknownType string
+
+func (e ErrUnknownType) Error() string {
+	return fmt.Sprintf("unknown geometry type %q", string(e))
+}
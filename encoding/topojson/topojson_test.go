@@ -0,0 +1,242 @@
+package topojson_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+	"github.com/hahaking119/geom/encoding/geojson"
+	"github.com/hahaking119/geom/encoding/topojson"
+)
+
+func feature(geo geom.Geometry) geojson.Feature {
+	return geojson.Feature{Geometry: geojson.Geometry{Geometry: geo}}
+}
+
+func TestMarshal(t *testing.T) {
+	type tcase struct {
+		objects  map[string]geojson.FeatureCollection
+		opts     topojson.Options
+		expected string
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := topojson.Marshal(tc.objects, tc.opts)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("topology, expected\n%v\ngot\n%s", tc.expected, got)
+			}
+		}
+	}
+
+	squares := map[string]geojson.FeatureCollection{
+		"squares": {Features: []geojson.Feature{
+			feature(geom.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}),
+			feature(geom.Polygon{{{1, 0}, {2, 0}, {2, 1}, {1, 1}}}),
+		}},
+	}
+
+	tests := map[string]tcase{
+		"shared edge": {
+			objects: squares,
+			expected: `{"type":"Topology","bbox":[0,0,2,1],` +
+				`"objects":{"squares":{"type":"GeometryCollection","geometries":[{"type":"Polygon","arcs":[[0,1]]},{"type":"Polygon","arcs":[[2,-1]]}]}},` +
+				`"arcs":[[[1,0],[1,1]],[[1,1],[0,1],[0,0],[1,0]],[[1,0],[2,0],[2,1],[1,1]]]}`,
+		},
+		"quantized": {
+			objects: squares,
+			opts:    topojson.Options{Quantization: 3},
+			expected: `{"type":"Topology","bbox":[0,0,2,1],"transform":{"scale":[1,0.5],"translate":[0,0]},` +
+				`"objects":{"squares":{"type":"GeometryCollection","geometries":[{"type":"Polygon","arcs":[[0,1]]},{"type":"Polygon","arcs":[[2,-1]]}]}},` +
+				`"arcs":[[[1,0],[0,2]],[[1,2],[-1,0],[0,-2],[1,0]],[[1,0],[1,0],[0,2],[-1,0]]]}`,
+		},
+		"same ring rotated and reversed": {
+			objects: map[string]geojson.FeatureCollection{
+				"a": {Features: []geojson.Feature{feature(geom.Polygon{{{1, 1}, {0, 1}, {0, 0}, {1, 0}}})}},
+				"b": {Features: []geojson.Feature{feature(geom.Polygon{{{0, 0}, {0, 1}, {1, 1}, {1, 0}}})}},
+			},
+			expected: `{"type":"Topology","bbox":[0,0,1,1],` +
+				`"objects":{"a":{"type":"GeometryCollection","geometries":[{"type":"Polygon","arcs":[[0]]}]},` +
+				`"b":{"type":"GeometryCollection","geometries":[{"type":"Polygon","arcs":[[-1]]}]}},` +
+				`"arcs":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`,
+		},
+		"lines crossing": {
+			objects: map[string]geojson.FeatureCollection{
+				"lines": {Features: []geojson.Feature{
+					feature(geom.LineString{{0, 1}, {1, 1}, {2, 1}}),
+					feature(geom.LineString{{1, 0}, {1, 1}, {1, 2}}),
+				}},
+			},
+			expected: `{"type":"Topology","bbox":[0,0,2,2],` +
+				`"objects":{"lines":{"type":"GeometryCollection","geometries":[{"type":"LineString","arcs":[0,1]},{"type":"LineString","arcs":[2,3]}]}},` +
+				`"arcs":[[[0,1],[1,1]],[[1,1],[2,1]],[[1,0],[1,1]],[[1,1],[1,2]]]}`,
+		},
+		"points and properties": {
+			objects: map[string]geojson.FeatureCollection{
+				"points": {Features: []geojson.Feature{
+					{
+						ID:         func() *uint64 { id := uint64(7); return &id }(),
+						Geometry:   geojson.Geometry{Geometry: geom.Point{1, 2}},
+						Properties: map[string]interface{}{"name": "a"},
+					},
+					feature(geom.MultiPoint{}),
+					feature(nil),
+				}},
+			},
+			expected: `{"type":"Topology","bbox":[1,2,1,2],` +
+				`"objects":{"points":{"type":"GeometryCollection","geometries":[{"type":"Point","id":7,"properties":{"name":"a"},"coordinates":[1,2]},` +
+				`{"type":"MultiPoint","coordinates":[]},{"type":null}]}},"arcs":[]}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	type tcase struct {
+		features []geojson.Feature
+		opts     topojson.Options
+		// expected are the features read back, features if nil
+		expected []geojson.Feature
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			b, err := topojson.Marshal(map[string]geojson.FeatureCollection{"o": {Features: tc.features}}, tc.opts)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			objects, err := topojson.Unmarshal(b)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			expected := tc.expected
+			if expected == nil {
+				expected = tc.features
+			}
+			if got := objects["o"].Features; !reflect.DeepEqual(got, expected) {
+				t.Errorf("features, expected %v got %v\n%s", expected, got, b)
+			}
+		}
+	}
+
+	id := uint64(42)
+	tests := map[string]tcase{
+		"geometries": {
+			features: []geojson.Feature{
+				feature(geom.Point{1, 2}),
+				feature(geom.MultiPoint{{1, 2}, {3, 4}}),
+				feature(geom.LineString{{0, 0}, {1, 1}, {2, 0}}),
+				feature(geom.MultiLineString{{{0, 0}, {1, 1}}, {{1, 1}, {2, 0}}}),
+				feature(geom.Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}, {{1, 1}, {1, 2}, {2, 2}, {2, 1}}}),
+				feature(geom.MultiPolygon{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}}, {{{5, 5}, {6, 5}, {6, 6}}}}),
+				feature(geom.Collection{geom.Point{1, 2}, geom.LineString{{0, 0}, {1, 1}}}),
+			},
+		},
+		"properties and id": {
+			features: []geojson.Feature{
+				{
+					ID:         &id,
+					Geometry:   geojson.Geometry{Geometry: geom.Point{1, 2}},
+					Properties: map[string]interface{}{"name": "a", "n": 1.5, "ok": true},
+				},
+				{Properties: map[string]interface{}{"empty": nil}},
+			},
+		},
+		"quantized": {
+			features: []geojson.Feature{
+				feature(geom.Point{10, 20}),
+				feature(geom.LineString{{0, 0}, {0.1, 0.1}, {10, 20}}),
+				// collapses to fewer than three positions
+				feature(geom.Polygon{{{0, 0}, {0.1, 0}, {0.1, 0.1}}}),
+				feature(geom.Polygon{{{0, 0}, {5, 0}, {5, 4.1}, {0, 4}}, {{1, 1}, {1.1, 1}, {1.1, 1.1}}}),
+			},
+			opts: topojson.Options{Quantization: 11},
+			expected: []geojson.Feature{
+				feature(geom.Point{10, 20}),
+				feature(geom.LineString{{0, 0}, {10, 20}}),
+				feature(geom.Polygon{}),
+				feature(geom.Polygon{{{0, 0}, {5, 0}, {5, 4}, {0, 4}}}),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	type tcase struct {
+		topology string
+		expected map[string]geojson.FeatureCollection
+		err      bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := topojson.Unmarshal([]byte(tc.topology))
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("objects, expected %v got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"single geometry object": {
+			topology: `{"type":"Topology","transform":{"scale":[0.5,2],"translate":[10,20]},
+				"objects":{"line":{"type":"LineString","id":"ignored","properties":{"a":1},"arcs":[-1]}},
+				"arcs":[[[0,0],[2,1],[2,-1]]]}`,
+			expected: map[string]geojson.FeatureCollection{
+				"line": {Features: []geojson.Feature{{
+					Geometry:   geojson.Geometry{Geometry: geom.LineString{{12, 20}, {11, 22}, {10, 20}}},
+					Properties: map[string]interface{}{"a": 1.0},
+				}}},
+			},
+		},
+		"not a topology":  {topology: `{"type":"FeatureCollection"}`, err: true},
+		"invalid arc":     {topology: `{"type":"Topology","objects":{"a":{"type":"LineString","arcs":[1]}},"arcs":[[[0,0],[1,1]]]}`, err: true},
+		"invalid ~arc":    {topology: `{"type":"Topology","objects":{"a":{"type":"LineString","arcs":[-2]}},"arcs":[[[0,0],[1,1]]]}`, err: true},
+		"unknown type":    {topology: `{"type":"Topology","objects":{"a":{"type":"Circle"}},"arcs":[]}`, err: true},
+		"missing arcs":    {topology: `{"type":"Topology","objects":{"a":{"type":"Polygon"}},"arcs":[]}`, err: true},
+		"short position":  {topology: `{"type":"Topology","objects":{},"arcs":[[[0]]]}`, err: true},
+		"invalid json":    {topology: `{`, err: true},
+		"null object":     {topology: `{"type":"Topology","objects":{"a":null},"arcs":[]}`, err: true},
+		"invalid coords":  {topology: `{"type":"Topology","objects":{"a":{"type":"Point","coordinates":"a"}},"arcs":[]}`, err: true},
+		"invalid polygon": {topology: `{"type":"Topology","objects":{"a":{"type":"MultiPolygon","arcs":[[[3]]]}},"arcs":[]}`, err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	objects := map[string]geojson.FeatureCollection{"o": {Features: []geojson.Feature{feature(geom.Point{1, 2})}}}
+	for _, q := range []int{-1, 1} {
+		if _, err := topojson.Marshal(objects, topojson.Options{Quantization: q}); err == nil {
+			t.Errorf("quantization %v error, expected error got nil", q)
+		}
+	}
+
+	objects = map[string]geojson.FeatureCollection{"o": {Features: []geojson.Feature{feature(geom.Circle{Center: [2]float64{1, 2}, Radius: 1})}}}
+	_, err := topojson.Marshal(objects, topojson.Options{})
+	if _, ok := err.(encoding.ErrUnknownGeometry); !ok {
+		t.Errorf("error, expected %T got %v", encoding.ErrUnknownGeometry{}, err)
+	}
+}
@@ -0,0 +1,435 @@
+package twkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/hahaking119/geom"
+)
+
+// Decode reads a TWKB encoded geometry from r. Only the bytes of the geometry are read.
+// An empty point is decoded as nil, other empty geometries as empty values of their type.
+func Decode(r io.Reader) (geom.Geometry, error) {
+	geo, _, err := DecodeWithMetadata(r)
+	return geo, err
+}
+
+// DecodeBytes decodes the TWKB encoded geometry. See Decode.
+func DecodeBytes(b []byte) (geom.Geometry, error) {
+	return Decode(bytes.NewReader(b))
+}
+
+// DecodeWithMetadata reads a TWKB encoded geometry from r, along with the metadata of
+// its header. See Decode.
+func DecodeWithMetadata(r io.Reader) (geom.Geometry, Metadata, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = byteReader{r}
+	}
+	return decoder{r: br}.geometry()
+}
+
+// byteReader reads single bytes, so no more than the geometry is read from r
+type byteReader struct {
+	r io.Reader
+}
+
+func (br byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(br.r, b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+type decoder struct {
+	r io.ByteReader
+}
+
+// geometry decodes the header and the body of a geometry
+func (dec decoder) geometry() (geo geom.Geometry, md Metadata, err error) {
+	head, err := dec.r.ReadByte()
+	if err != nil {
+		return nil, md, err
+	}
+	typ := head & 0x0f
+	if typ < Point || typ > Collection {
+		return nil, md, ErrUnknownGeometryType{Typ: typ}
+	}
+	// the precision is a zigzag encoded 4 bit int
+	precision := int(head >> 4)
+	md.Precision = (precision >> 1) ^ -(precision & 1)
+
+	meta, err := dec.r.ReadByte()
+	if err != nil {
+		return nil, md, io.ErrUnexpectedEOF
+	}
+	if meta&flagExtendedDims != 0 {
+		ext, err := dec.r.ReadByte()
+		if err != nil {
+			return nil, md, io.ErrUnexpectedEOF
+		}
+		md.HasZ = ext&extendedHasZ != 0
+		md.HasM = ext&extendedHasM != 0
+		md.PrecisionZ = int(ext>>2) & 0x07
+		md.PrecisionM = int(ext>>5) & 0x07
+	}
+	s := shape{typ: typ, hasZ: md.HasZ, hasM: md.HasM}
+	if meta&flagEmptyGeometry != 0 {
+		geo, err = s.geometry()
+		return geo, md, err
+	}
+
+	if meta&flagSize != 0 {
+		// the size is only useful to skip the geometry
+		if _, err = dec.uvarint(); err != nil {
+			return nil, md, err
+		}
+	}
+
+	precisions := []int{md.Precision, md.Precision}
+	if md.HasZ {
+		precisions = append(precisions, md.PrecisionZ)
+	}
+	if md.HasM {
+		precisions = append(precisions, md.PrecisionM)
+	}
+
+	if meta&flagBBox != 0 {
+		var bbox [2][2]float64
+		for d := range precisions {
+			min, err := dec.varint()
+			if err != nil {
+				return nil, md, err
+			}
+			delta, err := dec.varint()
+			if err != nil {
+				return nil, md, err
+			}
+			if d < 2 {
+				bbox[0][d] = unscale(min, precisions[d])
+				bbox[1][d] = unscale(min+delta, precisions[d])
+			}
+		}
+		md.BBox = geom.NewExtent(bbox[0], bbox[1])
+	}
+
+	last := make([]int64, len(precisions))
+	point := func() ([]float64, error) {
+		pt := make([]float64, len(precisions))
+		for d := range precisions {
+			v, err := dec.varint()
+			if err != nil {
+				return nil, err
+			}
+			last[d] += v
+			pt[d] = unscale(last[d], precisions[d])
+		}
+		return pt, nil
+	}
+	points := func(n uint64) ([][]float64, error) {
+		var pts [][]float64
+		for i := uint64(0); i < n; i++ {
+			pt, err := point()
+			if err != nil {
+				return nil, err
+			}
+			pts = append(pts, pt)
+		}
+		return pts, nil
+	}
+	line := func() ([][]float64, error) {
+		n, err := dec.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		return points(n)
+	}
+	lines := func(n uint64) ([][][]float64, error) {
+		var ls [][][]float64
+		for i := uint64(0); i < n; i++ {
+			l, err := line()
+			if err != nil {
+				return nil, err
+			}
+			ls = append(ls, l)
+		}
+		return ls, nil
+	}
+	polygon := func() ([][][]float64, error) {
+		n, err := dec.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		rings, err := lines(n)
+		if err != nil {
+			return nil, err
+		}
+		for i := range rings {
+			rings[i] = openRing(rings[i])
+		}
+		return rings, nil
+	}
+	// count reads the number of parts and the id list
+	count := func() (uint64, error) {
+		n, err := dec.uvarint()
+		if err != nil || meta&flagIDList == 0 {
+			return n, err
+		}
+		md.IDs = []int64{}
+		for i := uint64(0); i < n; i++ {
+			id, err := dec.varint()
+			if err != nil {
+				return 0, err
+			}
+			md.IDs = append(md.IDs, id)
+		}
+		return n, nil
+	}
+
+	var (
+		pts   [][]float64
+		rings [][][]float64
+	)
+	switch typ {
+	case Point:
+		var pt []float64
+		if pt, err = point(); err == nil {
+			s.polys = [][][][]float64{{{pt}}}
+		}
+	case LineString:
+		if pts, err = line(); err == nil {
+			s.polys = [][][][]float64{{pts}}
+		}
+	case Polygon:
+		if rings, err = polygon(); err == nil {
+			s.polys = [][][][]float64{rings}
+		}
+	case MultiPoint:
+		var n uint64
+		if n, err = count(); err == nil {
+			if pts, err = points(n); err == nil {
+				s.polys = [][][][]float64{{pts}}
+			}
+		}
+	case MultiLineString:
+		var n uint64
+		if n, err = count(); err == nil {
+			if rings, err = lines(n); err == nil {
+				s.polys = [][][][]float64{rings}
+			}
+		}
+	case MultiPolygon:
+		var n uint64
+		if n, err = count(); err == nil {
+			for i := uint64(0); i < n && err == nil; i++ {
+				if rings, err = polygon(); err == nil {
+					s.polys = append(s.polys, rings)
+				}
+			}
+		}
+	case Collection:
+		var n uint64
+		if n, err = count(); err == nil {
+			for i := uint64(0); i < n && err == nil; i++ {
+				var g geom.Geometry
+				if g, _, err = dec.geometry(); err == nil {
+					s.geoms = append(s.geoms, g)
+				}
+			}
+		}
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, md, err
+	}
+
+	geo, err = s.geometry()
+	return geo, md, err
+}
+
+func (dec decoder) uvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(dec.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// varint reads a zigzag encoded varint
+func (dec decoder) varint() (int64, error) {
+	v, err := binary.ReadVarint(dec.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// unscale returns the value of the integer encoded with the precision. Dividing
+// by the power of ten gives the closest float to the decimal value.
+func unscale(v int64, precision int) float64 {
+	if precision < 0 {
+		return float64(v) * math.Pow10(-precision)
+	}
+	return float64(v) / math.Pow10(precision)
+}
+
+// openRing returns the ring without the closing point
+func openRing(r [][]float64) [][]float64 {
+	if len(r) < 2 {
+		return r
+	}
+	first, last := r[0], r[len(r)-1]
+	for d := range first {
+		if first[d] != last[d] {
+			return r
+		}
+	}
+	return r[:len(r)-1]
+}
+
+// geometry returns the geom geometry of the shape
+func (s shape) geometry() (geom.Geometry, error) {
+	// the coordinates of the parts, nil for an empty shape
+	var (
+		pts   [][]float64
+		rings [][][]float64
+	)
+	if len(s.polys) > 0 {
+		rings = s.polys[0]
+		if len(rings) > 0 {
+			pts = rings[0]
+		}
+	}
+
+	switch {
+	case !s.hasZ && !s.hasM:
+		switch s.typ {
+		case Point:
+			if len(pts) == 0 {
+				return nil, nil
+			}
+			return geom.Point(to2(pts)[0]), nil
+		case LineString:
+			return geom.LineString(to2(pts)), nil
+		case Polygon:
+			return geom.Polygon(rings2D(rings)), nil
+		case MultiPoint:
+			return geom.MultiPoint(to2(pts)), nil
+		case MultiLineString:
+			return geom.MultiLineString(rings2D(rings)), nil
+		case MultiPolygon:
+			mp := make(geom.MultiPolygon, len(s.polys))
+			for i := range s.polys {
+				mp[i] = rings2D(s.polys[i])
+			}
+			return mp, nil
+		case Collection:
+			return geom.Collection(s.geoms), nil
+		}
+
+	case s.hasZ && !s.hasM:
+		switch s.typ {
+		case Point:
+			if len(pts) == 0 {
+				return nil, nil
+			}
+			return geom.PointZ(to3(pts)[0]), nil
+		case LineString:
+			return geom.LineStringZ(to3(pts)), nil
+		case Polygon:
+			return geom.PolygonZ(rings3D(rings)), nil
+		case MultiPoint:
+			return geom.MultiPointZ(to3(pts)), nil
+		case MultiLineString:
+			return geom.MultiLineStringZ(rings3D(rings)), nil
+		}
+
+	case !s.hasZ && s.hasM:
+		switch s.typ {
+		case Point:
+			if len(pts) == 0 {
+				return nil, nil
+			}
+			return geom.PointM(to3(pts)[0]), nil
+		case LineString:
+			return geom.LineStringM(to3(pts)), nil
+		case Polygon:
+			return geom.PolygonM(rings3D(rings)), nil
+		case MultiPoint:
+			return geom.MultiPointM(to3(pts)), nil
+		case MultiLineString:
+			return geom.MultiLineStringM(rings3D(rings)), nil
+		}
+
+	default:
+		switch s.typ {
+		case Point:
+			if len(pts) == 0 {
+				return nil, nil
+			}
+			return geom.PointZM(to4(pts)[0]), nil
+		case LineString:
+			return geom.LineStringZM(to4(pts)), nil
+		case Polygon:
+			return geom.PolygonZM(rings4D(rings)), nil
+		case MultiPoint:
+			return geom.MultiPointZM(to4(pts)), nil
+		case MultiLineString:
+			return geom.MultiLineStringZM(rings4D(rings)), nil
+		}
+	}
+	return nil, ErrUnsupportedDimensions{Typ: s.typ, HasZ: s.hasZ, HasM: s.hasM}
+}
+
+func to2(pts [][]float64) [][2]float64 {
+	cs := make([][2]float64, len(pts))
+	for i := range pts {
+		copy(cs[i][:], pts[i])
+	}
+	return cs
+}
+
+func to3(pts [][]float64) [][3]float64 {
+	cs := make([][3]float64, len(pts))
+	for i := range pts {
+		copy(cs[i][:], pts[i])
+	}
+	return cs
+}
+
+func to4(pts [][]float64) [][4]float64 {
+	cs := make([][4]float64, len(pts))
+	for i := range pts {
+		copy(cs[i][:], pts[i])
+	}
+	return cs
+}
+
+func rings2D(rs [][][]float64) [][][2]float64 {
+	cs := make([][][2]float64, len(rs))
+	for i := range rs {
+		cs[i] = to2(rs[i])
+	}
+	return cs
+}
+
+func rings3D(rs [][][]float64) [][][3]float64 {
+	cs := make([][][3]float64, len(rs))
+	for i := range rs {
+		cs[i] = to3(rs[i])
+	}
+	return cs
+}
+
+func rings4D(rs [][][]float64) [][][4]float64 {
+	cs := make([][][4]float64, len(rs))
+	for i := range rs {
+		cs[i] = to4(rs[i])
+	}
+	return cs
+}
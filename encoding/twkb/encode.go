@@ -0,0 +1,381 @@
+package twkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding"
+)
+
+// Encode writes the geometry encoded as TWKB to w
+func Encode(w io.Writer, geo geom.Geometry, opts Options) error {
+	return EncodeWithIDs(w, geo, nil, opts)
+}
+
+// EncodeBytes returns the geometry encoded as TWKB
+func EncodeBytes(geo geom.Geometry, opts Options) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := Encode(buf, geo, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeWithIDs writes the multi geometry or collection encoded as TWKB, with the ids
+// of its parts, to w. There must be an id for each part.
+func EncodeWithIDs(w io.Writer, geo geom.Geometry, ids []int64, opts Options) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	s, err := newShape(geo)
+	if err != nil {
+		return err
+	}
+	if ids != nil {
+		switch s.typ {
+		case MultiPoint, MultiLineString, MultiPolygon, Collection:
+			if len(ids) != s.parts() {
+				return fmt.Errorf("%v ids for %v parts", len(ids), s.parts())
+			}
+		default:
+			return fmt.Errorf("ids are only supported for multi geometries and collections")
+		}
+	}
+
+	var enc encoder
+	if err = enc.shape(s, ids, opts); err != nil {
+		return err
+	}
+	_, err = w.Write(enc.buf.Bytes())
+	return err
+}
+
+type encoder struct {
+	buf     bytes.Buffer
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (enc *encoder) uvarint(v uint64) {
+	n := binary.PutUvarint(enc.scratch[:], v)
+	enc.buf.Write(enc.scratch[:n])
+}
+
+// varint writes the zigzag encoded varint
+func (enc *encoder) varint(v int64) {
+	n := binary.PutVarint(enc.scratch[:], v)
+	enc.buf.Write(enc.scratch[:n])
+}
+
+// shape writes the header and body of the shape
+func (enc *encoder) shape(s shape, ids []int64, opts Options) error {
+	// the precision is a zigzag encoded 4 bit int
+	precision := byte((opts.Precision<<1)^(opts.Precision>>31)) & 0x0f
+	enc.buf.WriteByte(s.typ | precision<<4)
+
+	var meta, ext byte
+	if s.hasZ || s.hasM {
+		meta |= flagExtendedDims
+		if s.hasZ {
+			ext |= extendedHasZ | byte(opts.PrecisionZ)<<2
+		}
+		if s.hasM {
+			ext |= extendedHasM | byte(opts.PrecisionM)<<5
+		}
+	}
+	if s.empty() {
+		enc.buf.WriteByte(meta | flagEmptyGeometry)
+		if meta&flagExtendedDims != 0 {
+			enc.buf.WriteByte(ext)
+		}
+		return nil
+	}
+	if opts.BBox {
+		meta |= flagBBox
+	}
+	if opts.Size {
+		meta |= flagSize
+	}
+	if ids != nil {
+		meta |= flagIDList
+	}
+	enc.buf.WriteByte(meta)
+	if meta&flagExtendedDims != 0 {
+		enc.buf.WriteByte(ext)
+	}
+
+	precisions := []int{opts.Precision, opts.Precision}
+	if s.hasZ {
+		precisions = append(precisions, opts.PrecisionZ)
+	}
+	if s.hasM {
+		precisions = append(precisions, opts.PrecisionM)
+	}
+
+	// the size is of the bbox and body, so they are written to their own encoder first
+	var body encoder
+	if opts.BBox {
+		bbox, err := s.bbox(precisions)
+		if err != nil {
+			return err
+		}
+		for d := range bbox[0] {
+			body.varint(bbox[0][d])
+			body.varint(bbox[1][d] - bbox[0][d])
+		}
+	}
+
+	last := make([]int64, len(precisions))
+	point := func(pt []float64) {
+		for d := range last {
+			v := scale(pt[d], precisions[d])
+			body.varint(v - last[d])
+			last[d] = v
+		}
+	}
+	line := func(pts [][]float64) {
+		body.uvarint(uint64(len(pts)))
+		for _, pt := range pts {
+			point(pt)
+		}
+	}
+	polygon := func(rings [][][]float64) {
+		body.uvarint(uint64(len(rings)))
+		for _, r := range rings {
+			line(closeRing(r))
+		}
+	}
+	idList := func() {
+		for _, id := range ids {
+			body.varint(id)
+		}
+	}
+
+	switch s.typ {
+	case Point:
+		point(s.polys[0][0][0])
+	case LineString:
+		line(s.polys[0][0])
+	case Polygon:
+		polygon(s.polys[0])
+	case MultiPoint:
+		body.uvarint(uint64(len(s.polys[0][0])))
+		idList()
+		for _, pt := range s.polys[0][0] {
+			point(pt)
+		}
+	case MultiLineString:
+		body.uvarint(uint64(len(s.polys[0])))
+		idList()
+		for _, l := range s.polys[0] {
+			line(l)
+		}
+	case MultiPolygon:
+		body.uvarint(uint64(len(s.polys)))
+		idList()
+		for _, p := range s.polys {
+			polygon(p)
+		}
+	case Collection:
+		body.uvarint(uint64(len(s.geoms)))
+		idList()
+		for _, g := range s.geoms {
+			gs, err := newShape(g)
+			if err != nil {
+				return err
+			}
+			if err = body.shape(gs, nil, opts); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.Size {
+		enc.uvarint(uint64(body.buf.Len()))
+	}
+	enc.buf.Write(body.buf.Bytes())
+	return nil
+}
+
+// bbox returns the min and max of the scaled values of each dimension; of a collection
+// only the x and y values are used.
+func (s shape) bbox(precisions []int) (bbox [2][]int64, err error) {
+	bbox[0] = make([]int64, len(precisions))
+	bbox[1] = make([]int64, len(precisions))
+	first := true
+	add := func(pt []float64) {
+		for d := range precisions {
+			v := scale(pt[d], precisions[d])
+			if first || v < bbox[0][d] {
+				bbox[0][d] = v
+			}
+			if first || v > bbox[1][d] {
+				bbox[1][d] = v
+			}
+		}
+		first = false
+	}
+
+	var addShape func(s shape) error
+	addShape = func(s shape) error {
+		for _, g := range s.geoms {
+			gs, err := newShape(g)
+			if err != nil {
+				return err
+			}
+			if err = addShape(gs); err != nil {
+				return err
+			}
+		}
+		for _, ply := range s.polys {
+			for _, r := range ply {
+				for _, pt := range r {
+					add(pt)
+				}
+			}
+		}
+		return nil
+	}
+	return bbox, addShape(s)
+}
+
+// scale returns the value scaled by the precision, rounded to an integer
+func scale(v float64, precision int) int64 {
+	if precision < 0 {
+		return int64(math.Round(v / math.Pow10(-precision)))
+	}
+	return int64(math.Round(v * math.Pow10(precision)))
+}
+
+// closeRing returns the ring with the first point repeated at the end
+func closeRing(r [][]float64) [][]float64 {
+	if len(r) == 0 {
+		return r
+	}
+	first, last := r[0], r[len(r)-1]
+	for d := range first {
+		if first[d] != last[d] {
+			return append(r[:len(r):len(r)], first)
+		}
+	}
+	return r
+}
+
+// newShape returns the shape of the geometry
+func newShape(geo geom.Geometry) (shape, error) {
+	one := func(pts [][]float64) [][][][]float64 { return [][][][]float64{{pts}} }
+
+	switch g := geo.(type) {
+	// the z and m types implement the 2d interfaces, so are matched first
+	case geom.PointZ:
+		return shape{typ: Point, hasZ: true, polys: one(coords3(g))}, nil
+	case geom.PointM:
+		return shape{typ: Point, hasM: true, polys: one(coords3(g))}, nil
+	case geom.PointZM:
+		return shape{typ: Point, hasZ: true, hasM: true, polys: one(coords4(g))}, nil
+	case geom.LineStringZ:
+		return shape{typ: LineString, hasZ: true, polys: one(coords3(g...))}, nil
+	case geom.LineStringM:
+		return shape{typ: LineString, hasM: true, polys: one(coords3(g...))}, nil
+	case geom.LineStringZM:
+		return shape{typ: LineString, hasZ: true, hasM: true, polys: one(coords4(g...))}, nil
+	case geom.MultiPointZ:
+		return shape{typ: MultiPoint, hasZ: true, polys: one(coords3(g...))}, nil
+	case geom.MultiPointM:
+		return shape{typ: MultiPoint, hasM: true, polys: one(coords3(g...))}, nil
+	case geom.MultiPointZM:
+		return shape{typ: MultiPoint, hasZ: true, hasM: true, polys: one(coords4(g...))}, nil
+	case geom.PolygonZ:
+		return shape{typ: Polygon, hasZ: true, polys: [][][][]float64{rings3(g)}}, nil
+	case geom.PolygonM:
+		return shape{typ: Polygon, hasM: true, polys: [][][][]float64{rings3(g)}}, nil
+	case geom.PolygonZM:
+		return shape{typ: Polygon, hasZ: true, hasM: true, polys: [][][][]float64{rings4(g)}}, nil
+	case geom.MultiLineStringZ:
+		return shape{typ: MultiLineString, hasZ: true, polys: [][][][]float64{rings3(g)}}, nil
+	case geom.MultiLineStringM:
+		return shape{typ: MultiLineString, hasM: true, polys: [][][][]float64{rings3(g)}}, nil
+	case geom.MultiLineStringZM:
+		return shape{typ: MultiLineString, hasZ: true, hasM: true, polys: [][][][]float64{rings4(g)}}, nil
+
+	case *geom.MultiPolygon:
+		if g == nil {
+			return shape{}, encoding.ErrUnknownGeometry{Geom: geo}
+		}
+		return newShape(*g)
+
+	case geom.Pointer:
+		return shape{typ: Point, polys: one(coords2(g.XY()))}, nil
+	case geom.MultiPointer:
+		return shape{typ: MultiPoint, polys: one(coords2(g.Points()...))}, nil
+	case geom.LineStringer:
+		return shape{typ: LineString, polys: one(coords2(g.Vertices()...))}, nil
+	case geom.MultiLineStringer:
+		return shape{typ: MultiLineString, polys: [][][][]float64{rings2(g.LineStrings())}}, nil
+	case geom.Polygoner:
+		return shape{typ: Polygon, polys: [][][][]float64{rings2(g.LinearRings())}}, nil
+	case geom.MultiPolygoner:
+		plys := g.Polygons()
+		polys := make([][][][]float64, len(plys))
+		for i := range plys {
+			polys[i] = rings2(plys[i])
+		}
+		return shape{typ: MultiPolygon, polys: polys}, nil
+	case geom.Collectioner:
+		return shape{typ: Collection, geoms: g.Geometries()}, nil
+
+	default:
+		return shape{}, encoding.ErrUnknownGeometry{Geom: geo}
+	}
+}
+
+func coords2(pts ...[2]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func coords3(pts ...[3]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func coords4(pts ...[4]float64) [][]float64 {
+	cs := make([][]float64, len(pts))
+	for i := range pts {
+		cs[i] = pts[i][:]
+	}
+	return cs
+}
+
+func rings2(rs [][][2]float64) [][][]float64 {
+	cs := make([][][]float64, len(rs))
+	for i := range rs {
+		cs[i] = coords2(rs[i]...)
+	}
+	return cs
+}
+
+func rings3(rs [][][3]float64) [][][]float64 {
+	cs := make([][][]float64, len(rs))
+	for i := range rs {
+		cs[i] = coords3(rs[i]...)
+	}
+	return cs
+}
+
+func rings4(rs [][][4]float64) [][][]float64 {
+	cs := make([][][]float64, len(rs))
+	for i := range rs {
+		cs[i] = coords4(rs[i]...)
+	}
+	return cs
+}
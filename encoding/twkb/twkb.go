@@ -0,0 +1,171 @@
+// Package twkb implements encoding and decoding of Tiny Well Known Binary (TWKB)
+// as described at https://github.com/TWKB/Specification. TWKB stores coordinates
+// as integers, scaled by a decimal precision, delta encoded from the previous
+// coordinate and written as varints.
+//
+// The 2D geometry types, the Z, M and ZM variants of points, line strings, polygons,
+// multi points and multi line strings, and collections are supported.
+package twkb
+
+import (
+	"fmt"
+
+	"github.com/hahaking119/geom"
+)
+
+// geometry types
+const (
+	Point           = 1
+	LineString      = 2
+	Polygon         = 3
+	MultiPoint      = 4
+	MultiLineString = 5
+	MultiPolygon    = 6
+	Collection      = 7
+)
+
+// bits of the metadata header
+const (
+	flagBBox          = 1 << 0
+	flagSize          = 1 << 1
+	flagIDList        = 1 << 2
+	flagExtendedDims  = 1 << 3
+	flagEmptyGeometry = 1 << 4
+)
+
+// bits of the extended dimensions header
+const (
+	extendedHasZ = 1 << 0
+	extendedHasM = 1 << 1
+)
+
+// the range of the precisions
+const (
+	minPrecision         = -8
+	maxPrecision         = 7
+	maxExtendedPrecision = 7
+)
+
+// ErrUnknownGeometryType is returned when decoding a geometry type that is not known
+type ErrUnknownGeometryType struct {
+	Typ byte
+}
+
+func (e ErrUnknownGeometryType) Error() string {
+	return fmt.Sprintf("unknown geometry type %v", e.Typ)
+}
+
+// ErrUnsupportedDimensions is returned when decoding a geometry type with Z or M
+// values that has no geom type with those dimensions
+type ErrUnsupportedDimensions struct {
+	Typ        byte
+	HasZ, HasM bool
+}
+
+func (e ErrUnsupportedDimensions) Error() string {
+	return fmt.Sprintf("geometry type %v with z (%v) and m (%v) is not supported", e.Typ, e.HasZ, e.HasM)
+}
+
+// ErrInvalidPrecision is returned when encoding with a precision out of range
+type ErrInvalidPrecision struct {
+	Dimension string
+	Precision int
+}
+
+func (e ErrInvalidPrecision) Error() string {
+	return fmt.Sprintf("invalid precision %v for %v", e.Precision, e.Dimension)
+}
+
+// Options are the options used to encode geometries
+type Options struct {
+	// Precision is the number of decimal digits of the x and y values that are kept,
+	// from -8 to 7; negative values round to tens, hundreds and so on.
+	Precision int
+	// PrecisionZ is the number of decimal digits of the z values that are kept, from 0 to 7
+	PrecisionZ int
+	// PrecisionM is the number of decimal digits of the m values that are kept, from 0 to 7
+	PrecisionM int
+	// BBox adds the bounding box of the geometry to the header
+	BBox bool
+	// Size adds the size, in bytes, of the rest of the geometry to the header,
+	// so readers can skip it
+	Size bool
+}
+
+func (o Options) validate() error {
+	if o.Precision < minPrecision || o.Precision > maxPrecision {
+		return ErrInvalidPrecision{Dimension: "xy", Precision: o.Precision}
+	}
+	if o.PrecisionZ < 0 || o.PrecisionZ > maxExtendedPrecision {
+		return ErrInvalidPrecision{Dimension: "z", Precision: o.PrecisionZ}
+	}
+	if o.PrecisionM < 0 || o.PrecisionM > maxExtendedPrecision {
+		return ErrInvalidPrecision{Dimension: "m", Precision: o.PrecisionM}
+	}
+	return nil
+}
+
+// Metadata is the header information of a decoded geometry
+type Metadata struct {
+	// Precision, PrecisionZ and PrecisionM are the precisions the values were encoded with
+	Precision, PrecisionZ, PrecisionM int
+	// HasZ and HasM report whether the geometry has z and m values
+	HasZ, HasM bool
+	// BBox is the bounding box of the x and y values, if it was encoded
+	BBox *geom.Extent
+	// IDs are the ids of the parts of a multi geometry or collection, if they were encoded
+	IDs []int64
+}
+
+// shape is a geometry with its coordinates in the layout of the encoding.
+// Every geometry type is held as a list of polygons: a point is a single polygon
+// with a ring of one point, a line string or multi point a single polygon with a
+// single ring, and a polygon or multi line string a single polygon.
+type shape struct {
+	typ        byte
+	hasZ, hasM bool
+	polys      [][][][]float64
+	geoms      []geom.Geometry
+}
+
+// dims is the number of values of each coordinate
+func (s shape) dims() int {
+	d := 2
+	if s.hasZ {
+		d++
+	}
+	if s.hasM {
+		d++
+	}
+	return d
+}
+
+// empty reports whether the shape has no coordinates
+func (s shape) empty() bool {
+	if s.typ == Collection {
+		return len(s.geoms) == 0
+	}
+	for _, ply := range s.polys {
+		for _, r := range ply {
+			if len(r) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parts is the number of parts of a multi geometry or collection
+func (s shape) parts() int {
+	switch s.typ {
+	case MultiPoint:
+		return len(s.polys[0][0])
+	case MultiLineString:
+		return len(s.polys[0])
+	case MultiPolygon:
+		return len(s.polys)
+	case Collection:
+		return len(s.geoms)
+	}
+	return 0
+}
@@ -0,0 +1,296 @@
+package twkb_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding/twkb"
+)
+
+func TestEncodeBytes(t *testing.T) {
+	type tcase struct {
+		geo      geom.Geometry
+		opts     twkb.Options
+		ids      []int64
+		expected string
+		err      bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			var buf bytes.Buffer
+			err := twkb.EncodeWithIDs(&buf, tc.geo, tc.ids, tc.opts)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if got := hex.EncodeToString(buf.Bytes()); got != tc.expected {
+				t.Errorf("bytes, expected %v got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			geo:      geom.Point{1, 2},
+			expected: "01000204",
+		},
+		"line string": {
+			geo:      geom.LineString{{1, 2}, {3, 4}},
+			expected: "02000202040404",
+		},
+		"line string bbox": {
+			geo:      geom.LineString{{1, 2}, {3, 4}},
+			opts:     twkb.Options{BBox: true},
+			expected: "020102040404" + "02020404" + "04",
+		},
+		"line string size": {
+			geo:      geom.LineString{{1, 2}, {3, 4}},
+			opts:     twkb.Options{Size: true},
+			expected: "0202" + "05" + "0202040404",
+		},
+		"point precision": {
+			geo:      geom.Point{1.25, -2.5},
+			opts:     twkb.Options{Precision: 2},
+			expected: "4100fa01f303",
+		},
+		"polygon closes rings": {
+			geo:      geom.Polygon{{{0, 0}, {1, 0}, {1, 1}}},
+			expected: "03000104" + "0000" + "0200" + "0002" + "0101",
+		},
+		"multi point ids": {
+			geo:      geom.MultiPoint{{1, 1}, {2, 2}},
+			ids:      []int64{5, -1},
+			expected: "040402" + "0a01" + "0202" + "0202",
+		},
+		"point z": {
+			geo:      geom.PointZ{1, 2, 3},
+			opts:     twkb.Options{PrecisionZ: 1},
+			expected: "0108" + "05" + "02043c",
+		},
+		"empty line string": {
+			geo:      geom.LineString{},
+			expected: "0210",
+		},
+		"invalid precision": {
+			geo:  geom.Point{1, 2},
+			opts: twkb.Options{Precision: 8},
+			err:  true,
+		},
+		"ids for point": {
+			geo: geom.Point{1, 2},
+			ids: []int64{1},
+			err: true,
+		},
+		"ids mismatch": {
+			geo: geom.MultiPoint{{1, 1}, {2, 2}},
+			ids: []int64{1},
+			err: true,
+		},
+		"unknown geometry": {
+			geo: geom.PointS{},
+			err: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	type tcase struct {
+		geo  geom.Geometry
+		opts twkb.Options
+		ids  []int64
+		// expected is the decoded geometry, if different from geo
+		expected geom.Geometry
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := twkb.EncodeWithIDs(&buf, tc.geo, tc.ids, tc.opts); err != nil {
+				t.Fatalf("encode error, expected nil got %v", err)
+			}
+			// a trailing byte that should not be read
+			buf.WriteByte(0xff)
+
+			geo, md, err := twkb.DecodeWithMetadata(&buf)
+			if err != nil {
+				t.Fatalf("decode error, expected nil got %v", err)
+			}
+			expected := tc.expected
+			if expected == nil {
+				expected = tc.geo
+			}
+			if !reflect.DeepEqual(geo, expected) {
+				t.Errorf("geometry, expected %v got %v", expected, geo)
+			}
+			if !reflect.DeepEqual(md.IDs, tc.ids) {
+				t.Errorf("ids, expected %v got %v", tc.ids, md.IDs)
+			}
+			if md.Precision != tc.opts.Precision {
+				t.Errorf("precision, expected %v got %v", tc.opts.Precision, md.Precision)
+			}
+			if tc.opts.BBox != (md.BBox != nil) {
+				t.Errorf("bbox, expected %v got %v", tc.opts.BBox, md.BBox)
+			}
+			if buf.Len() != 1 {
+				t.Errorf("unread bytes, expected 1 got %v", buf.Len())
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			geo: geom.Point{10, -20},
+		},
+		"point precision": {
+			geo:  geom.Point{1.234567, -7.654321},
+			opts: twkb.Options{Precision: 6},
+		},
+		"point negative precision": {
+			geo:      geom.Point{1234, -5678},
+			opts:     twkb.Options{Precision: -2},
+			expected: geom.Point{1200, -5700},
+		},
+		"point m": {
+			geo:  geom.PointM{1.5, 2.5, 100},
+			opts: twkb.Options{Precision: 1},
+		},
+		"point zm": {
+			geo:  geom.PointZM{1, 2, 3.25, 4.5},
+			opts: twkb.Options{PrecisionZ: 2, PrecisionM: 1},
+		},
+		"line string bbox size": {
+			geo:  geom.LineString{{1, 2}, {3, 4}, {-5, 6}},
+			opts: twkb.Options{BBox: true, Size: true},
+		},
+		"line string z": {
+			geo:  geom.LineStringZ{{1, 2, 3}, {4, 5, 6}},
+			opts: twkb.Options{BBox: true},
+		},
+		"polygon": {
+			geo: geom.Polygon{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+				{{2, 2}, {2, 4}, {4, 4}},
+			},
+		},
+		"polygon zm": {
+			geo: geom.PolygonZM{{{0, 0, 1, 2}, {10, 0, 1, 2}, {10, 10, 1, 2}}},
+		},
+		"multi point": {
+			geo: geom.MultiPoint{{1, 1}, {2, 2}},
+			ids: []int64{1, 2},
+		},
+		"multi point z": {
+			geo: geom.MultiPointZ{{1, 1, 1}, {2, 2, 2}},
+		},
+		"multi line string": {
+			geo:  geom.MultiLineString{{{1, 1}, {2, 2}}, {{3, 3}, {4, 4}, {5, 3}}},
+			opts: twkb.Options{Precision: 3, BBox: true},
+			ids:  []int64{-10, 300000},
+		},
+		"multi line string m": {
+			geo: geom.MultiLineStringM{{{1, 1, 5}, {2, 2, 6}}},
+		},
+		"multi polygon": {
+			geo: geom.MultiPolygon{
+				{{{0, 0}, {1, 0}, {1, 1}}},
+				{{{5, 5}, {6, 5}, {6, 6}}},
+			},
+			ids: []int64{7, 8},
+		},
+		"collection": {
+			geo: geom.Collection{
+				geom.Point{1, 2},
+				geom.LineStringZ{{1, 2, 3}, {4, 5, 6}},
+				geom.Collection{geom.MultiPoint{{1, 1}}},
+			},
+			opts: twkb.Options{BBox: true, Size: true},
+			ids:  []int64{1, 2, 3},
+		},
+		"empty line string": {
+			geo: geom.LineString{},
+		},
+		"empty collection": {
+			geo:      geom.Collection{},
+			expected: geom.Collection(nil),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	type tcase struct {
+		bytes    string
+		expected geom.Geometry
+		err      error
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			b, err := hex.DecodeString(tc.bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			geo, err := twkb.DecodeBytes(b)
+			if tc.err != nil {
+				if !reflect.DeepEqual(err, tc.err) {
+					t.Errorf("error, expected %v got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(geo, tc.expected) {
+				t.Errorf("geometry, expected %v got %v", tc.expected, geo)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			bytes:    "01000204",
+			expected: geom.Point{1, 2},
+		},
+		"empty point": {
+			bytes:    "0110",
+			expected: nil,
+		},
+		"truncated": {
+			bytes: "020002020404",
+			err:   io.ErrUnexpectedEOF,
+		},
+		"no bytes": {
+			bytes: "",
+			err:   io.EOF,
+		},
+		"unknown type": {
+			bytes: "0900",
+			err:   twkb.ErrUnknownGeometryType{Typ: 9},
+		},
+		"multi polygon z": {
+			bytes: "061801",
+			err:   twkb.ErrUnsupportedDimensions{Typ: twkb.MultiPolygon, HasZ: true},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
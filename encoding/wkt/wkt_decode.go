@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/hahaking119/geom"
@@ -536,8 +537,82 @@ func (d *Decoder) readGeometry() (geom.Geometry, error) {
 	}
 }
 
+// readSRID reads the SRID=<srid>; prefix of EWKT and returns
+// false if there is none
+func (d *Decoder) readSRID() (uint32, bool, error) {
+	_, err := d.readWhitespace()
+	if err != nil {
+		return 0, false, err
+	}
+
+	prefix, err := d.src.Peek(len("srid="))
+	if err != nil || !strings.EqualFold(string(prefix), "srid=") {
+		// not EWKT, the geometry reports any error
+		return 0, false, nil
+	}
+
+	for range prefix {
+		d.readByte()
+	}
+
+	token := []byte{}
+
+	var b byte
+	for b, err = d.readByte(); b >= '0' && b <= '9' && err == nil; b, err = d.readByte() {
+		token = append(token, b)
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	if b != ';' || len(token) == 0 {
+		return 0, false, d.expected("0123456789;")
+	}
+
+	srid, err := strconv.ParseUint(string(token), 10, 32)
+	if err != nil {
+		return 0, false, d.syntaxErr("SRID", "cannot parse %q", token)
+	}
+
+	return uint32(srid), true, nil
+}
+
+// withSRID returns the geometry as its geom type with an SRID
+func (d *Decoder) withSRID(geo geom.Geometry, srid uint32) (geom.Geometry, error) {
+	switch g := geo.(type) {
+	case geom.Point:
+		return geom.PointS{Srid: srid, Xy: g}, nil
+	case geom.MultiPoint:
+		return geom.MultiPointS{Srid: srid, Mp: g}, nil
+	case geom.LineString:
+		return geom.LineStringS{Srid: srid, Ls: g}, nil
+	case geom.MultiLineString:
+		return geom.MultiLineStringS{Srid: srid, Mls: g}, nil
+	case geom.Polygon:
+		return geom.PolygonS{Srid: srid, Pol: g}, nil
+	default:
+		// the srid would be lost
+		return nil, d.syntaxErr("SRID", "not supported for %T", geo)
+	}
+}
+
+// Decode reads a WKT or EWKT geometry. An EWKT geometry, which has an
+// SRID=<srid>; prefix, is returned as the geom type with an SRID, such as
+// geom.PointS; multi polygons and collections have no such type and
+// return an error.
 func (d *Decoder) Decode() (geom.Geometry, error) {
-	return d.readGeometry()
+	srid, ok, err := d.readSRID()
+	if err != nil {
+		return nil, err
+	}
+
+	geo, err := d.readGeometry()
+	if err != nil || !ok {
+		return geo, err
+	}
+
+	return d.withSRID(geo, srid)
 }
 
 func NewDecoder(r io.Reader) *Decoder {
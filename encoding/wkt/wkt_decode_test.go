@@ -1,6 +1,7 @@
 package wkt
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -243,3 +244,84 @@ func TestDecode(t *testing.T) {
 		t.Run(k, fn(v))
 	}
 }
+
+func TestDecodeEWKT(t *testing.T) {
+	type tcase struct {
+		in  string
+		out geom.Geometry
+		err bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			out, err := DecodeString(tc.in)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(out, tc.out) {
+				t.Errorf("geometry, expected %v got %v", tc.out, out)
+			}
+
+			// the srid is kept when written back
+			str, err := EncodeString(out)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			back, err := DecodeString(str)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(back, tc.out) {
+				t.Errorf("round trip, expected %v got %v", tc.out, back)
+			}
+		}
+	}
+
+	tcases := map[string]tcase{
+		"point": {
+			in:  "SRID=4326;POINT(1 2)",
+			out: geom.PointS{Srid: 4326, Xy: geom.Point{1, 2}},
+		},
+		"lower case with spaces": {
+			in:  "  srid=3857;point ( 1 2 )",
+			out: geom.PointS{Srid: 3857, Xy: geom.Point{1, 2}},
+		},
+		"multipoint": {
+			in:  "SRID=4326;MULTIPOINT(1 2,3 4)",
+			out: geom.MultiPointS{Srid: 4326, Mp: geom.MultiPoint{{1, 2}, {3, 4}}},
+		},
+		"linestring": {
+			in:  "SRID=4326;LINESTRING(1 2,3 4)",
+			out: geom.LineStringS{Srid: 4326, Ls: geom.LineString{{1, 2}, {3, 4}}},
+		},
+		"multilinestring": {
+			in:  "SRID=4326;MULTILINESTRING((1 2,3 4),(5 6,7 8))",
+			out: geom.MultiLineStringS{Srid: 4326, Mls: geom.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}}},
+		},
+		"polygon": {
+			in:  "SRID=25832;POLYGON((0 0,1 0,1 1,0 0))",
+			out: geom.PolygonS{Srid: 25832, Pol: geom.Polygon{{{0, 0}, {1, 0}, {1, 1}}}},
+		},
+		"without srid": {
+			in:  "POINT(1 2)",
+			out: geom.Point{1, 2},
+		},
+		"multipolygon":   {in: "SRID=4326;MULTIPOLYGON(((0 0,1 0,1 1,0 0)))", err: true},
+		"collection":     {in: "SRID=4326;GEOMETRYCOLLECTION(POINT(1 2))", err: true},
+		"missing srid":   {in: "SRID=;POINT(1 2)", err: true},
+		"negative srid":  {in: "SRID=-1;POINT(1 2)", err: true},
+		"srid too large": {in: "SRID=4294967296;POINT(1 2)", err: true},
+		"missing ;":      {in: "SRID=4326 POINT(1 2)", err: true},
+		"only srid":      {in: "SRID=4326;", err: true},
+	}
+
+	for k, v := range tcases {
+		t.Run(k, fn(v))
+	}
+}
@@ -352,6 +352,16 @@ func (enc Encoder) encodePolys(polys [][][][2]float64, last int) error {
 	return enc.byte(')')
 }
 
+// encodeSRID writes the geometry with the SRID=<srid>; prefix of EWKT
+func (enc Encoder) encodeSRID(srid uint32, geo geom.Geometry) error {
+	err := enc.string("SRID=" + strconv.FormatUint(uint64(srid), 10) + ";")
+	if err != nil {
+		return err
+	}
+
+	return enc.encode(geo)
+}
+
 func (enc Encoder) encode(geo geom.Geometry) error {
 
 	switch g := geo.(type) {
@@ -513,6 +523,58 @@ func (enc Encoder) encode(geo geom.Geometry) error {
 
 		return enc.encode(*g)
 
+	// geometries with an srid, written as EWKT
+
+	case geom.PointS:
+		return enc.encodeSRID(g.Srid, g.Xy)
+
+	case geom.MultiPointS:
+		return enc.encodeSRID(g.Srid, g.Mp)
+
+	case geom.LineStringS:
+		return enc.encodeSRID(g.Srid, g.Ls)
+
+	case geom.MultiLineStringS:
+		return enc.encodeSRID(g.Srid, g.Mls)
+
+	case geom.PolygonS:
+		return enc.encodeSRID(g.Srid, g.Pol)
+
+	case *geom.PointS:
+		if g == nil {
+			return enc.string("POINT EMPTY")
+		}
+
+		return enc.encode(*g)
+
+	case *geom.MultiPointS:
+		if g == nil {
+			return enc.string("MULTIPOINT EMPTY")
+		}
+
+		return enc.encode(*g)
+
+	case *geom.LineStringS:
+		if g == nil {
+			return enc.string("LINESTRING EMPTY")
+		}
+
+		return enc.encode(*g)
+
+	case *geom.MultiLineStringS:
+		if g == nil {
+			return enc.string("MULTILINESTRING EMPTY")
+		}
+
+		return enc.encode(*g)
+
+	case *geom.PolygonS:
+		if g == nil {
+			return enc.string("POLYGON EMPTY")
+		}
+
+		return enc.encode(*g)
+
 	// non basic types
 
 	case [2]float64:
@@ -449,6 +449,56 @@ func TestEncode(t *testing.T) {
 				Rep: "GEOMETRYCOLLECTION (POINT (10 10),LINESTRING (11 11,22 22))",
 			},
 		},
+		"EWKT": {
+			{
+				Geom: geom.PointS{Srid: 4326, Xy: geom.Point{1, 2}},
+				Rep:  "SRID=4326;POINT (1 2)",
+			},
+			{
+				Geom: geom.MultiPointS{Srid: 3857, Mp: geom.MultiPoint{{1, 2}, {3, 4}}},
+				Rep:  "SRID=3857;MULTIPOINT (1 2,3 4)",
+			},
+			{
+				Geom: geom.LineStringS{Srid: 4326, Ls: geom.LineString{{1, 2}, {3, 4}}},
+				Rep:  "SRID=4326;LINESTRING (1 2,3 4)",
+			},
+			{
+				Geom: geom.MultiLineStringS{Srid: 0, Mls: geom.MultiLineString{{{1, 2}, {3, 4}}}},
+				Rep:  "SRID=0;MULTILINESTRING ((1 2,3 4))",
+			},
+			{
+				Geom: geom.PolygonS{Srid: 25832, Pol: geom.Polygon{{{0, 0}, {1, 0}, {1, 1}}}},
+				Rep:  "SRID=25832;POLYGON ((0 0,1 0,1 1,0 0))",
+			},
+			{
+				Geom: &geom.PointS{Srid: 4326, Xy: geom.Point{1, 2}},
+				Rep:  "SRID=4326;POINT (1 2)",
+			},
+			{
+				Geom: &geom.MultiPointS{Srid: 4326, Mp: geom.MultiPoint{{1, 2}}},
+				Rep:  "SRID=4326;MULTIPOINT (1 2)",
+			},
+			{
+				Geom: &geom.LineStringS{Srid: 4326, Ls: geom.LineString{{1, 2}, {3, 4}}},
+				Rep:  "SRID=4326;LINESTRING (1 2,3 4)",
+			},
+			{
+				Geom: &geom.MultiLineStringS{Srid: 4326, Mls: geom.MultiLineString{{{1, 2}, {3, 4}}}},
+				Rep:  "SRID=4326;MULTILINESTRING ((1 2,3 4))",
+			},
+			{
+				Geom: &geom.PolygonS{Srid: 4326, Pol: geom.Polygon{{{0, 0}, {1, 0}, {1, 1}}}},
+				Rep:  "SRID=4326;POLYGON ((0 0,1 0,1 1,0 0))",
+			},
+			{
+				Geom: (*geom.PointS)(nil),
+				Rep:  "POINT EMPTY",
+			},
+			{
+				Geom: (*geom.PolygonS)(nil),
+				Rep:  "POLYGON EMPTY",
+			},
+		},
 		"MultiLine": {
 			{
 				Geom: []geom.Line{
@@ -0,0 +1,128 @@
+package geohash
+
+import (
+	"sort"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/slippy"
+)
+
+// Cover returns the sorted geohashes, with precision characters, of the cells
+// the geometry touches. The geometry should be in lng/lat. Points and lines
+// cover the cells they pass through, polygons also cover the cells of their
+// interior, not including their holes. Use Compact to merge the cells into
+// fewer, shorter geohashes.
+func Cover(geo geom.Geometry, precision uint) ([]string, error) {
+	if err := validPrecision(precision); err != nil {
+		return nil, err
+	}
+
+	// the tiles of the grid are counted from the top, the latitude is flipped
+	// so they are counted from the bottom like geohash cells; this also puts
+	// points on the edge of two cells in the same cell as Encode.
+	flipped, err := geom.ApplyToPoints(geo, func(coords ...float64) ([]float64, error) {
+		return []float64{coords[0], -coords[1]}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tiles, err := slippy.Cover(grid{}, flipped, precision)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(tiles))
+	for i, t := range tiles {
+		hashes[i] = cell{x: uint64(t.X), y: uint64(t.Y), precision: precision}.String()
+	}
+	sort.Strings(hashes)
+	return hashes, nil
+}
+
+// Compact returns the sorted geohashes that cover the same cells as hashes
+// with as few geohashes as possible; geohashes within another are dropped and
+// all 32 cells of a geohash are replaced by that geohash.
+func Compact(hashes []string) []string {
+	set := make(map[string]bool, len(hashes))
+	longest := 0
+	for _, h := range hashes {
+		set[h] = true
+		if len(h) > longest {
+			longest = len(h)
+		}
+	}
+
+	for n := longest; n > 1; n-- {
+		children := make(map[string]int)
+		for h := range set {
+			if len(h) == n {
+				children[h[:n-1]]++
+			}
+		}
+		for parent, count := range children {
+			if count != len(base32) {
+				continue
+			}
+			for i := range base32 {
+				delete(set, parent+base32[i:i+1])
+			}
+			set[parent] = true
+		}
+	}
+
+	compacted := make([]string, 0, len(set))
+	for h := range set {
+		within := false
+		for i := 1; i < len(h) && !within; i++ {
+			within = set[h[:i]]
+		}
+		if !within {
+			compacted = append(compacted, h)
+		}
+	}
+	sort.Strings(compacted)
+	return compacted
+}
+
+// grid is the slippy grid of the geohash cells, the zoom is the precision
+type grid struct{}
+
+func (grid) SRID() uint { return 4326 }
+
+func (grid) Size(z uint) (*slippy.Tile, bool) {
+	if validPrecision(z) != nil {
+		return nil, false
+	}
+	cols, rows := cell{precision: z}.size()
+	return slippy.NewTile(z, uint(cols), uint(rows)), true
+}
+
+func (g grid) FromNative(z uint, pt geom.Point) (*slippy.Tile, bool) {
+	size, ok := g.Size(z)
+	if !ok || !(pt[0] >= -180 && pt[0] <= 180 && pt[1] >= -90 && pt[1] <= 90) {
+		return nil, false
+	}
+
+	x := uint((pt[0] + 180) / 360 * float64(size.X))
+	y := uint((90 - pt[1]) / 180 * float64(size.Y))
+	if x == size.X {
+		x--
+	}
+	if y == size.Y {
+		y--
+	}
+	return slippy.NewTile(z, x, y), true
+}
+
+func (g grid) ToNative(t *slippy.Tile) (geom.Point, bool) {
+	size, ok := g.Size(t.Z)
+	if !ok || t.X > size.X || t.Y > size.Y {
+		return geom.Point{}, false
+	}
+
+	return geom.Point{
+		-180 + float64(t.X)*360/float64(size.X),
+		90 - float64(t.Y)*180/float64(size.Y),
+	}, true
+}
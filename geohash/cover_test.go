@@ -0,0 +1,124 @@
+package geohash_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/geohash"
+)
+
+func TestCover(t *testing.T) {
+	type tcase struct {
+		geom      geom.Geometry
+		precision uint
+		expected  []string
+		err       bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			hashes, err := geohash.Cover(tc.geom, tc.precision)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(hashes, tc.expected) {
+				t.Errorf("geohashes, expected %v got %v", tc.expected, hashes)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			geom:      geom.Point{-5.6, 42.6},
+			precision: 5,
+			expected:  []string{"ezs42"},
+		},
+		"point on the equator": {
+			geom:      geom.Point{10, 0},
+			precision: 2,
+			expected:  []string{"s0"},
+		},
+		"line across the prime meridian": {
+			geom:      geom.LineString{{-1, 1}, {1, 1}},
+			precision: 2,
+			expected:  []string{"eb", "s0"},
+		},
+		"polygon": {
+			geom:      geom.Polygon{{{-10, -10}, {10, -10}, {10, 10}, {-10, 10}}},
+			precision: 2,
+			expected:  []string{"7y", "7z", "eb", "ec", "kn", "kp", "s0", "s1"},
+		},
+		"polygon with hole": {
+			geom: geom.Polygon{
+				{{1, 1}, {44, 1}, {44, 44}, {1, 44}},
+				{{12, 12}, {33, 12}, {33, 33}, {12, 33}},
+			},
+			precision: 1,
+			expected:  []string{"s"},
+		},
+		"collection": {
+			geom:      geom.Collection{geom.Point{-5.6, 42.6}, geom.MultiPoint{{0, 0}, {0.0001, 0}}},
+			precision: 5,
+			expected:  []string{"ezs42", "s0000"},
+		},
+		"invalid precision": {geom: geom.Point{0, 0}, precision: 13, err: true},
+		"unknown geometry":  {geom: geom.Circle{Center: [2]float64{0, 0}, Radius: 1}, precision: 2, err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestCompact(t *testing.T) {
+	type tcase struct {
+		hashes   []string
+		expected []string
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			compacted := geohash.Compact(tc.hashes)
+			if !reflect.DeepEqual(compacted, tc.expected) {
+				t.Errorf("geohashes, expected %v got %v", tc.expected, compacted)
+			}
+		}
+	}
+
+	var children, grandchildren []string
+	for _, c := range "0123456789bcdefghjkmnpqrstuvwxyz" {
+		children = append(children, "s"+string(c))
+		for _, cc := range "0123456789bcdefghjkmnpqrstuvwxyz" {
+			grandchildren = append(grandchildren, "s"+string(c)+string(cc))
+		}
+	}
+
+	tests := map[string]tcase{
+		"empty":          {hashes: nil, expected: []string{}},
+		"duplicates":     {hashes: []string{"s0", "ezs42", "s0"}, expected: []string{"ezs42", "s0"}},
+		"within another": {hashes: []string{"s0", "s01", "s0zz", "s1"}, expected: []string{"s0", "s1"}},
+		"children":       {hashes: append([]string{"u"}, children...), expected: []string{"s", "u"}},
+		"grandchildren":  {hashes: grandchildren, expected: []string{"s"}},
+		"missing child":  {hashes: children[1:], expected: children[1:]},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+
+	// a cover merges to the geohash of the cell
+	hashes, err := geohash.Cover(geom.Polygon{{{0.1, 0.1}, {44.9, 0.1}, {44.9, 44.9}, {0.1, 44.9}}}, 3)
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	if compacted := geohash.Compact(hashes); !reflect.DeepEqual(compacted, []string{"s"}) {
+		t.Errorf("geohashes, expected [s] got %v", compacted)
+	}
+}
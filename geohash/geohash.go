@@ -0,0 +1,202 @@
+// Package geohash encodes lng/lat (EPSG:4326) points as geohashes, a base 32
+// string where each character splits the cell of its prefix into 32 cells.
+// Points that share a prefix are in the same cell, which makes geohashes a
+// spatial key for partitioning and joining data.
+//
+// Reference: https://en.wikipedia.org/wiki/Geohash
+package geohash
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hahaking119/geom"
+)
+
+// MaxPrecision is the longest geohash, its cells are less than 4cm wide.
+const MaxPrecision = 12
+
+// base32 is the geohash alphabet, without a, i, l and o
+const base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// cell is the column and row, counted from the bottom left of the world, of a
+// geohash at a precision
+type cell struct {
+	x, y      uint64
+	precision uint
+}
+
+// bits returns the number of bits of the longitude and latitude of the
+// precision; the bits are interleaved starting with the longitude.
+func bits(precision uint) (lngBits, latBits uint) {
+	n := 5 * precision
+	return (n + 1) / 2, n / 2
+}
+
+// bisect returns the n bits of the value, each halving the range
+func bisect(v, min, max float64, n uint) uint64 {
+	var i uint64
+	for ; n > 0; n-- {
+		mid := (min + max) / 2
+		i <<= 1
+		if v >= mid {
+			i |= 1
+			min = mid
+		} else {
+			max = mid
+		}
+	}
+	return i
+}
+
+func validPrecision(precision uint) error {
+	if precision < 1 || precision > MaxPrecision {
+		return fmt.Errorf("invalid precision %v, expected 1 to %v", precision, MaxPrecision)
+	}
+	return nil
+}
+
+func newCell(pt geom.Point, precision uint) (cell, error) {
+	if err := validPrecision(precision); err != nil {
+		return cell{}, err
+	}
+	// written to also reject NaN
+	if !(pt[0] >= -180 && pt[0] <= 180 && pt[1] >= -90 && pt[1] <= 90) {
+		return cell{}, fmt.Errorf("point %v out of lng/lat range", pt)
+	}
+
+	lngBits, latBits := bits(precision)
+	return cell{
+		x:         bisect(pt[0], -180, 180, lngBits),
+		y:         bisect(pt[1], -90, 90, latBits),
+		precision: precision,
+	}, nil
+}
+
+// parse returns the cell of the geohash, upper case characters are accepted
+func parse(hash string) (cell, error) {
+	if len(hash) == 0 {
+		return cell{}, fmt.Errorf("invalid geohash %q, empty", hash)
+	}
+	if len(hash) > MaxPrecision {
+		return cell{}, fmt.Errorf("invalid geohash %q, precision larger than %v", hash, MaxPrecision)
+	}
+
+	c := cell{precision: uint(len(hash))}
+	bit := 0
+	for i := range hash {
+		v := strings.IndexByte(base32, lower(hash[i]))
+		if v == -1 {
+			return cell{}, fmt.Errorf("invalid geohash %q, unexpected character %q", hash, hash[i])
+		}
+		for mask := 16; mask > 0; mask >>= 1 {
+			b := uint64(0)
+			if v&mask != 0 {
+				b = 1
+			}
+			if bit%2 == 0 {
+				c.x = c.x<<1 | b
+			} else {
+				c.y = c.y<<1 | b
+			}
+			bit++
+		}
+	}
+	return c, nil
+}
+
+func lower(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// size returns the number of columns and rows of the precision
+func (c cell) size() (cols, rows uint64) {
+	lngBits, latBits := bits(c.precision)
+	return 1 << lngBits, 1 << latBits
+}
+
+func (c cell) String() string {
+	lngBits, latBits := bits(c.precision)
+	hash := make([]byte, c.precision)
+	for i := range hash {
+		v := 0
+		for j := 0; j < 5; j++ {
+			bit := uint(5*i + j)
+			var b uint64
+			if bit%2 == 0 {
+				b = c.x >> (lngBits - 1 - bit/2) & 1
+			} else {
+				b = c.y >> (latBits - 1 - bit/2) & 1
+			}
+			v = v<<1 | int(b)
+		}
+		hash[i] = base32[v]
+	}
+	return string(hash)
+}
+
+func (c cell) extent() *geom.Extent {
+	cols, rows := c.size()
+	w, h := 360/float64(cols), 180/float64(rows)
+	return geom.NewExtent(
+		[2]float64{-180 + float64(c.x)*w, -90 + float64(c.y)*h},
+		[2]float64{-180 + float64(c.x+1)*w, -90 + float64(c.y+1)*h},
+	)
+}
+
+// Encode returns the geohash of the point, which is in lng/lat, with precision
+// characters.
+func Encode(pt geom.Point, precision uint) (string, error) {
+	c, err := newCell(pt, precision)
+	if err != nil {
+		return "", err
+	}
+	return c.String(), nil
+}
+
+// Decode returns the center and the extent of the cell of the geohash.
+func Decode(hash string) (geom.Point, *geom.Extent, error) {
+	c, err := parse(hash)
+	if err != nil {
+		return geom.Point{}, nil, err
+	}
+	ext := c.extent()
+	return geom.Point{
+		(ext.MinX() + ext.MaxX()) / 2,
+		(ext.MinY() + ext.MaxY()) / 2,
+	}, ext, nil
+}
+
+// Neighbours returns the geohashes, of the same precision, that touch the cell
+// of the geohash; starting at the top left going across and then down. Cells
+// wrap around the antimeridian but not across the poles, so cells at the poles
+// have five neighbours.
+func Neighbours(hash string) ([]string, error) {
+	c, err := parse(hash)
+	if err != nil {
+		return nil, err
+	}
+	cols, rows := c.size()
+
+	var neighbours []string
+	seen := map[cell]bool{c: true}
+	for dy := 1; dy >= -1; dy-- {
+		y := int64(c.y) + int64(dy)
+		if y < 0 || y >= int64(rows) {
+			continue
+		}
+		for dx := -1; dx <= 1; dx++ {
+			x := (int64(c.x) + int64(dx) + int64(cols)) % int64(cols)
+			n := cell{x: uint64(x), y: uint64(y), precision: c.precision}
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			neighbours = append(neighbours, n.String())
+		}
+	}
+	return neighbours, nil
+}
@@ -0,0 +1,185 @@
+package geohash_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/geohash"
+)
+
+func TestEncode(t *testing.T) {
+	type tcase struct {
+		pt        geom.Point
+		precision uint
+		expected  string
+		err       bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			hash, err := geohash.Encode(tc.pt, tc.precision)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if hash != tc.expected {
+				t.Errorf("geohash, expected %v got %v", tc.expected, hash)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"jutland":          {pt: geom.Point{10.40744, 57.64911}, precision: 11, expected: "u4pruydqqvj"},
+		"spain":            {pt: geom.Point{-5.6, 42.6}, precision: 5, expected: "ezs42"},
+		"max precision":    {pt: geom.Point{-5.6, 42.6}, precision: 12, expected: "ezs42e44yx96"},
+		"origin":           {pt: geom.Point{0, 0}, precision: 3, expected: "s00"},
+		"bottom left":      {pt: geom.Point{-180, -90}, precision: 2, expected: "00"},
+		"top right":        {pt: geom.Point{180, 90}, precision: 2, expected: "zz"},
+		"precision 0":      {pt: geom.Point{0, 0}, precision: 0, err: true},
+		"precision 13":     {pt: geom.Point{0, 0}, precision: 13, err: true},
+		"out of range lng": {pt: geom.Point{180.1, 0}, precision: 5, err: true},
+		"out of range lat": {pt: geom.Point{0, -90.1}, precision: 5, err: true},
+		"nan":              {pt: geom.Point{math.NaN(), 0}, precision: 5, err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type tcase struct {
+		hash   string
+		pt     geom.Point
+		extent *geom.Extent
+		err    bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			pt, ext, err := geohash.Decode(tc.hash)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if pt != tc.pt {
+				t.Errorf("point, expected %v got %v", tc.pt, pt)
+			}
+			if !reflect.DeepEqual(ext, tc.extent) {
+				t.Errorf("extent, expected %v got %v", tc.extent, ext)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"spain": {
+			hash:   "ezs42",
+			pt:     geom.Point{-5.60302734375, 42.60498046875},
+			extent: geom.NewExtent([2]float64{-5.625, 42.5830078125}, [2]float64{-5.5810546875, 42.626953125}),
+		},
+		"upper case": {
+			hash:   "EZS42",
+			pt:     geom.Point{-5.60302734375, 42.60498046875},
+			extent: geom.NewExtent([2]float64{-5.625, 42.5830078125}, [2]float64{-5.5810546875, 42.626953125}),
+		},
+		"precision 1": {
+			hash:   "s",
+			pt:     geom.Point{22.5, 22.5},
+			extent: geom.NewExtent([2]float64{0, 0}, [2]float64{45, 45}),
+		},
+		"precision 2": {
+			hash:   "zz",
+			pt:     geom.Point{174.375, 87.1875},
+			extent: geom.NewExtent([2]float64{168.75, 84.375}, [2]float64{180, 90}),
+		},
+		"empty":         {hash: "", err: true},
+		"too long":      {hash: "ezs42e44yx96a", err: true},
+		"invalid a":     {hash: "eza", err: true},
+		"invalid space": {hash: "ez s", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	pts := []geom.Point{{10.40744, 57.64911}, {-122.4194, 37.7749}, {151.2093, -33.8688}, {-180, 90}}
+	for _, pt := range pts {
+		for precision := uint(1); precision <= geohash.MaxPrecision; precision++ {
+			hash, err := geohash.Encode(pt, precision)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			_, ext, err := geohash.Decode(hash)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !ext.ContainsPoint(pt) {
+				t.Errorf("extent of %v, expected to contain %v got %v", hash, pt, ext)
+			}
+		}
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	type tcase struct {
+		hash     string
+		expected []string
+		err      bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			neighbours, err := geohash.Neighbours(tc.hash)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if !reflect.DeepEqual(neighbours, tc.expected) {
+				t.Errorf("neighbours, expected %v got %v", tc.expected, neighbours)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"spain": {
+			hash:     "ezs42",
+			expected: []string{"ezefx", "ezs48", "ezs49", "ezefr", "ezs43", "ezefp", "ezs40", "ezs41"},
+		},
+		"across the prime meridian": {
+			hash:     "s0",
+			expected: []string{"ec", "s1", "s3", "eb", "s2", "7z", "kp", "kr"},
+		},
+		"north pole and antimeridian": {
+			hash:     "b",
+			expected: []string{"z", "c", "x", "8", "9"},
+		},
+		"south pole and antimeridian": {
+			hash:     "0",
+			expected: []string{"r", "2", "3", "p", "1"},
+		},
+		"invalid": {hash: "a", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -1,13 +1,49 @@
-//go:build !cgo
-// +build !cgo
-
 package debugger
 
 import (
-	"github.com/gdey/errors"
+	"fmt"
+	"os"
+
 	rcdr "github.com/hahaking119/geom/internal/debugger/recorder"
+	"github.com/hahaking119/geom/internal/debugger/recorder/geojson"
+)
+
+const (
+	// BackendGPKG records to a GeoPackage, it is only supported with cgo
+	BackendGPKG = "gpkg"
+	// BackendGeoJSON records to a GeoJSON text sequence file
+	BackendGeoJSON = "geojson"
 )
 
-func NewRecorder(_, _ string) (rcdr.Interface, string, error) {
-	return nil, "", errors.String("only supported in cgo")
+// BackendEnv is the environment variable the default Backend is read from
+const BackendEnv = "GEOM_DEBUGGER_BACKEND"
+
+// Backend is the backend new recorders are created with. By default it is the value of
+// the BackendEnv environment variable; if that is empty BackendGPKG is used when built
+// with cgo, and BackendGeoJSON otherwise. Set this in an init function to change it.
+var Backend = os.Getenv(BackendEnv)
+
+// NewRecorder returns a new recorder of the Backend, writing to filename, without
+// the extension, in dir. The full filename is returned along with the recorder.
+func NewRecorder(dir, filename string) (rcdr.Interface, string, error) {
+	backend := Backend
+	if backend == "" {
+		backend = BackendGeoJSON
+		if gpkgSupported {
+			backend = BackendGPKG
+		}
+	}
+
+	switch backend {
+	case BackendGPKG:
+		return newGPKGRecorder(dir, filename)
+	case BackendGeoJSON:
+		r, fn, err := geojson.New(dir, filename)
+		if err != nil {
+			return nil, fn, fmt.Errorf("geojson error: %v", err)
+		}
+		return r, fn, nil
+	default:
+		return nil, "", fmt.Errorf("unknown debugger backend %q", backend)
+	}
 }
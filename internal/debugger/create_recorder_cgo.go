@@ -10,7 +10,10 @@ import (
 	"github.com/hahaking119/geom/internal/debugger/recorder/gpkg"
 )
 
-func NewRecorder(dir, filename string) (rcdr.Interface, string, error) {
+// gpkgSupported is whether the gpkg backend is available, it needs cgo
+const gpkgSupported = true
+
+func newGPKGRecorder(dir, filename string) (rcdr.Interface, string, error) {
 	r, fn, err := gpkg.New(dir, filename, 0)
 	if err != nil {
 		return nil, fn, fmt.Errorf("gpkg error: %v", err)
@@ -0,0 +1,16 @@
+//go:build !cgo
+// +build !cgo
+
+package debugger
+
+import (
+	"github.com/gdey/errors"
+	rcdr "github.com/hahaking119/geom/internal/debugger/recorder"
+)
+
+// gpkgSupported is whether the gpkg backend is available, it needs cgo
+const gpkgSupported = false
+
+func newGPKGRecorder(_, _ string) (rcdr.Interface, string, error) {
+	return nil, "", errors.String("only supported in cgo")
+}
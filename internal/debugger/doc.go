@@ -1,7 +1,8 @@
 /*
 Package debugger provides a way for us to capture partial
 geometries during geometry processing. The geometries are
-stored in a `spatailite` database.
+stored in a GeoPackage database, which needs cgo, or a GeoJSON
+text sequence file; see Backend for choosing between them.
 
  The general way to use the package is with a `context.Context`
  variable. The package uses context as a way to pass around
@@ -0,0 +1,5 @@
+package geojson
+
+const (
+	debug = false
+)
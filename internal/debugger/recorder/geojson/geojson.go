@@ -0,0 +1,110 @@
+// Package geojson provides a debugger recorder that writes the records as a
+// GeoJSON text sequence (RFC 8142); each record is a GeoJSON feature with the
+// test description and the function, file and line it was recorded at as its
+// properties. Unlike the gpkg recorder it does not need cgo.
+package geojson
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/encoding/geojson"
+	"github.com/hahaking119/geom/internal/debugger/recorder"
+)
+
+// Ext is the extension of the files written by the recorder
+const Ext = ".geojsons"
+
+// recordSeparator starts each record of a GeoJSON text sequence
+const recordSeparator = 0x1E
+
+// File records to a GeoJSON text sequence file
+type File struct {
+	lck  sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+}
+
+// New returns a new recorder, the file where the records are recorded to and
+// any errors.
+func New(outputDir, filename string) (*File, string, error) {
+	fn := filepath.Join(outputDir, filename+Ext)
+	file, err := os.Create(fn)
+	if err != nil {
+		return nil, fn, fmt.Errorf("file: %v err: %v", fn, err)
+	}
+	return &File{
+		file: file,
+		w:    bufio.NewWriter(file),
+	}, fn, nil
+}
+
+// Record writes the geometry as a feature to the file
+func (f *File) Record(geo interface{}, ffl recorder.FuncFileLineType, tblTest recorder.TestDescription) error {
+	if f == nil {
+		return nil
+	}
+
+	g, ok := geo.(geom.Geometry)
+	if !ok {
+		err := geom.ErrUnknownGeometry{Geom: geo}
+		if debug {
+			log.Println(ffl, err)
+		}
+		return err
+	}
+
+	b, err := geojson.Marshal(geojson.Feature{
+		Geometry: geojson.Geometry{Geometry: g},
+		Properties: map[string]interface{}{
+			"function_name": ffl.Func,
+			"filename":      ffl.File,
+			"line":          ffl.LineNumber,
+			"name":          tblTest.Name,
+			"description":   tblTest.Description,
+			"category":      tblTest.Category,
+		},
+	})
+	if err != nil {
+		if debug {
+			log.Println(ffl, err)
+		}
+		return err
+	}
+
+	f.lck.Lock()
+	defer f.lck.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	if err = f.w.WriteByte(recordSeparator); err != nil {
+		return err
+	}
+	if _, err = f.w.Write(b); err != nil {
+		return err
+	}
+	return f.w.WriteByte('\n')
+}
+
+// Close flushes the records and closes the file
+func (f *File) Close() error {
+	if f == nil {
+		return nil
+	}
+	f.lck.Lock()
+	defer f.lck.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.w.Flush()
+	if cerr := f.file.Close(); err == nil {
+		err = cerr
+	}
+	f.file = nil
+	return err
+}
@@ -0,0 +1,98 @@
+package geojson_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/cmp"
+	"github.com/hahaking119/geom/encoding/geojson"
+	"github.com/hahaking119/geom/internal/debugger/recorder"
+	rgeojson "github.com/hahaking119/geom/internal/debugger/recorder/geojson"
+)
+
+func TestRecord(t *testing.T) {
+	type tcase struct {
+		geo  interface{}
+		desc recorder.TestDescription
+		err  bool
+	}
+
+	tests := []tcase{
+		{
+			geo:  geom.LineString{{1, 1}, {1, 2}},
+			desc: recorder.TestDescription{Name: "test1", Category: "got", Description: "got segments"},
+		},
+		{
+			geo:  geom.Polygon{{{0, 0}, {2, 0}, {2, 2}}},
+			desc: recorder.TestDescription{Name: "test1", Category: "input", Description: "input polygon"},
+		},
+		{
+			geo: "not a geometry",
+			err: true,
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "geojson_recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rec, filename, err := rgeojson.New(dir, "test_record")
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	ffl := recorder.FuncFileLineType{Func: "TestRecord", File: "geojson_test.go", LineNumber: 10}
+	for i, tc := range tests {
+		err := rec.Record(tc.geo, ffl, tc.desc)
+		if tc.err != (err != nil) {
+			t.Errorf("record %v error, expected error %v got %v", i, tc.err, err)
+		}
+	}
+	if err = rec.Close(); err != nil {
+		t.Fatalf("close error, expected nil got %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := bytes.Split(bytes.TrimPrefix(b, []byte{0x1E}), []byte{0x1E})
+	var expected []tcase
+	for _, tc := range tests {
+		if !tc.err {
+			expected = append(expected, tc)
+		}
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("records, expected %v got %v", len(expected), len(records))
+	}
+
+	for i, tc := range expected {
+		v, err := geojson.Unmarshal(records[i])
+		if err != nil {
+			t.Errorf("record %v unmarshal error, expected nil got %v", i, err)
+			continue
+		}
+		f := v.(geojson.Feature)
+		if !cmp.GeometryEqual(f.Geometry.Geometry, tc.geo.(geom.Geometry)) {
+			t.Errorf("record %v geometry, expected %v got %v", i, tc.geo, f.Geometry.Geometry)
+		}
+		props := map[string]interface{}{
+			"function_name": ffl.Func,
+			"filename":      ffl.File,
+			"line":          float64(ffl.LineNumber),
+			"name":          tc.desc.Name,
+			"description":   tc.desc.Description,
+			"category":      tc.desc.Category,
+		}
+		for k, p := range props {
+			if f.Properties[k] != p {
+				t.Errorf("record %v property %v, expected %v got %v", i, k, p, f.Properties[k])
+			}
+		}
+	}
+}
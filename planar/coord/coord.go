@@ -22,11 +22,19 @@ type LngLat struct {
 	Lat float64
 }
 
-// NormalizeLng will insure that the longitude value is between 0-360
+// NormalizeLng will insure that the longitude value is greater than -180 and at most 180;
+// the antimeridian is always 180.
 func (l LngLat) NormalizeLng() LngLat {
+	if l.Lng > -180.0 && l.Lng <= 180.0 {
+		return l
+	}
+	lng := l.Lng - math.Floor((l.Lng+180.0)/360.0)*360.0
+	if lng == -180.0 {
+		lng = 180.0
+	}
 	return LngLat{
 		Lat: l.Lat,
-		Lng: l.Lng - float64(int64((l.Lng+180.0)/360.0)*360.0),
+		Lng: lng,
 	}
 }
 
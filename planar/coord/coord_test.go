@@ -341,6 +341,50 @@ func TestLngLat_NormalizeLng(t *testing.T) {
 			},
 			Lng: 69.1503666510912,
 		},
+		{
+			Desc: "Fiji east",
+			LngLat: LngLat{
+				Lng: 181.5,
+				Lat: -17.8,
+			},
+			Lng: -178.5,
+		},
+		{
+			Desc: "Fiji west",
+			LngLat: LngLat{
+				Lng: -538.5,
+				Lat: -17.8,
+			},
+			Lng: -178.5,
+		},
+		{
+			Desc: "antimeridian",
+			LngLat: LngLat{
+				Lng: 180,
+			},
+			Lng: 180,
+		},
+		{
+			Desc: "antimeridian west",
+			LngLat: LngLat{
+				Lng: -180,
+			},
+			Lng: 180,
+		},
+		{
+			Desc: "antimeridian east wrapped",
+			LngLat: LngLat{
+				Lng: 540,
+			},
+			Lng: 180,
+		},
+		{
+			Desc: "antimeridian west wrapped",
+			LngLat: LngLat{
+				Lng: -540,
+			},
+			Lng: 180,
+		},
 	}
 
 	for i := range tests {
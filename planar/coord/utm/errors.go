@@ -7,4 +7,11 @@ const (
 	ErrInvalidZone = errors.String("zone is invalid")
 	// ErrLatitudeOutOfRange will be returned if the latitude is not in the correct range of acceptable values
 	ErrLatitudeOutOfRange = errors.String("latitude out of range")
+	// ErrInvalidPrecision will be returned if the MGRS precision is not between 100km and 1m
+	ErrInvalidPrecision = errors.String("precision is invalid")
+	// ErrInvalidMGRS will be returned if a MGRS or USNG string is malformed
+	ErrInvalidMGRS = errors.String("invalid MGRS string")
+	// ErrPolarRegion will be returned for coordinates in the polar regions, where UPS is used instead of UTM.
+	// UPS is not supported.
+	ErrPolarRegion = errors.String("polar region, UPS is not supported")
 )
@@ -0,0 +1,137 @@
+package utm
+
+import (
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/planar/coord"
+	"github.com/hahaking119/geom/spherical"
+)
+
+// FromLngLatInZone returns a new utm coordinate for the provided longitude and latitude
+// values in the given zone, instead of the zone the values fall in. The hemisphere of
+// the zone is used for the false northing, so points in the other hemisphere will have
+// a northing that is either negative or more then 10,000km.
+//
+// Possible errors:
+//
+//	ErrInvalidZone
+func FromLngLatInZone(lnglat coord.LngLat, zone Zone, ellips coord.Ellipsoid) (Coord, error) {
+	if !zone.IsValid() {
+		return Coord{}, ErrInvalidZone
+	}
+	return fromLngLatInZone(lnglat.NormalizeLng(), zone, ellips), nil
+}
+
+// fromLngLatInZone assumes a valid zone.
+func fromLngLatInZone(lnglat coord.LngLat, zone Zone, ellips coord.Ellipsoid) Coord {
+	nato := ellips.NATOCompatible
+	// the digraph calculation only works for values in the zone,
+	// we will get it from the easting and northing.
+	ellips.NATOCompatible = false
+	// keep the longitude next to the zone's central meridian, so
+	// values across the antimeridian don't go the long way round.
+	cm, _ := CentralMeridian(zone)
+	lnglat.Lng = float64(cm) + coord.LngLat{Lng: lnglat.Lng - float64(cm)}.NormalizeLng().Lng
+	c := fromLngLat(lnglat, zone, ellips)
+
+	switch {
+	case zone.IsNorthern() && lnglat.Lat < 0.0:
+		c.Northing -= 10000000.0
+	case !zone.IsNorthern() && lnglat.Lat >= 0.0:
+		c.Northing += 10000000.0
+	}
+	if nato {
+		c.Digraph, _ = SquareID(zone, c.Easting, c.Northing)
+	}
+	return c
+}
+
+// ZoneForGeometry returns the UTM zone for the center of the given lng/lat geometry,
+// taking into account the antimeridian.
+//
+// Possible errors:
+//
+//	ErrLatitudeOutOfRange
+//	ErrPolarRegion
+func ZoneForGeometry(g geom.Geometry) (Zone, error) {
+	ext, err := spherical.GeometryHull(g)
+	if err != nil {
+		return Zone{}, err
+	}
+	if ext == nil {
+		return Zone{}, geom.ErrUnknownGeometry{Geom: g}
+	}
+
+	west, east := ext[0], ext[2]
+	if west > east {
+		// the extent crosses the antimeridian
+		east += 360
+	}
+	center := coord.LngLat{
+		Lng: (west + east) / 2,
+		Lat: (ext[1] + ext[3]) / 2,
+	}.NormalizeLng()
+
+	zone, err := NewZone(center)
+	if err != nil {
+		return Zone{}, err
+	}
+	if zone.Number == 0 {
+		return Zone{}, ErrPolarRegion
+	}
+	return zone, nil
+}
+
+// FromGeometry projects the lng/lat geometry into the UTM zone of the center of the geometry.
+// The zone used is returned, and is needed to get back to lng/lat with ToGeometry.
+//
+// Possible errors:
+//
+//	ErrLatitudeOutOfRange
+//	ErrPolarRegion
+func FromGeometry(g geom.Geometry, ellips coord.Ellipsoid) (geom.Geometry, Zone, error) {
+	zone, err := ZoneForGeometry(g)
+	if err != nil {
+		return nil, Zone{}, err
+	}
+	utmg, err := FromGeometryInZone(g, zone, ellips)
+	return utmg, zone, err
+}
+
+// FromGeometryInZone projects the lng/lat geometry into the given UTM zone, the
+// coordinates of the returned geometry are easting, northing in meters. All of the
+// points use the false northing of the zone's hemisphere. Z and M values are
+// kept as is.
+//
+// Possible errors:
+//
+//	ErrInvalidZone
+func FromGeometryInZone(g geom.Geometry, zone Zone, ellips coord.Ellipsoid) (geom.Geometry, error) {
+	if !zone.IsValid() {
+		return nil, ErrInvalidZone
+	}
+	ellips.NATOCompatible = false
+	return geom.ApplyToPoints(g, func(coords ...float64) ([]float64, error) {
+		c := fromLngLatInZone(coord.LngLat{Lng: coords[0], Lat: coords[1]}.NormalizeLng(), zone, ellips)
+		return []float64{c.Easting, c.Northing}, nil
+	})
+}
+
+// ToGeometry transforms a geometry in the given UTM zone, as returned by FromGeometry or
+// FromGeometryInZone, back to lng/lat.
+//
+// Possible errors:
+//
+//	ErrInvalidZone
+func ToGeometry(g geom.Geometry, zone Zone, ellips coord.Ellipsoid) (geom.Geometry, error) {
+	if !zone.IsValid() {
+		return nil, ErrInvalidZone
+	}
+	return geom.ApplyToPoints(g, func(coords ...float64) ([]float64, error) {
+		ll, err := Coord{Easting: coords[0], Northing: coords[1], Zone: zone}.ToLngLat(ellips)
+		if err != nil {
+			return nil, err
+		}
+		ll = ll.NormalizeLng()
+		return []float64{ll.Lng, ll.Lat}, nil
+	})
+}
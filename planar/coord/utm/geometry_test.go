@@ -0,0 +1,165 @@
+package utm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/planar/coord"
+)
+
+func TestZoneNumberFromLngLat(t *testing.T) {
+	type tcase struct {
+		Desc   string
+		LngLat coord.LngLat
+		Number int
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			number := ZoneNumberFromLngLat(tc.LngLat)
+			if number != tc.Number {
+				t.Errorf("zone number, expected %v got %v", tc.Number, number)
+			}
+		}
+	}
+
+	tests := []tcase{
+		{
+			Desc:   "Kabul",
+			LngLat: coord.LngLat{Lng: 69.1503666510912, Lat: 34.52518357633554},
+			Number: 42,
+		},
+		{
+			Desc:   "Bergen",
+			LngLat: coord.LngLat{Lng: 5.32, Lat: 60.39},
+			Number: 32,
+		},
+		{
+			Desc:   "Svalbard 31X",
+			LngLat: coord.LngLat{Lng: 8.9, Lat: 79},
+			Number: 31,
+		},
+		{
+			Desc:   "Svalbard 33X",
+			LngLat: coord.LngLat{Lng: 15.6, Lat: 78.2},
+			Number: 33,
+		},
+		{
+			Desc:   "Svalbard 37X",
+			LngLat: coord.LngLat{Lng: 40, Lat: 80},
+			Number: 37,
+		},
+		{
+			Desc:   "antimeridian",
+			LngLat: coord.LngLat{Lng: 180, Lat: 0},
+			Number: 60,
+		},
+		{
+			Desc:   "unnormalized",
+			LngLat: coord.LngLat{Lng: 181, Lat: 0},
+			Number: 1,
+		},
+		{
+			Desc:   "north pole",
+			LngLat: coord.LngLat{Lng: 0, Lat: 85},
+			Number: 0,
+		},
+		{
+			Desc:   "south pole",
+			LngLat: coord.LngLat{Lng: 0, Lat: -85},
+			Number: 0,
+		},
+	}
+
+	for i := range tests {
+		t.Run(tests[i].Desc, fn(tests[i]))
+	}
+}
+
+func TestFromGeometry(t *testing.T) {
+	type tcase struct {
+		Desc  string
+		Geom  geom.Geometry
+		Zone  *Zone
+		Want  Zone
+		Error error
+	}
+
+	ellips := getEllipsoidByName("WGS 84")
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			var (
+				utmg geom.Geometry
+				zone Zone
+				err  error
+			)
+			if tc.Zone == nil {
+				utmg, zone, err = FromGeometry(tc.Geom, ellips)
+			} else {
+				zone = *tc.Zone
+				utmg, err = FromGeometryInZone(tc.Geom, zone, ellips)
+			}
+			if err != tc.Error {
+				t.Fatalf("error, expected %v got %v", tc.Error, err)
+			}
+			if tc.Error != nil {
+				return
+			}
+			if zone != tc.Want {
+				t.Errorf("zone, expected %v got %v", tc.Want, zone)
+			}
+
+			back, err := ToGeometry(utmg, zone, ellips)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			want, _ := geom.GetCoordinates(tc.Geom)
+			got, _ := geom.GetCoordinates(back)
+			if len(want) != len(got) {
+				t.Fatalf("number of points, expected %v got %v", len(want), len(got))
+			}
+			for i := range want {
+				w := coord.LngLat{Lng: want[i][0], Lat: want[i][1]}.NormalizeLng()
+				if math.Abs(w.Lng-got[i][0]) > 0.0001 || math.Abs(w.Lat-got[i][1]) > 0.0001 {
+					t.Errorf("point %v, expected %v got %v", i, w, got[i])
+				}
+			}
+		}
+	}
+
+	tests := []tcase{
+		{
+			Desc: "Kabul",
+			Geom: geom.LineString{{69.15, 34.52}, {69.2, 34.6}},
+			Want: Zone{Number: 42, Letter: ZoneS},
+		},
+		{
+			Desc: "Fiji",
+			Geom: geom.Polygon{{{178.5, -17}, {-179.9, -17}, {-179.9, -18}, {178.5, -18}}},
+			Want: Zone{Number: 60, Letter: ZoneK},
+		},
+		{
+			Desc: "forced zone across the equator",
+			Geom: geom.LineString{{-78.5, 0.5}, {-78.4, -0.5}},
+			Zone: &Zone{Number: 17, Letter: ZoneN},
+			Want: Zone{Number: 17, Letter: ZoneN},
+		},
+		{
+			Desc:  "invalid zone",
+			Geom:  geom.Point{1, 1},
+			Zone:  &Zone{Number: 61, Letter: ZoneN},
+			Error: ErrInvalidZone,
+		},
+		{
+			Desc:  "polar",
+			Geom:  geom.Point{1, 88},
+			Error: ErrLatitudeOutOfRange,
+		},
+	}
+
+	for i := range tests {
+		t.Run(tests[i].Desc, fn(tests[i]))
+	}
+}
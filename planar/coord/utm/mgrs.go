@@ -0,0 +1,275 @@
+package utm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hahaking119/geom/planar/coord"
+)
+
+// Precision is the precision of a MGRS or USNG reference.
+// It is the number of digits used for each of the easting
+// and northing values.
+type Precision uint8
+
+// MGRS precisions
+const (
+	Precision100km Precision = 0
+	Precision10km  Precision = 1
+	Precision1km   Precision = 2
+	Precision100m  Precision = 3
+	Precision10m   Precision = 4
+	Precision1m    Precision = 5
+)
+
+// IsValid returns if the precision is between 100km and 1m
+func (p Precision) IsValid() bool { return p <= Precision1m }
+
+// Meters returns the size, in meters, of the square described by a
+// reference with this precision.
+func (p Precision) Meters() float64 { return math.Pow10(5 - int(p)) }
+
+// mgrsColumnLetters are the 100km column letters; each of the three
+// sets of zones uses eight of these letters.
+const mgrsColumnLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// mgrsRowLetters are the 100km row letters, the rows cycle every 2000km.
+const mgrsRowLetters = "ABCDEFGHJKLMNPQRSTUV"
+
+// mgrsBandLetters are the latitude band letters, from south to north; each band is 8° tall.
+const mgrsBandLetters = "CDEFGHJKLMNPQRSTUVWX"
+
+// wgs84 is the ellipsoid MGRS is defined on.
+var wgs84 = coord.Ellipsoid{
+	Name:           "WGS_84",
+	Radius:         6378137,
+	Eccentricity:   0.00669438,
+	NATOCompatible: true,
+}
+
+// SquareID returns the 100km square identification letters, the
+// column letter followed by the row letter, of the given easting and
+// northing in the zone.
+//
+// Possible errors:
+//
+//	ErrInvalidZone
+func SquareID(zone Zone, easting, northing float64) (Digraph, error) {
+	if !zone.IsValid() {
+		return Digraph{}, ErrInvalidZone
+	}
+
+	set := (zone.Number - 1) % 3
+	col := int(math.Floor(easting/100000)) - 1
+	if col < 0 || col > 7 {
+		return Digraph{}, ErrInvalidMGRS
+	}
+
+	row := int(math.Floor(northing/100000)) % 20
+	if row < 0 {
+		return Digraph{}, ErrInvalidMGRS
+	}
+	// even zones start their rows at F
+	if zone.Number%2 == 0 {
+		row = (row + 5) % 20
+	}
+
+	return Digraph{
+		rune(mgrsColumnLetters[set*8+col]),
+		rune(mgrsRowLetters[row]),
+	}, nil
+}
+
+// mgrsParts returns the parts that make up the MGRS reference
+func (c Coord) mgrsParts(prec Precision) (zone string, square Digraph, easting, northing string, err error) {
+	if !prec.IsValid() {
+		return "", Digraph{}, "", "", ErrInvalidPrecision
+	}
+	if c.Zone.Number == 0 {
+		return "", Digraph{}, "", "", ErrPolarRegion
+	}
+	square, err = SquareID(c.Zone, c.Easting, c.Northing)
+	if err != nil {
+		return "", Digraph{}, "", "", err
+	}
+
+	// MGRS values are truncated, not rounded
+	size := prec.Meters()
+	e := int(math.Floor(math.Mod(c.Easting, 100000) / size))
+	n := int(math.Floor(math.Mod(c.Northing, 100000) / size))
+	if prec > Precision100km {
+		easting = fmt.Sprintf("%0*d", prec, e)
+		northing = fmt.Sprintf("%0*d", prec, n)
+	}
+	return c.Zone.String(), square, easting, northing, nil
+}
+
+// MGRS returns the Military Grid Reference System string of the
+// coordinate at the given precision. e.g. 33UXP0416614591
+//
+// Possible errors:
+//
+//	ErrInvalidPrecision
+//	ErrInvalidZone
+//	ErrPolarRegion
+func (c Coord) MGRS(prec Precision) (string, error) {
+	zone, square, easting, northing, err := c.mgrsParts(prec)
+	if err != nil {
+		return "", err
+	}
+	return zone + square.String() + easting + northing, nil
+}
+
+// USNG returns the United States National Grid string of the
+// coordinate at the given precision. e.g. 33U XP 04166 14591
+//
+// Possible errors:
+//
+//	ErrInvalidPrecision
+//	ErrInvalidZone
+//	ErrPolarRegion
+func (c Coord) USNG(prec Precision) (string, error) {
+	zone, square, easting, northing, err := c.mgrsParts(prec)
+	if err != nil {
+		return "", err
+	}
+	if prec == Precision100km {
+		return zone + " " + square.String(), nil
+	}
+	return zone + " " + square.String() + " " + easting + " " + northing, nil
+}
+
+// MGRSFromLngLat returns the MGRS string for the given lng/lat value at the given precision.
+//
+// Possible errors:
+//
+//	ErrInvalidPrecision
+//	ErrPolarRegion
+func MGRSFromLngLat(lnglat coord.LngLat, prec Precision) (string, error) {
+	if lnglat.Lat > 84 || lnglat.Lat < -80 {
+		return "", ErrPolarRegion
+	}
+	c, err := FromLngLat(lnglat, wgs84)
+	if err != nil {
+		return "", err
+	}
+	return c.MGRS(prec)
+}
+
+// bandMinNorthing returns the northing of the southern edge of the latitude band, at the
+// zone's central meridian, rounded down to the 100km square.
+func bandMinNorthing(zone Zone) float64 {
+	lat := -80.0 + 8*float64(strings.IndexByte(mgrsBandLetters, byte(zone.Letter)))
+	cm, _ := CentralMeridian(zone)
+	// we don't need the digraph, so don't ask for it.
+	c := fromLngLat(coord.LngLat{Lng: float64(cm), Lat: lat}, zone, coord.Ellipsoid{
+		Radius:       wgs84.Radius,
+		Eccentricity: wgs84.Eccentricity,
+	})
+	return math.Floor(c.Northing/100000) * 100000
+}
+
+// ParseMGRS parses a MGRS or USNG string, with or without spaces, into an UTM coordinate. The
+// returned coordinate is the south west corner of the square described by the string; the precision
+// of the string is returned as well.
+//
+// Possible errors:
+//
+//	ErrInvalidMGRS
+//	ErrInvalidZone
+//	ErrPolarRegion
+func ParseMGRS(s string) (Coord, Precision, error) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, s)
+
+	// zone number
+	i := 0
+	for i < len(s) && i < 2 && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		// UPS references start with the band letter
+		if len(s) > 0 && strings.IndexByte("ABYZ", s[0]) != -1 {
+			return Coord{}, 0, ErrPolarRegion
+		}
+		return Coord{}, 0, ErrInvalidMGRS
+	}
+	number, _ := strconv.Atoi(s[:i])
+	s = s[i:]
+
+	if len(s) < 3 {
+		return Coord{}, 0, ErrInvalidMGRS
+	}
+	zone := Zone{Number: number, Letter: ZoneLetter(s[0])}
+	if zone.Letter == 'A' || zone.Letter == 'B' || zone.Letter == 'Y' || zone.Letter == 'Z' {
+		return Coord{}, 0, ErrPolarRegion
+	}
+	if !zone.IsValid() || strings.IndexByte(mgrsBandLetters, byte(zone.Letter)) == -1 {
+		return Coord{}, 0, ErrInvalidZone
+	}
+
+	square := Digraph{rune(s[1]), rune(s[2])}
+	digits := s[3:]
+	if len(digits)%2 != 0 || len(digits) > 2*int(Precision1m) {
+		return Coord{}, 0, ErrInvalidMGRS
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Coord{}, 0, ErrInvalidMGRS
+		}
+	}
+	prec := Precision(len(digits) / 2)
+
+	set := (zone.Number - 1) % 3
+	col := strings.IndexRune(mgrsColumnLetters[set*8:set*8+8], square[0])
+	row := strings.IndexRune(mgrsRowLetters, square[1])
+	if col == -1 || row == -1 {
+		return Coord{}, 0, ErrInvalidMGRS
+	}
+	if zone.Number%2 == 0 {
+		row = (row + 15) % 20
+	}
+
+	easting := float64(col+1) * 100000
+	northing := float64(row) * 100000
+	for min := bandMinNorthing(zone); northing < min; {
+		northing += 2000000
+	}
+
+	if prec > Precision100km {
+		e, _ := strconv.Atoi(digits[:prec])
+		n, _ := strconv.Atoi(digits[prec:])
+		easting += float64(e) * prec.Meters()
+		northing += float64(n) * prec.Meters()
+	}
+
+	return Coord{
+		Easting:  easting,
+		Northing: northing,
+		Zone:     zone,
+		Digraph:  square,
+	}, prec, nil
+}
+
+// MGRSToLngLat returns the lng/lat value of the south west corner of the square described by
+// the MGRS or USNG string.
+//
+// Possible errors:
+//
+//	ErrInvalidMGRS
+//	ErrInvalidZone
+//	ErrPolarRegion
+func MGRSToLngLat(s string) (coord.LngLat, error) {
+	c, _, err := ParseMGRS(s)
+	if err != nil {
+		return coord.LngLat{}, err
+	}
+	return c.ToLngLat(wgs84)
+}
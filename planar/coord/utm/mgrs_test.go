@@ -0,0 +1,262 @@
+package utm
+
+import (
+	"testing"
+
+	"github.com/hahaking119/geom/planar/coord"
+)
+
+func TestCoord_MGRS(t *testing.T) {
+	type tcase struct {
+		Desc      string
+		UTM       Coord
+		Precision Precision
+		MGRS      string
+		USNG      string
+		Err       error
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			mgrs, err := tc.UTM.MGRS(tc.Precision)
+			if err != tc.Err {
+				t.Fatalf("error, expected %v got %v", tc.Err, err)
+			}
+			if mgrs != tc.MGRS {
+				t.Errorf("mgrs, expected %v got %v", tc.MGRS, mgrs)
+			}
+			usng, err := tc.UTM.USNG(tc.Precision)
+			if err != tc.Err {
+				t.Fatalf("error, expected %v got %v", tc.Err, err)
+			}
+			if usng != tc.USNG {
+				t.Errorf("usng, expected %v got %v", tc.USNG, usng)
+			}
+		}
+	}
+
+	kabul := Coord{
+		Northing: 3820400.4,
+		Easting:  513799.7,
+		Zone:     Zone{Number: 42, Letter: ZoneS},
+	}
+
+	tests := []tcase{
+		{
+			Desc:      "Kabul 1m",
+			UTM:       kabul,
+			Precision: Precision1m,
+			MGRS:      "42SWD1379920400",
+			USNG:      "42S WD 13799 20400",
+		},
+		{
+			Desc:      "Kabul 1km",
+			UTM:       kabul,
+			Precision: Precision1km,
+			MGRS:      "42SWD1320",
+			USNG:      "42S WD 13 20",
+		},
+		{
+			Desc:      "Kabul 100km",
+			UTM:       kabul,
+			Precision: Precision100km,
+			MGRS:      "42SWD",
+			USNG:      "42S WD",
+		},
+		{
+			Desc: "Brasil",
+			UTM: Coord{
+				Northing: 8769581,
+				Easting:  667767,
+				Zone:     Zone{Number: 22, Letter: ZoneL},
+			},
+			Precision: Precision10m,
+			MGRS:      "22LFN67766958",
+			USNG:      "22L FN 6776 6958",
+		},
+		{
+			Desc:      "bad precision",
+			UTM:       kabul,
+			Precision: 6,
+			Err:       ErrInvalidPrecision,
+		},
+		{
+			Desc:      "polar",
+			UTM:       Coord{Zone: Zone{Number: 0, Letter: ZoneX}},
+			Precision: Precision1m,
+			Err:       ErrPolarRegion,
+		},
+	}
+
+	for i := range tests {
+		t.Run(tests[i].Desc, fn(tests[i]))
+	}
+}
+
+func TestParseMGRS(t *testing.T) {
+	type tcase struct {
+		Desc      string
+		MGRS      string
+		UTM       Coord
+		Precision Precision
+		Err       error
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			utm, prec, err := ParseMGRS(tc.MGRS)
+			if err != tc.Err {
+				t.Fatalf("error, expected %v got %v", tc.Err, err)
+			}
+			if tc.Err != nil {
+				return
+			}
+			if prec != tc.Precision {
+				t.Errorf("precision, expected %v got %v", tc.Precision, prec)
+			}
+			if utm != tc.UTM {
+				t.Errorf("utm, expected %v got %v", tc.UTM, utm)
+			}
+		}
+	}
+
+	tests := []tcase{
+		{
+			Desc:      "vienna",
+			MGRS:      "33UXP0416614591",
+			Precision: Precision1m,
+			UTM: Coord{
+				Easting:  604166,
+				Northing: 5314591,
+				Zone:     Zone{Number: 33, Letter: ZoneU},
+				Digraph:  Digraph{'X', 'P'},
+			},
+		},
+		{
+			Desc:      "Kabul usng",
+			MGRS:      "42S WD 13 20",
+			Precision: Precision1km,
+			UTM: Coord{
+				Easting:  513000,
+				Northing: 3820000,
+				Zone:     Zone{Number: 42, Letter: ZoneS},
+				Digraph:  Digraph{'W', 'D'},
+			},
+		},
+		{
+			Desc:      "Brasil",
+			MGRS:      "22lfn67766958",
+			Precision: Precision10m,
+			UTM: Coord{
+				Easting:  667760,
+				Northing: 8769580,
+				Zone:     Zone{Number: 22, Letter: ZoneL},
+				Digraph:  Digraph{'F', 'N'},
+			},
+		},
+		{
+			Desc:      "single digit zone",
+			MGRS:      "4QFJ",
+			Precision: Precision100km,
+			UTM: Coord{
+				Easting:  600000,
+				Northing: 2300000,
+				Zone:     Zone{Number: 4, Letter: ZoneQ},
+				Digraph:  Digraph{'F', 'J'},
+			},
+		},
+		{
+			Desc: "ups",
+			MGRS: "ZGC2677330125",
+			Err:  ErrPolarRegion,
+		},
+		{
+			Desc: "odd digits",
+			MGRS: "33UXP041661459",
+			Err:  ErrInvalidMGRS,
+		},
+		{
+			Desc: "bad column",
+			MGRS: "33UAP0416614591",
+			Err:  ErrInvalidMGRS,
+		},
+		{
+			Desc: "bad zone",
+			MGRS: "61UXP0416614591",
+			Err:  ErrInvalidZone,
+		},
+	}
+
+	for i := range tests {
+		t.Run(tests[i].Desc, fn(tests[i]))
+	}
+}
+
+func TestMGRSLngLat(t *testing.T) {
+	type tcase struct {
+		Desc   string
+		LngLat coord.LngLat
+		MGRS   string
+		Err    error
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			mgrs, err := MGRSFromLngLat(tc.LngLat, Precision1m)
+			if err != tc.Err {
+				t.Fatalf("error, expected %v got %v", tc.Err, err)
+			}
+			if tc.Err != nil {
+				return
+			}
+			if mgrs != tc.MGRS {
+				t.Errorf("mgrs, expected %v got %v", tc.MGRS, mgrs)
+			}
+
+			// we should get back to within a meter or so.
+			lnglat, err := MGRSToLngLat(mgrs)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			tol := 0.00005
+			if d := lnglat.Lng - tc.LngLat.Lng; d > tol || d < -tol {
+				t.Errorf("lng, expected %v got %v", tc.LngLat.Lng, lnglat.Lng)
+			}
+			if d := lnglat.Lat - tc.LngLat.Lat; d > tol || d < -tol {
+				t.Errorf("lat, expected %v got %v", tc.LngLat.Lat, lnglat.Lat)
+			}
+		}
+	}
+
+	tests := []tcase{
+		{
+			Desc:   "vienna",
+			LngLat: coord.LngLat{Lng: 16.41, Lat: 48.21},
+			MGRS:   "33UXP0475040602",
+		},
+		{
+			Desc:   "washington",
+			LngLat: coord.LngLat{Lng: -77.0365, Lat: 38.8977},
+			MGRS:   "18SUJ2339407395",
+		},
+		{
+			Desc:   "sydney",
+			LngLat: coord.LngLat{Lng: 151.2153, Lat: -33.8568},
+			MGRS:   "56HLH3490052288",
+		},
+		{
+			Desc:   "north pole",
+			LngLat: coord.LngLat{Lng: 10, Lat: 88},
+			Err:    ErrPolarRegion,
+		},
+		{
+			Desc:   "south pole",
+			LngLat: coord.LngLat{Lng: 10, Lat: -81},
+			Err:    ErrPolarRegion,
+		},
+	}
+
+	for i := range tests {
+		t.Run(tests[i].Desc, fn(tests[i]))
+	}
+}
@@ -182,6 +182,19 @@ func (z Zone) IsNorthern() bool { return z.Letter.IsNorthern() }
 // IsValid will run validity check on the zone letter and number
 func (z Zone) IsValid() bool { return z.Letter.IsValid() && z.Number >= 1 && z.Number <= 60 }
 
+// EPSGCode returns the EPSG code of the WGS 84 UTM projection for the zone,
+// 326xx for the northern hemisphere and 327xx for the southern hemisphere.
+// 0 is returned if the zone is not valid.
+func (z Zone) EPSGCode() uint {
+	if !z.IsValid() {
+		return 0
+	}
+	if z.IsNorthern() {
+		return 32600 + uint(z.Number)
+	}
+	return 32700 + uint(z.Number)
+}
+
 // ZoneNumberFromLngLat will get the zone number for the given LngLat value.
 //
 // The returned value will be from 1-60.
@@ -191,9 +204,10 @@ func (z Zone) IsValid() bool { return z.Letter.IsValid() && z.Number >= 1 && z.N
 //	 Transcribed from:
 //		 https://github.com/gdey/GDGeoCocoa/blob/master/GDGeoCoordConv.m
 func ZoneNumberFromLngLat(lnglat coord.LngLat) int {
+	lnglat = lnglat.NormalizeLng()
 	lng, lat := lnglat.Lng, lnglat.Lat
-	if (lat > 84.0 && lat < 90.0) || // North Pole
-		(lat > -80.0 && lat < -90.0) { // South Pole
+	if lat > 84.0 || // North Pole
+		lat < -80.0 { // South Pole
 		return 0
 	}
 
@@ -214,6 +228,10 @@ func ZoneNumberFromLngLat(lnglat coord.LngLat) int {
 			return 37
 		}
 	}
+	// 180° is the east edge of zone 60
+	if lng == 180.0 {
+		return 60
+	}
 	// Recast from [-180,180) to [0,360).
 	// the w<-> is then divided into 60 zones from 1-60.
 	return int((lng+180)/6) + 1
@@ -499,7 +517,7 @@ func (c Coord) ToLngLat(ellips coord.Ellipsoid) (coord.LngLat, error) {
 				(5+3*t1+10*c1-4*c12-9*eccPrimeSqr)*
 					math.Pow(d, 4)/24+
 				(61+90*t1+298*c1+45*t12-252*eccPrimeSqr-c12_3)*
-					math.Pow(d, 6)*720)
+					math.Pow(d, 6)/720)
 
 	lngRad := (d -
 		(1+2*t1+c1)*
@@ -283,7 +283,7 @@ func TestCoord_ToLngLat(t *testing.T) {
 		{
 			Desc: "Brazil",
 			LngLat: coord.LngLat{
-				Lat: -11.126489480072872,
+				Lat: -11.126665013816657,
 				Lng: -43.46380056756961,
 			},
 			UTM: Coord{
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+)
+
+// Image draws the layers, in order, into a new image. Shapes are not anti-aliased.
+func (c Canvas) Image(layers ...Layer) (*image.RGBA, error) {
+	ls, err := c.shapes(layers)
+	if err != nil {
+		return nil, err
+	}
+	width, height := c.size()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if c.Background != nil {
+		draw.Draw(img, img.Bounds(), image.NewUniform(c.Background), image.Point{}, draw.Src)
+	}
+
+	// each part of a geometry is drawn into the mask first, so overlapping
+	// parts are not blended twice.
+	mask := image.NewAlpha(img.Bounds())
+	paint := func(clr color.Color) {
+		draw.DrawMask(img, img.Bounds(), image.NewUniform(clr), image.Point{}, mask, image.Point{}, draw.Over)
+		for i := range mask.Pix {
+			mask.Pix[i] = 0
+		}
+	}
+
+	for _, l := range ls {
+		halfWidth := l.style.StrokeWidth / 2
+		for _, sh := range l.shapes {
+			if l.style.Fill != nil && len(sh.polygons) > 0 {
+				for _, ply := range sh.polygons {
+					fillRings(mask, ply)
+				}
+				paint(l.style.Fill)
+			}
+			if l.style.Stroke != nil && (len(sh.polygons) > 0 || len(sh.lines) > 0) {
+				for _, ply := range sh.polygons {
+					for _, r := range ply {
+						if len(r) == 0 {
+							continue
+						}
+						strokeLine(mask, append(r, r[0]), halfWidth)
+					}
+				}
+				for _, line := range sh.lines {
+					strokeLine(mask, line, halfWidth)
+				}
+				paint(l.style.Stroke)
+			}
+			if len(sh.points) > 0 {
+				for _, pt := range sh.points {
+					fillCircle(mask, pt, l.style.PointRadius)
+				}
+				paint(l.style.pointColor())
+			}
+		}
+	}
+	return img, nil
+}
+
+// PNG draws the layers and writes the image as a PNG to w. See Image.
+func (c Canvas) PNG(w io.Writer, layers ...Layer) error {
+	img, err := c.Image(layers...)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// fillRings fills the area inside the rings, using the even-odd rule, into the mask.
+// A pixel is filled if its center is inside.
+func fillRings(mask *image.Alpha, rings [][][2]float64) {
+	b := mask.Bounds()
+	miny, maxy := math.Inf(1), math.Inf(-1)
+	for _, r := range rings {
+		for _, pt := range r {
+			miny, maxy = math.Min(miny, pt[1]), math.Max(maxy, pt[1])
+		}
+	}
+	if math.IsInf(miny, 0) {
+		return
+	}
+	y0 := clampInt(int(math.Floor(miny)), b.Min.Y, b.Max.Y)
+	y1 := clampInt(int(math.Ceil(maxy)), b.Min.Y, b.Max.Y)
+
+	var xs []float64
+	for y := y0; y < y1; y++ {
+		cy := float64(y) + 0.5
+		xs = xs[:0]
+		for _, r := range rings {
+			for i := range r {
+				p1, p2 := r[i], r[(i+1)%len(r)]
+				if (p1[1] <= cy) == (p2[1] <= cy) {
+					continue
+				}
+				xs = append(xs, p1[0]+(cy-p1[1])*(p2[0]-p1[0])/(p2[1]-p1[1]))
+			}
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			x0 := clampInt(int(math.Ceil(xs[i]-0.5)), b.Min.X, b.Max.X)
+			x1 := clampInt(int(math.Ceil(xs[i+1]-0.5)), b.Min.X, b.Max.X)
+			for x := x0; x < x1; x++ {
+				mask.SetAlpha(x, y, color.Alpha{A: 0xff})
+			}
+		}
+	}
+}
+
+// strokeLine draws the line, with round joins and caps, into the mask
+func strokeLine(mask *image.Alpha, line [][2]float64, halfWidth float64) {
+	for i := range line {
+		fillCircle(mask, line[i], halfWidth)
+		if i == 0 {
+			continue
+		}
+		p1, p2 := line[i-1], line[i]
+		dx, dy := p2[0]-p1[0], p2[1]-p1[1]
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		nx, ny := -dy/l*halfWidth, dx/l*halfWidth
+		fillRings(mask, [][][2]float64{{
+			{p1[0] + nx, p1[1] + ny},
+			{p2[0] + nx, p2[1] + ny},
+			{p2[0] - nx, p2[1] - ny},
+			{p1[0] - nx, p1[1] - ny},
+		}})
+	}
+}
+
+// fillCircle fills the pixels whose center is within the circle into the mask
+func fillCircle(mask *image.Alpha, c [2]float64, radius float64) {
+	b := mask.Bounds()
+	x0 := clampInt(int(math.Floor(c[0]-radius)), b.Min.X, b.Max.X)
+	x1 := clampInt(int(math.Ceil(c[0]+radius)), b.Min.X, b.Max.X)
+	y0 := clampInt(int(math.Floor(c[1]-radius)), b.Min.Y, b.Max.Y)
+	y1 := clampInt(int(math.Ceil(c[1]+radius)), b.Min.Y, b.Max.Y)
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			if math.Hypot(float64(x)+0.5-c[0], float64(y)+0.5-c[1]) <= radius {
+				mask.SetAlpha(x, y, color.Alpha{A: 0xff})
+			}
+		}
+	}
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
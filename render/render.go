@@ -0,0 +1,256 @@
+// Package render draws geometries as SVG or into an image, using only the
+// standard library. It is meant for looking at the geometries of failing
+// tests and for reports, not for cartography.
+//
+// The geometries are grouped in layers, each with its own style, and drawn
+// on a Canvas which fits them, or a given extent, into the canvas' size:
+//
+//	c := render.Canvas{Width: 512, Height: 512, FlipY: true}
+//	err := c.PNG(w,
+//		render.Layer{Name: "input", Style: render.Style{Fill: color.Gray{0xcc}}, Geometries: []geom.Geometry{input}},
+//		render.Layer{Name: "got", Geometries: []geom.Geometry{got}},
+//	)
+package render
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hahaking119/geom"
+)
+
+// DefaultSize is the width and height, in pixels, of a canvas that does not set them
+const DefaultSize = 512
+
+// DefaultStyle is the style of layers that set neither a stroke or fill color
+var DefaultStyle = Style{
+	Stroke:      color.Black,
+	StrokeWidth: 1,
+	PointRadius: 3,
+}
+
+// Style is how the geometries of a layer are drawn
+type Style struct {
+	// Stroke is the color of lines and the outlines of polygons, nil for none
+	Stroke color.Color
+	// Fill is the color of polygons and points, nil for none. Points are
+	// drawn with the Stroke color if there is no fill.
+	Fill color.Color
+	// StrokeWidth is the width of lines in pixels, defaults to 1
+	StrokeWidth float64
+	// PointRadius is the radius of points in pixels, defaults to 3
+	PointRadius float64
+}
+
+func (s Style) normalize() Style {
+	if s.Stroke == nil && s.Fill == nil {
+		return DefaultStyle
+	}
+	if s.StrokeWidth <= 0 {
+		s.StrokeWidth = DefaultStyle.StrokeWidth
+	}
+	if s.PointRadius <= 0 {
+		s.PointRadius = DefaultStyle.PointRadius
+	}
+	return s
+}
+
+// pointColor is the color points are drawn with
+func (s Style) pointColor() color.Color {
+	if s.Fill != nil {
+		return s.Fill
+	}
+	return s.Stroke
+}
+
+// Layer is a set of geometries drawn with the same style
+type Layer struct {
+	// Name of the layer, used as the id of the layer's group in SVG
+	Name string
+	// Style of the layer, see DefaultStyle
+	Style Style
+	// Geometries of the layer, collections are drawn as their geometries
+	Geometries []geom.Geometry
+}
+
+// TriangleLayer returns a layer that draws the outlines of the triangles
+// with the stroke of the style.
+func TriangleLayer(name string, style Style, triangles ...geom.Triangle) Layer {
+	style.Fill = nil
+	if style.Stroke == nil {
+		style.Stroke = DefaultStyle.Stroke
+	}
+	geos := make([]geom.Geometry, len(triangles))
+	for i := range triangles {
+		geos[i] = triangles[i]
+	}
+	return Layer{Name: name, Style: style, Geometries: geos}
+}
+
+// Canvas is the area the layers are drawn on
+type Canvas struct {
+	// Width and Height are the size in pixels, default to DefaultSize
+	Width, Height int
+	// Padding is the number of pixels around the extent that are left empty
+	Padding int
+	// Extent is the area that is drawn; if nil the extent of the geometries is used.
+	// The extent is scaled, keeping its aspect ratio, to fit the canvas.
+	Extent *geom.Extent
+	// FlipY draws y positive up, as in most coordinate systems, instead of
+	// positive down as in images.
+	FlipY bool
+	// Background is the color of the canvas, nil for transparent
+	Background color.Color
+}
+
+func (c Canvas) size() (width, height int) {
+	width, height = c.Width, c.Height
+	if width <= 0 {
+		width = DefaultSize
+	}
+	if height <= 0 {
+		height = DefaultSize
+	}
+	return width, height
+}
+
+// shape is a geometry broken into the parts that are drawn
+type shape struct {
+	points   [][2]float64
+	lines    [][][2]float64
+	polygons [][][][2]float64
+}
+
+// layerShapes are the shapes of the geometries of a layer
+type layerShapes struct {
+	name   string
+	style  Style
+	shapes []shape
+}
+
+// shapes returns the shapes of the layers in canvas coordinates
+func (c Canvas) shapes(layers []Layer) ([]layerShapes, error) {
+	ls := make([]layerShapes, len(layers))
+	var ext *geom.Extent
+	expand := func(pts ...[2]float64) {
+		for _, pt := range pts {
+			if ext == nil {
+				ext = geom.NewExtent(pt)
+				continue
+			}
+			ext.AddPoints(pt)
+		}
+	}
+	for i := range layers {
+		ls[i].name = layers[i].Name
+		ls[i].style = layers[i].Style.normalize()
+		for _, geo := range layers[i].Geometries {
+			var s shape
+			if err := s.add(geo); err != nil {
+				return nil, err
+			}
+			expand(s.points...)
+			for _, l := range s.lines {
+				expand(l...)
+			}
+			for _, ply := range s.polygons {
+				for _, r := range ply {
+					expand(r...)
+				}
+			}
+			ls[i].shapes = append(ls[i].shapes, s)
+		}
+	}
+	if c.Extent != nil {
+		ext = c.Extent
+	}
+	if ext == nil {
+		// nothing to draw
+		return ls, nil
+	}
+
+	width, height := c.size()
+	w, h := float64(width-2*c.Padding), float64(height-2*c.Padding)
+	scale := 1.0
+	switch xs, ys := ext.XSpan(), ext.YSpan(); {
+	case xs > 0 && ys > 0:
+		scale = math.Min(w/xs, h/ys)
+	case xs > 0:
+		scale = w / xs
+	case ys > 0:
+		scale = h / ys
+	}
+	// center the extent
+	offx := float64(c.Padding) + (w-ext.XSpan()*scale)/2
+	offy := float64(c.Padding) + (h-ext.YSpan()*scale)/2
+
+	tx := func(pts [][2]float64) {
+		for i, pt := range pts {
+			pts[i][0] = offx + (pt[0]-ext.MinX())*scale
+			if c.FlipY {
+				pts[i][1] = offy + (ext.MaxY()-pt[1])*scale
+				continue
+			}
+			pts[i][1] = offy + (pt[1]-ext.MinY())*scale
+		}
+	}
+	for i := range ls {
+		for _, s := range ls[i].shapes {
+			tx(s.points)
+			for _, l := range s.lines {
+				tx(l)
+			}
+			for _, ply := range s.polygons {
+				for _, r := range ply {
+					tx(r)
+				}
+			}
+		}
+	}
+	return ls, nil
+}
+
+// add adds a copy of the geometry's coordinates to the shape
+func (s *shape) add(geo geom.Geometry) error {
+	cp := func(pts [][2]float64) [][2]float64 { return append([][2]float64(nil), pts...) }
+	polygon := func(rings [][][2]float64) [][][2]float64 {
+		ply := make([][][2]float64, 0, len(rings))
+		for _, r := range rings {
+			ply = append(ply, cp(r))
+		}
+		return ply
+	}
+
+	switch g := geo.(type) {
+	case nil:
+	case *geom.Extent:
+		if g != nil {
+			s.polygons = append(s.polygons, [][][2]float64{g.Vertices()})
+		}
+	case geom.Pointer:
+		s.points = append(s.points, g.XY())
+	case geom.MultiPointer:
+		s.points = append(s.points, g.Points()...)
+	case geom.LineStringer:
+		s.lines = append(s.lines, cp(g.Vertices()))
+	case geom.MultiLineStringer:
+		for _, l := range g.LineStrings() {
+			s.lines = append(s.lines, cp(l))
+		}
+	case geom.Polygoner:
+		s.polygons = append(s.polygons, polygon(g.LinearRings()))
+	case geom.MultiPolygoner:
+		for _, p := range g.Polygons() {
+			s.polygons = append(s.polygons, polygon(p))
+		}
+	case geom.Collectioner:
+		for _, gg := range g.Geometries() {
+			if err := s.add(gg); err != nil {
+				return err
+			}
+		}
+	default:
+		return geom.ErrUnknownGeometry{Geom: geo}
+	}
+	return nil
+}
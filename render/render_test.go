@@ -0,0 +1,194 @@
+package render_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/render"
+)
+
+var (
+	red   = color.RGBA{R: 0xff, A: 0xff}
+	blue  = color.RGBA{B: 0xff, A: 0xff}
+	white = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+func TestCanvasImage(t *testing.T) {
+	type tcase struct {
+		canvas render.Canvas
+		layers []render.Layer
+		// expected colors of pixels
+		expected map[[2]int]color.RGBA
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			img, err := tc.canvas.Image(tc.layers...)
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			for pt, clr := range tc.expected {
+				if got := img.RGBAAt(pt[0], pt[1]); got != clr {
+					t.Errorf("pixel %v, expected %v got %v", pt, clr, got)
+				}
+			}
+		}
+	}
+
+	// a 10x10 square with a 2x2 hole, drawn on a 100x100 canvas
+	square := geom.Polygon{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+		{{6, 6}, {8, 6}, {8, 8}, {6, 8}},
+	}
+
+	tests := map[string]tcase{
+		"fill": {
+			canvas: render.Canvas{Width: 100, Height: 100, Background: white},
+			layers: []render.Layer{{Style: render.Style{Fill: red}, Geometries: []geom.Geometry{square}}},
+			expected: map[[2]int]color.RGBA{
+				{20, 20}: red,
+				{70, 70}: white, // hole
+				{90, 30}: red,
+			},
+		},
+		"fill flipped": {
+			canvas: render.Canvas{Width: 100, Height: 100, Background: white, FlipY: true},
+			layers: []render.Layer{{Style: render.Style{Fill: red}, Geometries: []geom.Geometry{square}}},
+			expected: map[[2]int]color.RGBA{
+				{70, 70}: red,
+				{70, 30}: white, // hole
+			},
+		},
+		"extent and padding": {
+			canvas: render.Canvas{
+				Width: 100, Height: 100, Padding: 10, Background: white,
+				Extent: geom.NewExtent([2]float64{0, 0}, [2]float64{20, 20}),
+			},
+			layers: []render.Layer{{Style: render.Style{Fill: red}, Geometries: []geom.Geometry{square}}},
+			expected: map[[2]int]color.RGBA{
+				{5, 5}:   white, // padding
+				{15, 15}: red,
+				{55, 55}: white, // outside the square
+			},
+		},
+		"line and point": {
+			canvas: render.Canvas{Width: 100, Height: 100, Background: white},
+			layers: []render.Layer{
+				{
+					Style:      render.Style{Stroke: blue, StrokeWidth: 2},
+					Geometries: []geom.Geometry{geom.LineString{{0, 5}, {10, 5}}},
+				},
+				{
+					Style:      render.Style{Fill: red, PointRadius: 4},
+					Geometries: []geom.Geometry{geom.Collection{geom.Point{5, 0}, geom.Point{5, 10}}},
+				},
+			},
+			expected: map[[2]int]color.RGBA{
+				{20, 50}: blue,
+				{20, 55}: white,
+				{50, 2}:  red,
+				{50, 97}: red,
+				{50, 20}: white,
+			},
+		},
+		"triangle outlines": {
+			canvas: render.Canvas{Width: 100, Height: 100, Padding: 5, Background: white},
+			layers: []render.Layer{
+				render.TriangleLayer("triangles", render.Style{Stroke: blue, Fill: red}, geom.Triangle{{0, 0}, {10, 0}, {0, 10}}),
+			},
+			expected: map[[2]int]color.RGBA{
+				{50, 4}:  blue,
+				{4, 50}:  blue,
+				{20, 20}: white, // not filled
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestCanvasSVG(t *testing.T) {
+	type tcase struct {
+		layers   []render.Layer
+		expected []string
+		err      bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			var buf bytes.Buffer
+			err := render.Canvas{Width: 100, Height: 100}.SVG(&buf, tc.layers...)
+			if tc.err {
+				if err == nil {
+					t.Errorf("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			for _, s := range tc.expected {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("svg, expected to contain %q got\n%v", s, buf.String())
+				}
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"polygon": {
+			layers: []render.Layer{{
+				Name:       "a<b",
+				Style:      render.Style{Fill: color.NRGBA{R: 0xff, A: 0x80}},
+				Geometries: []geom.Geometry{geom.Polygon{{{0, 0}, {10, 0}, {10, 10}}}},
+			}},
+			expected: []string{
+				`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100" viewBox="0 0 100 100">`,
+				`<g id="a&lt;b" fill="#ff0000" fill-opacity="0.5" stroke="none" stroke-width="1"`,
+				`<path fill-rule="evenodd" d="M0 0L100 0L100 100Z"/>`,
+			},
+		},
+		"line and point": {
+			layers: []render.Layer{{
+				Geometries: []geom.Geometry{
+					geom.LineString{{0, 0}, {5, 10}},
+					geom.Point{10, 10},
+				},
+			}},
+			expected: []string{
+				`<g fill="none" stroke="#000000" stroke-width="1"`,
+				`<path fill="none" d="M0 0L50 100"/>`,
+				`<circle cx="100" cy="100" r="3" fill="#000000" stroke="none"/>`,
+			},
+		},
+		"unknown geometry": {
+			layers: []render.Layer{{Geometries: []geom.Geometry{"a"}}},
+			err:    true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestCanvasPNG(t *testing.T) {
+	var buf bytes.Buffer
+	c := render.Canvas{Width: 20, Height: 10}
+	if err := c.PNG(&buf, render.Layer{Geometries: []geom.Geometry{geom.Point{1, 1}}}); err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode error, expected nil got %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 10 {
+		t.Errorf("size, expected 20x10 got %vx%v", b.Dx(), b.Dy())
+	}
+}
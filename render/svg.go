@@ -0,0 +1,101 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SVG writes the layers, in order, as an SVG document to w. Each layer is a group,
+// with the layer's name as its id.
+func (c Canvas) SVG(w io.Writer, layers ...Layer) error {
+	ls, err := c.shapes(layers)
+	if err != nil {
+		return err
+	}
+	width, height := c.size()
+
+	var s strings.Builder
+	fmt.Fprintf(&s, `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="%[2]d" viewBox="0 0 %[1]d %[2]d">`+"\n", width, height)
+	if c.Background != nil {
+		fmt.Fprintf(&s, `<rect width="100%%" height="100%%" %v/>`+"\n", svgColor("fill", c.Background))
+	}
+
+	for _, l := range ls {
+		s.WriteString(`<g`)
+		if l.name != "" {
+			s.WriteString(` id="`)
+			xml.EscapeText(&s, []byte(l.name))
+			s.WriteString(`"`)
+		}
+		fmt.Fprintf(&s, ` %v %v stroke-width="%v" stroke-linejoin="round" stroke-linecap="round">`+"\n",
+			svgColor("fill", l.style.Fill),
+			svgColor("stroke", l.style.Stroke),
+			svgFloat(l.style.StrokeWidth),
+		)
+
+		for _, sh := range l.shapes {
+			for _, ply := range sh.polygons {
+				s.WriteString(`<path fill-rule="evenodd" d="`)
+				for _, r := range ply {
+					svgPath(&s, r)
+					s.WriteString(`Z`)
+				}
+				s.WriteString(`"/>` + "\n")
+			}
+			for _, line := range sh.lines {
+				s.WriteString(`<path fill="none" d="`)
+				svgPath(&s, line)
+				s.WriteString(`"/>` + "\n")
+			}
+			for _, pt := range sh.points {
+				fmt.Fprintf(&s, `<circle cx="%v" cy="%v" r="%v" %v stroke="none"/>`+"\n",
+					svgFloat(pt[0]), svgFloat(pt[1]), svgFloat(l.style.PointRadius),
+					svgColor("fill", l.style.pointColor()),
+				)
+			}
+		}
+		s.WriteString("</g>\n")
+	}
+	s.WriteString("</svg>\n")
+
+	_, err = io.WriteString(w, s.String())
+	return err
+}
+
+// svgPath writes the points as path commands
+func svgPath(s *strings.Builder, pts [][2]float64) {
+	for i, pt := range pts {
+		cmd := "L"
+		if i == 0 {
+			cmd = "M"
+		}
+		fmt.Fprintf(s, "%v%v %v", cmd, svgFloat(pt[0]), svgFloat(pt[1]))
+	}
+}
+
+// svgColor returns the attributes for the color and opacity of the color
+func svgColor(attr string, c color.Color) string {
+	if c == nil {
+		return attr + `="none"`
+	}
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	str := fmt.Sprintf(`%v="#%02x%02x%02x"`, attr, nc.R, nc.G, nc.B)
+	if nc.A != 0xff {
+		str += fmt.Sprintf(` %v-opacity="%v"`, attr, svgFloat(float64(nc.A)/0xff))
+	}
+	return str
+}
+
+// svgFloat formats the float with at most 2 decimals
+func svgFloat(f float64) string {
+	str := strconv.FormatFloat(f, 'f', 2, 64)
+	str = strings.TrimRight(strings.TrimRight(str, "0"), ".")
+	if str == "-0" {
+		return "0"
+	}
+	return str
+}
@@ -0,0 +1,200 @@
+package reproject
+
+import (
+	"math"
+
+	"github.com/hahaking119/geom"
+)
+
+// pieces returns the number of pieces the edge a,b needs to be broken
+// into so that no piece is longer then max.
+func pieces(a, b []float64, max float64) int {
+	d := math.Hypot(b[0]-a[0], b[1]-a[1])
+	if d <= max {
+		return 1
+	}
+	return int(math.Ceil(d / max))
+}
+
+// lerp sets dst to the point t of the way along a,b
+func lerp(dst, a, b []float64, t float64) {
+	for i := range dst {
+		dst[i] = a[i] + (b[i]-a[i])*t
+	}
+}
+
+func densify2(pts [][2]float64, max float64, closed bool) [][2]float64 {
+	out := make([][2]float64, 0, len(pts))
+	for i := range pts {
+		out = append(out, pts[i])
+		j := i + 1
+		if j == len(pts) {
+			if !closed || len(pts) < 3 {
+				break
+			}
+			j = 0
+		}
+		n := pieces(pts[i][:], pts[j][:], max)
+		for k := 1; k < n; k++ {
+			var pt [2]float64
+			lerp(pt[:], pts[i][:], pts[j][:], float64(k)/float64(n))
+			out = append(out, pt)
+		}
+	}
+	return out
+}
+
+func densify3(pts [][3]float64, max float64, closed bool) [][3]float64 {
+	out := make([][3]float64, 0, len(pts))
+	for i := range pts {
+		out = append(out, pts[i])
+		j := i + 1
+		if j == len(pts) {
+			if !closed || len(pts) < 3 {
+				break
+			}
+			j = 0
+		}
+		n := pieces(pts[i][:], pts[j][:], max)
+		for k := 1; k < n; k++ {
+			var pt [3]float64
+			lerp(pt[:], pts[i][:], pts[j][:], float64(k)/float64(n))
+			out = append(out, pt)
+		}
+	}
+	return out
+}
+
+func densify4(pts [][4]float64, max float64, closed bool) [][4]float64 {
+	out := make([][4]float64, 0, len(pts))
+	for i := range pts {
+		out = append(out, pts[i])
+		j := i + 1
+		if j == len(pts) {
+			if !closed || len(pts) < 3 {
+				break
+			}
+			j = 0
+		}
+		n := pieces(pts[i][:], pts[j][:], max)
+		for k := 1; k < n; k++ {
+			var pt [4]float64
+			lerp(pt[:], pts[i][:], pts[j][:], float64(k)/float64(n))
+			out = append(out, pt)
+		}
+	}
+	return out
+}
+
+func densifyLines2(lines [][][2]float64, max float64, closed bool) [][][2]float64 {
+	out := make([][][2]float64, len(lines))
+	for i := range lines {
+		out[i] = densify2(lines[i], max, closed)
+	}
+	return out
+}
+
+func densifyLines3(lines [][][3]float64, max float64, closed bool) [][][3]float64 {
+	out := make([][][3]float64, len(lines))
+	for i := range lines {
+		out[i] = densify3(lines[i], max, closed)
+	}
+	return out
+}
+
+func densifyLines4(lines [][][4]float64, max float64, closed bool) [][][4]float64 {
+	out := make([][][4]float64, len(lines))
+	for i := range lines {
+		out[i] = densify4(lines[i], max, closed)
+	}
+	return out
+}
+
+// Densify returns a copy of the geometry where vertices have been added so
+// that no edge is longer then max. The added vertices are linearly
+// interpolated, including the Z and M values. The closing edge of
+// polygon rings is densified as well. Points are returned as is.
+func Densify(g geom.Geometry, max float64) (geom.Geometry, error) {
+	switch gg := g.(type) {
+	default:
+		return nil, geom.ErrUnknownGeometry{Geom: g}
+
+	case geom.Point, geom.PointZ, geom.PointM, geom.PointZM,
+		geom.PointS, geom.PointZS, geom.PointMS, geom.PointZMS,
+		geom.MultiPoint, geom.MultiPointZ, geom.MultiPointM, geom.MultiPointZM,
+		geom.MultiPointS, geom.MultiPointZS, geom.MultiPointMS, geom.MultiPointZMS:
+		return g, nil
+
+	case geom.LineString:
+		return geom.LineString(densify2(gg, max, false)), nil
+	case geom.LineStringZ:
+		return geom.LineStringZ(densify3(gg, max, false)), nil
+	case geom.LineStringM:
+		return geom.LineStringM(densify3(gg, max, false)), nil
+	case geom.LineStringZM:
+		return geom.LineStringZM(densify4(gg, max, false)), nil
+
+	case geom.MultiLineString:
+		return geom.MultiLineString(densifyLines2(gg, max, false)), nil
+	case geom.MultiLineStringZ:
+		return geom.MultiLineStringZ(densifyLines3(gg, max, false)), nil
+	case geom.MultiLineStringM:
+		return geom.MultiLineStringM(densifyLines3(gg, max, false)), nil
+	case geom.MultiLineStringZM:
+		return geom.MultiLineStringZM(densifyLines4(gg, max, false)), nil
+
+	case geom.Polygon:
+		return geom.Polygon(densifyLines2(gg, max, true)), nil
+	case geom.PolygonZ:
+		return geom.PolygonZ(densifyLines3(gg, max, true)), nil
+	case geom.PolygonM:
+		return geom.PolygonM(densifyLines3(gg, max, true)), nil
+	case geom.PolygonZM:
+		return geom.PolygonZM(densifyLines4(gg, max, true)), nil
+
+	case geom.MultiPolygon:
+		mp := make(geom.MultiPolygon, len(gg))
+		for i := range gg {
+			mp[i] = densifyLines2(gg[i], max, true)
+		}
+		return mp, nil
+
+	case geom.LineStringS:
+		return geom.LineStringS{Srid: gg.Srid, Ls: densify2(gg.Ls, max, false)}, nil
+	case geom.LineStringZS:
+		return geom.LineStringZS{Srid: gg.Srid, Lsz: densify3(gg.Lsz, max, false)}, nil
+	case geom.LineStringMS:
+		return geom.LineStringMS{Srid: gg.Srid, Lsm: densify3(gg.Lsm, max, false)}, nil
+	case geom.LineStringZMS:
+		return geom.LineStringZMS{Srid: gg.Srid, Lszm: densify4(gg.Lszm, max, false)}, nil
+
+	case geom.MultiLineStringS:
+		return geom.MultiLineStringS{Srid: gg.Srid, Mls: densifyLines2(gg.Mls, max, false)}, nil
+	case geom.MultiLineStringZS:
+		return geom.MultiLineStringZS{Srid: gg.Srid, Mlsz: densifyLines3(gg.Mlsz, max, false)}, nil
+	case geom.MultiLineStringMS:
+		return geom.MultiLineStringMS{Srid: gg.Srid, Mlsm: densifyLines3(gg.Mlsm, max, false)}, nil
+	case geom.MultiLineStringZMS:
+		return geom.MultiLineStringZMS{Srid: gg.Srid, Mlszm: densifyLines4(gg.Mlszm, max, false)}, nil
+
+	case geom.PolygonS:
+		return geom.PolygonS{Srid: gg.Srid, Pol: densifyLines2(gg.Pol, max, true)}, nil
+	case geom.PolygonZS:
+		return geom.PolygonZS{Srid: gg.Srid, Polz: densifyLines3(gg.Polz, max, true)}, nil
+	case geom.PolygonMS:
+		return geom.PolygonMS{Srid: gg.Srid, Polm: densifyLines3(gg.Polm, max, true)}, nil
+	case geom.PolygonZMS:
+		return geom.PolygonZMS{Srid: gg.Srid, Polzm: densifyLines4(gg.Polzm, max, true)}, nil
+
+	case geom.Collection:
+		col := make(geom.Collection, len(gg))
+		for i := range gg {
+			ng, err := Densify(gg[i], max)
+			if err != nil {
+				return nil, err
+			}
+			col[i] = ng
+		}
+		return col, nil
+	}
+}
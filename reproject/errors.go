@@ -0,0 +1,8 @@
+package reproject
+
+import "github.com/gdey/errors"
+
+const (
+	// ErrUnsupportedSRID will be returned if the given EPSG code can not be reprojected
+	ErrUnsupportedSRID = errors.String("unsupported srid")
+)
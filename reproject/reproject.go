@@ -0,0 +1,248 @@
+/*
+Package reproject provides the ability to transform geometries between
+coordinate reference systems.
+
+The supported EPSG codes are the ones supported by github.com/go-spatial/proj:
+
+	4326 (WGS84, lng/lat)
+	3857 (Web Mercator)
+	3395 (World Mercator)
+	4087 (World Equidistant Cylindrical)
+*/
+package reproject
+
+import (
+	"github.com/go-spatial/proj"
+	"github.com/hahaking119/geom"
+)
+
+// Supported reports whether the given EPSG code can be reprojected to and from.
+func Supported(srid uint) bool {
+	switch srid {
+	case 4326, 3857, 3395, 4087:
+		return true
+	default:
+		return false
+	}
+}
+
+// Transformer transforms geometries from one coordinate reference
+// system to another.
+type Transformer struct {
+	// From is the EPSG code the geometries are in. The S geometries
+	// with a non-zero Srid use that instead.
+	From uint
+	// To is the EPSG code to transform the geometries to.
+	To uint
+	// Densify, when greater than zero, is the longest an edge, in the
+	// units of the source system, may be before it is reprojected.
+	// Longer edges have vertices added so curves stay accurate.
+	Densify float64
+}
+
+// New returns a Transformer from one EPSG code to another.
+//
+// Possible errors:
+//
+//	ErrUnsupportedSRID
+func New(from, to uint) (*Transformer, error) {
+	if !Supported(from) || !Supported(to) {
+		return nil, ErrUnsupportedSRID
+	}
+	return &Transformer{From: from, To: to}, nil
+}
+
+// Geometry reprojects the geometry from one EPSG code to another.
+func Geometry(from, to uint, g geom.Geometry) (geom.Geometry, error) {
+	t, err := New(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return t.Geometry(g)
+}
+
+// Point reprojects a single point from one EPSG code to another.
+func Point(from, to uint, pt [2]float64) ([2]float64, error) {
+	xy, err := convert(from, to, pt[:])
+	if err != nil {
+		return pt, err
+	}
+	return [2]float64{xy[0], xy[1]}, nil
+}
+
+// convert transforms the given x,y pairs going through 4326 as needed.
+func convert(from, to uint, xy []float64) ([]float64, error) {
+	if !Supported(from) || !Supported(to) {
+		return nil, ErrUnsupportedSRID
+	}
+	if from == to {
+		return xy, nil
+	}
+
+	var err error
+	if from != 4326 {
+		if xy, err = proj.Inverse(proj.EPSGCode(from), xy); err != nil {
+			return nil, err
+		}
+	}
+	if to != 4326 {
+		if xy, err = proj.Convert(proj.EPSGCode(to), xy); err != nil {
+			return nil, err
+		}
+	}
+	return xy, nil
+}
+
+// srid returns the srid of the S geometries, or zero if the geometry
+// does not have one.
+func srid(g geom.Geometry) uint32 {
+	switch gg := g.(type) {
+	case geom.PointS:
+		return gg.Srid
+	case geom.PointZS:
+		return gg.Srid
+	case geom.PointMS:
+		return gg.Srid
+	case geom.PointZMS:
+		return gg.Srid
+	case geom.MultiPointS:
+		return gg.Srid
+	case geom.MultiPointZS:
+		return gg.Srid
+	case geom.MultiPointMS:
+		return gg.Srid
+	case geom.MultiPointZMS:
+		return gg.Srid
+	case geom.LineStringS:
+		return gg.Srid
+	case geom.LineStringZS:
+		return gg.Srid
+	case geom.LineStringMS:
+		return gg.Srid
+	case geom.LineStringZMS:
+		return gg.Srid
+	case geom.MultiLineStringS:
+		return gg.Srid
+	case geom.MultiLineStringZS:
+		return gg.Srid
+	case geom.MultiLineStringMS:
+		return gg.Srid
+	case geom.MultiLineStringZMS:
+		return gg.Srid
+	case geom.PolygonS:
+		return gg.Srid
+	case geom.PolygonZS:
+		return gg.Srid
+	case geom.PolygonMS:
+		return gg.Srid
+	case geom.PolygonZMS:
+		return gg.Srid
+	default:
+		return 0
+	}
+}
+
+// setSrid returns the geometry with the srid of the S geometries set.
+func setSrid(g geom.Geometry, srid uint32) geom.Geometry {
+	switch gg := g.(type) {
+	case geom.PointS:
+		gg.Srid = srid
+		return gg
+	case geom.PointZS:
+		gg.Srid = srid
+		return gg
+	case geom.PointMS:
+		gg.Srid = srid
+		return gg
+	case geom.PointZMS:
+		gg.Srid = srid
+		return gg
+	case geom.MultiPointS:
+		gg.Srid = srid
+		return gg
+	case geom.MultiPointZS:
+		gg.Srid = srid
+		return gg
+	case geom.MultiPointMS:
+		gg.Srid = srid
+		return gg
+	case geom.MultiPointZMS:
+		gg.Srid = srid
+		return gg
+	case geom.LineStringS:
+		gg.Srid = srid
+		return gg
+	case geom.LineStringZS:
+		gg.Srid = srid
+		return gg
+	case geom.LineStringMS:
+		gg.Srid = srid
+		return gg
+	case geom.LineStringZMS:
+		gg.Srid = srid
+		return gg
+	case geom.MultiLineStringS:
+		gg.Srid = srid
+		return gg
+	case geom.MultiLineStringZS:
+		gg.Srid = srid
+		return gg
+	case geom.MultiLineStringMS:
+		gg.Srid = srid
+		return gg
+	case geom.MultiLineStringZMS:
+		gg.Srid = srid
+		return gg
+	case geom.PolygonS:
+		gg.Srid = srid
+		return gg
+	case geom.PolygonZS:
+		gg.Srid = srid
+		return gg
+	case geom.PolygonMS:
+		gg.Srid = srid
+		return gg
+	case geom.PolygonZMS:
+		gg.Srid = srid
+		return gg
+	default:
+		return g
+	}
+}
+
+// Geometry returns a copy of the geometry reprojected to the Transformer's
+// To system. Z and M values are kept as is; the Srid of the S geometries is
+// set to To.
+func (t Transformer) Geometry(g geom.Geometry) (geom.Geometry, error) {
+	if col, ok := g.(geom.Collection); ok {
+		ncol := make(geom.Collection, len(col))
+		for i := range col {
+			ng, err := t.Geometry(col[i])
+			if err != nil {
+				return nil, err
+			}
+			ncol[i] = ng
+		}
+		return ncol, nil
+	}
+
+	from := t.From
+	if s := srid(g); s != 0 {
+		from = uint(s)
+	}
+
+	var err error
+	if t.Densify > 0 {
+		if g, err = Densify(g, t.Densify); err != nil {
+			return nil, err
+		}
+	}
+
+	g, err = geom.ApplyToPoints(g, func(coords ...float64) ([]float64, error) {
+		return convert(from, t.To, coords[:2])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return setSrid(g, uint32(t.To)), nil
+}
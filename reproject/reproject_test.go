@@ -0,0 +1,131 @@
+package reproject_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/cmp"
+	"github.com/hahaking119/geom/reproject"
+)
+
+func TestGeometry(t *testing.T) {
+	type tcase struct {
+		from, to uint
+		geom     geom.Geometry
+		expected geom.Geometry
+		err      error
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := reproject.Geometry(tc.from, tc.to, tc.geom)
+			if err != tc.err {
+				t.Fatalf("error, expected %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(tc.expected) {
+				t.Fatalf("type, expected %T, got %T", tc.expected, got)
+			}
+			if gs, ok := got.(geom.PointZS); ok {
+				es := tc.expected.(geom.PointZS)
+				if gs.Srid != es.Srid {
+					t.Errorf("srid, expected %v, got %v", es.Srid, gs.Srid)
+				}
+				if gs.Xyz[2] != es.Xyz[2] {
+					t.Errorf("z, expected %v, got %v", es.Xyz[2], gs.Xyz[2])
+				}
+				got, tc.expected = geom.Point(gs.Xyz.XY()), geom.Point(es.Xyz.XY())
+			}
+			if !cmp.GeometryEqual(tc.expected, got) {
+				t.Errorf("geometry, expected %v, got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"4326 to 3857": {
+			from:     4326,
+			to:       3857,
+			geom:     geom.Point{10, 50},
+			expected: geom.Point{1113194.9079327357, 6446275.841017158},
+		},
+		"3857 to 4326": {
+			from:     3857,
+			to:       4326,
+			geom:     geom.LineString{{0, 0}, {1113194.9079327357, 6446275.841017158}},
+			expected: geom.LineString{{0, 0}, {10, 50}},
+		},
+		"same": {
+			from:     3857,
+			to:       3857,
+			geom:     geom.Point{1, 2},
+			expected: geom.Point{1, 2},
+		},
+		"srid from geometry": {
+			from:     3857,
+			to:       3857,
+			geom:     geom.PointZS{Srid: 4326, Xyz: geom.PointZ{10, 50, 100}},
+			expected: geom.PointZS{Srid: 3857, Xyz: geom.PointZ{1113194.9079327357, 6446275.841017158, 100}},
+		},
+		"unsupported": {
+			from: 4326,
+			to:   2193,
+			geom: geom.Point{1, 2},
+			err:  reproject.ErrUnsupportedSRID,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestDensify(t *testing.T) {
+	type tcase struct {
+		geom     geom.Geometry
+		max      float64
+		expected geom.Geometry
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := reproject.Densify(tc.geom, tc.max)
+			if err != nil {
+				t.Fatalf("error, expected nil, got %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, got) {
+				t.Errorf("geometry, expected %v, got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"line": {
+			geom:     geom.LineString{{0, 0}, {4, 0}},
+			max:      1.5,
+			expected: geom.LineString{{0, 0}, {4.0 / 3, 0}, {8.0 / 3, 0}, {4, 0}},
+		},
+		"line z": {
+			geom:     geom.LineStringZ{{0, 0, 0}, {0, 2, 10}},
+			max:      1,
+			expected: geom.LineStringZ{{0, 0, 0}, {0, 1, 5}, {0, 2, 10}},
+		},
+		"polygon closing edge": {
+			geom:     geom.Polygon{{{0, 0}, {0, 1}, {2, 0}}},
+			max:      1.2,
+			expected: geom.Polygon{{{0, 0}, {0, 1}, {1, 0.5}, {2, 0}, {1, 0}}},
+		},
+		"point": {
+			geom:     geom.Point{1, 1},
+			max:      1,
+			expected: geom.Point{1, 1},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -0,0 +1,363 @@
+package slippy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hahaking119/geom"
+)
+
+// Cover returns the tiles at zoom z that the geometry touches. The geometry should be in the
+// grid's coordinate system. Points and lines cover the tiles they pass through, polygons also
+// cover the tiles of their interior, not including their holes. The tiles are ordered by
+// row then column.
+func Cover(g Grid, geo geom.Geometry, z uint) ([]Tile, error) {
+	return CoverRange(g, geo, z, z)
+}
+
+// CoverRange returns the tiles, for each of the zooms from minZoom to maxZoom (inclusive), that
+// the geometry touches. See Cover.
+func CoverRange(g Grid, geo geom.Geometry, minZoom, maxZoom uint) ([]Tile, error) {
+	var tiles []Tile
+	err := RangeCover(g, geo, minZoom, maxZoom, func(t *Tile) error {
+		tiles = append(tiles, *t)
+		return nil
+	})
+	return tiles, err
+}
+
+// RangeCover calls f on each tile, for each of the zooms from minZoom to maxZoom (inclusive),
+// that the geometry touches. The tiles are provided from the lowest zoom to the highest, and
+// within a zoom a row at a time. If f returns an error the iteration is stopped and the error
+// is returned. See Cover.
+//
+// Only the tiles of the geometry's boundary are held in memory, as spans of columns; the
+// interior of polygons is filled in as each row is provided.
+func RangeCover(g Grid, geo geom.Geometry, minZoom, maxZoom uint, f Iterator) error {
+	if minZoom > maxZoom {
+		return fmt.Errorf("min zoom %v is greater than max zoom %v", minZoom, maxZoom)
+	}
+
+	for z := minZoom; z <= maxZoom; z++ {
+		c, err := newCoverer(g, z)
+		if err != nil {
+			return err
+		}
+		if err = c.cover(geo); err != nil {
+			return err
+		}
+		if err = c.each(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// span is the columns from start to end (inclusive) of a row
+type span struct {
+	start, end int
+}
+
+// coverer collects the tiles of a zoom touched by geometries. The geometries
+// are converted to tile coordinates, where a tile is 1 unit wide and tall,
+// with the origin at the top left of the grid.
+type coverer struct {
+	z uint
+	// the number of columns and rows in the zoom
+	width, height int
+	// the top left of the grid and the size of a tile in native units
+	ox, oy, tw, th float64
+
+	// the spans of the tiles of points and lines, and of the boundaries of polygons, by row
+	rows map[int][]span
+	// the edges of the polygons, in tile coordinates, to fill in their interiors a row at a time
+	polygons [][][2][2]float64
+	// the range of rows with tiles
+	minRow, maxRow int
+}
+
+func newCoverer(g Grid, z uint) (*coverer, error) {
+	size, ok := g.Size(z)
+	if !ok {
+		return nil, fmt.Errorf("zoom %v not valid for grid", z)
+	}
+	origin, ok := g.ToNative(NewTile(z, 0, 0))
+	if !ok {
+		return nil, fmt.Errorf("zoom %v not valid for grid", z)
+	}
+	ext, ok := Extent(g, NewTile(z, 0, 0))
+	if !ok {
+		return nil, fmt.Errorf("zoom %v not valid for grid", z)
+	}
+	if ext.XSpan() == 0 || ext.YSpan() == 0 {
+		return nil, errors.New("grid has tiles with no area")
+	}
+
+	return &coverer{
+		z:      z,
+		width:  int(size.X),
+		height: int(size.Y),
+		ox:     origin.X(),
+		oy:     origin.Y(),
+		tw:     ext.XSpan(),
+		th:     ext.YSpan(),
+		rows:   make(map[int][]span),
+		minRow: int(size.Y),
+		maxRow: -1,
+	}, nil
+}
+
+// toTile returns the point in tile coordinates
+func (c *coverer) toTile(pt [2]float64) [2]float64 {
+	return [2]float64{
+		(pt[0] - c.ox) / c.tw,
+		(c.oy - pt[1]) / c.th,
+	}
+}
+
+// cell returns the column or row the value falls in; values on the far
+// edge of the grid belong to the last column or row.
+func cell(v float64, n int) int {
+	if v == float64(n) {
+		return n - 1
+	}
+	return int(math.Floor(v))
+}
+
+// addRows widens the range of rows with tiles
+func (c *coverer) addRows(minRow, maxRow int) {
+	if minRow < c.minRow {
+		c.minRow = minRow
+	}
+	if maxRow > c.maxRow {
+		c.maxRow = maxRow
+	}
+}
+
+// add records the tile, tiles outside of the grid are ignored. Lines are
+// walked a tile at a time, so a tile next to the last one of its row extends
+// that span.
+func (c *coverer) add(x, y int) {
+	if x < 0 || y < 0 || x >= c.width || y >= c.height {
+		return
+	}
+	c.addRows(y, y)
+	spans := c.rows[y]
+	if n := len(spans); n > 0 {
+		last := &spans[n-1]
+		if x >= last.start-1 && x <= last.end+1 {
+			if x < last.start {
+				last.start = x
+			}
+			if x > last.end {
+				last.end = x
+			}
+			return
+		}
+	}
+	c.rows[y] = append(spans, span{start: x, end: x})
+}
+
+// each calls f on the tiles a row at a time, each row ordered by column.
+// The rows are dropped once they have been provided.
+func (c *coverer) each(f Iterator) error {
+	var spans []span
+	for row := c.minRow; row <= c.maxRow; row++ {
+		spans = append(spans[:0], c.rows[row]...)
+		delete(c.rows, row)
+		for _, edges := range c.polygons {
+			spans = c.fill(spans, edges, row)
+		}
+		if len(spans) == 0 {
+			continue
+		}
+
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+		// the next column to provide, so overlapping spans are only provided once
+		next := 0
+		for _, s := range spans {
+			if s.start > next {
+				next = s.start
+			}
+			for ; next <= s.end; next++ {
+				t := Tile{Z: c.z, X: uint(next), Y: uint(row)}
+				if err := f(&t); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *coverer) cover(geo geom.Geometry) error {
+	switch gg := geo.(type) {
+	default:
+		return geom.ErrUnknownGeometry{Geom: geo}
+
+	case geom.Pointer:
+		c.point(gg.XY())
+
+	case geom.MultiPointer:
+		for _, pt := range gg.Points() {
+			c.point(pt)
+		}
+
+	case geom.LineStringer:
+		c.line(gg.Vertices(), false)
+
+	case geom.MultiLineStringer:
+		for _, ls := range gg.LineStrings() {
+			c.line(ls, false)
+		}
+
+	case geom.Polygoner:
+		c.polygon(gg.LinearRings())
+
+	case geom.MultiPolygoner:
+		for _, p := range gg.Polygons() {
+			c.polygon(p)
+		}
+
+	case geom.Collectioner:
+		for _, child := range gg.Geometries() {
+			if err := c.cover(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *coverer) point(pt [2]float64) {
+	t := c.toTile(pt)
+	c.add(cell(t[0], c.width), cell(t[1], c.height))
+}
+
+// line adds the tiles the line passes through; if closed the last
+// point is connected to the first.
+func (c *coverer) line(pts [][2]float64, closed bool) {
+	switch len(pts) {
+	case 0:
+		return
+	case 1:
+		c.point(pts[0])
+		return
+	}
+
+	for i := 1; i < len(pts); i++ {
+		c.segment(c.toTile(pts[i-1]), c.toTile(pts[i]))
+	}
+	if closed {
+		c.segment(c.toTile(pts[len(pts)-1]), c.toTile(pts[0]))
+	}
+}
+
+// segment walks the tiles between the two points, which are in tile coordinates.
+// See "A Fast Voxel Traversal Algorithm for Ray Tracing" by Amanatides and Woo.
+func (c *coverer) segment(a, b [2]float64) {
+	x, y := cell(a[0], c.width), cell(a[1], c.height)
+	ex, ey := cell(b[0], c.width), cell(b[1], c.height)
+	c.add(x, y)
+
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	stepX, stepY := 1, 1
+	tMaxX, tMaxY := math.Inf(1), math.Inf(1)
+	tDeltaX, tDeltaY := math.Inf(1), math.Inf(1)
+
+	switch {
+	case dx > 0:
+		tMaxX, tDeltaX = (float64(x+1)-a[0])/dx, 1/dx
+	case dx < 0:
+		stepX = -1
+		tMaxX, tDeltaX = (float64(x)-a[0])/dx, -1/dx
+	}
+	switch {
+	case dy > 0:
+		tMaxY, tDeltaY = (float64(y+1)-a[1])/dy, 1/dy
+	case dy < 0:
+		stepY = -1
+		tMaxY, tDeltaY = (float64(y)-a[1])/dy, -1/dy
+	}
+
+	// the number of tiles left to walk
+	for n := abs(ex-x) + abs(ey-y); n > 0; n-- {
+		if tMaxX < tMaxY {
+			tMaxX += tDeltaX
+			x += stepX
+		} else {
+			tMaxY += tDeltaY
+			y += stepY
+		}
+		c.add(x, y)
+	}
+}
+
+// polygon adds the tiles of the boundary of the rings and keeps the edges
+// so the interior can be filled in a row at a time, see fill.
+func (c *coverer) polygon(rings [][][2]float64) {
+	var edges [][2][2]float64
+	miny, maxy := math.Inf(1), math.Inf(-1)
+	for _, ring := range rings {
+		c.line(ring, true)
+		for i := range ring {
+			a, b := c.toTile(ring[i]), c.toTile(ring[(i+1)%len(ring)])
+			edges = append(edges, [2][2]float64{a, b})
+			miny, maxy = math.Min(miny, a[1]), math.Max(maxy, a[1])
+		}
+	}
+	if len(edges) < 3 {
+		return
+	}
+
+	minRow, maxRow := cell(miny, c.height), cell(maxy, c.height)
+	if minRow < 0 {
+		minRow = 0
+	}
+	if maxRow >= c.height {
+		maxRow = c.height - 1
+	}
+	if minRow > maxRow {
+		return
+	}
+	c.addRows(minRow, maxRow)
+	c.polygons = append(c.polygons, edges)
+}
+
+// fill appends the spans of the interior of the polygon in the row, found by
+// scanning the middle of the row.
+func (c *coverer) fill(spans []span, edges [][2][2]float64, row int) []span {
+	yc := float64(row) + 0.5
+	var xs []float64
+	for _, e := range edges {
+		a, b := e[0], e[1]
+		if (a[1] > yc) == (b[1] > yc) {
+			continue
+		}
+		xs = append(xs, a[0]+(yc-a[1])*(b[0]-a[0])/(b[1]-a[1]))
+	}
+	sort.Float64s(xs)
+	// using the even-odd rule, holes are skipped
+	for i := 0; i+1 < len(xs); i += 2 {
+		start, end := cell(xs[i], c.width), cell(xs[i+1], c.width)
+		if start < 0 {
+			start = 0
+		}
+		if end >= c.width {
+			end = c.width - 1
+		}
+		if start <= end {
+			spans = append(spans, span{start: start, end: end})
+		}
+	}
+	return spans
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
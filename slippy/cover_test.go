@@ -0,0 +1,224 @@
+package slippy_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/slippy"
+)
+
+func TestCover(t *testing.T) {
+	type tcase struct {
+		geom             geom.Geometry
+		minZoom, maxZoom uint
+		expected         []slippy.Tile
+		err              bool
+	}
+
+	grid, err := slippy.NewGrid(4326)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fn := func(tc tcase) func(t *testing.T) {
+		return func(t *testing.T) {
+			tiles, err := slippy.CoverRange(grid, tc.geom, tc.minZoom, tc.maxZoom)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("error, expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil, got %v", err)
+			}
+			if !reflect.DeepEqual(tiles, tc.expected) {
+				t.Errorf("tiles, expected %v, got %v", tc.expected, tiles)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			geom:     geom.Point{10, 10},
+			minZoom:  2,
+			maxZoom:  2,
+			expected: []slippy.Tile{{Z: 2, X: 4, Y: 1}},
+		},
+		"point range": {
+			geom:    geom.Point{10, 10},
+			minZoom: 0,
+			maxZoom: 2,
+			expected: []slippy.Tile{
+				{Z: 0, X: 1, Y: 0},
+				{Z: 1, X: 2, Y: 0},
+				{Z: 2, X: 4, Y: 1},
+			},
+		},
+		"point on grid edge": {
+			geom:     geom.Point{180, -90},
+			minZoom:  1,
+			maxZoom:  1,
+			expected: []slippy.Tile{{Z: 1, X: 3, Y: 1}},
+		},
+		"diagonal line": {
+			geom:    geom.LineString{{-170, 80}, {170, -70}},
+			minZoom: 1,
+			maxZoom: 1,
+			expected: []slippy.Tile{
+				{Z: 1, X: 0, Y: 0},
+				{Z: 1, X: 1, Y: 0},
+				{Z: 1, X: 2, Y: 0},
+				{Z: 1, X: 2, Y: 1},
+				{Z: 1, X: 3, Y: 1},
+			},
+		},
+		"l shaped polygon": {
+			geom: geom.Polygon{
+				{{-170, 80}, {170, 80}, {170, 50}, {-140, 50}, {-140, -80}, {-170, -80}},
+			},
+			minZoom: 2,
+			maxZoom: 2,
+			expected: []slippy.Tile{
+				{Z: 2, X: 0, Y: 0},
+				{Z: 2, X: 1, Y: 0},
+				{Z: 2, X: 2, Y: 0},
+				{Z: 2, X: 3, Y: 0},
+				{Z: 2, X: 4, Y: 0},
+				{Z: 2, X: 5, Y: 0},
+				{Z: 2, X: 6, Y: 0},
+				{Z: 2, X: 7, Y: 0},
+				{Z: 2, X: 0, Y: 1},
+				{Z: 2, X: 0, Y: 2},
+				{Z: 2, X: 0, Y: 3},
+			},
+		},
+		"polygon with hole": {
+			geom: geom.Polygon{
+				{{-170, 80}, {-10, 80}, {-10, -80}, {-170, -80}},
+				{{-140, 50}, {-140, -50}, {-40, -50}, {-40, 50}},
+			},
+			minZoom: 2,
+			maxZoom: 2,
+			expected: []slippy.Tile{
+				{Z: 2, X: 0, Y: 0},
+				{Z: 2, X: 1, Y: 0},
+				{Z: 2, X: 2, Y: 0},
+				{Z: 2, X: 3, Y: 0},
+				{Z: 2, X: 0, Y: 1},
+				{Z: 2, X: 3, Y: 1},
+				{Z: 2, X: 0, Y: 2},
+				{Z: 2, X: 3, Y: 2},
+				{Z: 2, X: 0, Y: 3},
+				{Z: 2, X: 1, Y: 3},
+				{Z: 2, X: 2, Y: 3},
+				{Z: 2, X: 3, Y: 3},
+			},
+		},
+		"collection": {
+			geom: geom.Collection{
+				geom.MultiPoint{{10, 10}, {-100, -60}},
+				geom.MultiLineString{{{100, 80}, {120, 80}}},
+			},
+			minZoom: 2,
+			maxZoom: 2,
+			expected: []slippy.Tile{
+				{Z: 2, X: 6, Y: 0},
+				{Z: 2, X: 4, Y: 1},
+				{Z: 2, X: 1, Y: 3},
+			},
+		},
+		"overlapping parts": {
+			geom: geom.Collection{
+				geom.Polygon{{{-170, 80}, {-10, 80}, {-10, 10}, {-170, 10}}},
+				geom.LineString{{-100, 60}, {100, 60}},
+				geom.Point{-60, 30},
+			},
+			minZoom: 2,
+			maxZoom: 2,
+			expected: []slippy.Tile{
+				{Z: 2, X: 0, Y: 0},
+				{Z: 2, X: 1, Y: 0},
+				{Z: 2, X: 2, Y: 0},
+				{Z: 2, X: 3, Y: 0},
+				{Z: 2, X: 4, Y: 0},
+				{Z: 2, X: 5, Y: 0},
+				{Z: 2, X: 6, Y: 0},
+				{Z: 2, X: 0, Y: 1},
+				{Z: 2, X: 1, Y: 1},
+				{Z: 2, X: 2, Y: 1},
+				{Z: 2, X: 3, Y: 1},
+			},
+		},
+		"bad zoom range": {
+			geom:    geom.Point{10, 10},
+			minZoom: 3,
+			maxZoom: 2,
+			err:     true,
+		},
+		"unknown geometry": {
+			geom:    struct{}{},
+			minZoom: 2,
+			maxZoom: 2,
+			err:     true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestRangeCover(t *testing.T) {
+	grid, err := slippy.NewGrid(3857)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ls := geom.LineString{{-slippy.WebMercatorMax + 1, 1}, {slippy.WebMercatorMax - 1, 1}}
+	tiles, err := slippy.Cover(grid, ls, 4)
+	if err != nil {
+		t.Fatalf("error, expected nil, got %v", err)
+	}
+	if len(tiles) != 16 {
+		t.Errorf("tiles, expected 16 got %v", len(tiles))
+	}
+
+	errStop := errors.New("stop")
+	var count int
+	err = slippy.RangeCover(grid, ls, 0, 4, func(*slippy.Tile) error {
+		count++
+		if count == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Errorf("error, expected %v, got %v", errStop, err)
+	}
+	if count != 3 {
+		t.Errorf("count, expected 3, got %v", count)
+	}
+
+	// the tiles are provided as they are found, so stopping at the first tile of
+	// a world covering polygon at zoom 20 doesn't wait for the rest of the zoom.
+	world := geom.Polygon{{
+		{-slippy.WebMercatorMax, slippy.WebMercatorMax},
+		{slippy.WebMercatorMax, slippy.WebMercatorMax},
+		{slippy.WebMercatorMax, -slippy.WebMercatorMax},
+		{-slippy.WebMercatorMax, -slippy.WebMercatorMax},
+	}}
+	var first *slippy.Tile
+	err = slippy.RangeCover(grid, world, 20, 20, func(t *slippy.Tile) error {
+		first = t
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("error, expected %v, got %v", errStop, err)
+	}
+	if first == nil || *first != *slippy.NewTile(20, 0, 0) {
+		t.Errorf("first tile, expected %v, got %v", slippy.NewTile(20, 0, 0), first)
+	}
+}
@@ -0,0 +1,53 @@
+package slippy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Quadkey returns the Bing Maps quadkey of the tile. Quadkeys are only
+// meaningful for the quad tree of the Web Mercator (3857) grid.
+//
+// Reference: https://docs.microsoft.com/en-us/bingmaps/articles/bing-maps-tile-system
+func (t Tile) Quadkey() string {
+	var qk strings.Builder
+	qk.Grow(int(t.Z))
+	for i := t.Z; i > 0; i-- {
+		digit := byte('0')
+		mask := uint(1) << (i - 1)
+		if t.X&mask != 0 {
+			digit++
+		}
+		if t.Y&mask != 0 {
+			digit += 2
+		}
+		qk.WriteByte(digit)
+	}
+	return qk.String()
+}
+
+// NewTileFromQuadkey returns the tile for the Bing Maps quadkey. The empty
+// quadkey is the zoom 0 tile.
+func NewTileFromQuadkey(quadkey string) (*Tile, error) {
+	if len(quadkey) > MaxZoom {
+		return nil, fmt.Errorf("invalid quadkey %q, zoom larger than %v", quadkey, MaxZoom)
+	}
+
+	tile := NewTile(uint(len(quadkey)), 0, 0)
+	for i := range quadkey {
+		mask := uint(1) << uint(len(quadkey)-i-1)
+		switch quadkey[i] {
+		case '0':
+		case '1':
+			tile.X |= mask
+		case '2':
+			tile.Y |= mask
+		case '3':
+			tile.X |= mask
+			tile.Y |= mask
+		default:
+			return nil, fmt.Errorf("invalid quadkey %q, unexpected digit %q", quadkey, quadkey[i])
+		}
+	}
+	return tile, nil
+}
@@ -0,0 +1,83 @@
+package slippy
+
+// Common tile sizes, in pixels
+const (
+	TileSize256 = 256
+	TileSize512 = 512
+)
+
+// StandardDPI is the dots per inch of the OGC standard pixel size (0.28mm)
+const StandardDPI = 0.0254 / StandardPixelSize
+
+// Resolution returns the size of a pixel, in the grid's native units, of a tile at
+// the zoom that is tileSize pixels wide. ok will be false if the zoom is not valid for
+// the grid or the tile size is 0.
+func Resolution(g Grid, zoom uint, tileSize float64) (res float64, ok bool) {
+	if tileSize <= 0 {
+		return 0, false
+	}
+	ext, ok := Extent(g, NewTile(zoom, 0, 0))
+	if !ok {
+		return 0, false
+	}
+	return ext.XSpan() / tileSize, true
+}
+
+// GroundResolution returns the size of a pixel in meters, of a tile at the zoom that is tileSize
+// pixels wide. For geographic grids (EPSG:4326) this is the size at the equator. For EPSG:3857
+// it is the size at the equator, the size at a latitude is smaller by cos(latitude).
+func GroundResolution(g Grid, zoom uint, tileSize float64) (float64, bool) {
+	res, ok := Resolution(g, zoom, tileSize)
+	if !ok {
+		return 0, false
+	}
+	return res * metersPerUnit(g.SRID()), true
+}
+
+// ScaleDenominator returns the scale denominator of a tile at the zoom that is tileSize pixels wide,
+// when displayed at the given dpi; use StandardDPI for the OGC scale denominator.
+func ScaleDenominator(g Grid, zoom uint, tileSize, dpi float64) (float64, bool) {
+	if dpi <= 0 {
+		return 0, false
+	}
+	res, ok := GroundResolution(g, zoom, tileSize)
+	if !ok {
+		return 0, false
+	}
+	return res * dpi / 0.0254, true
+}
+
+// ZoomForResolution returns the best zoom for displaying data at the given resolution, in the
+// grid's native units per pixel, using tiles of tileSize pixels. This is the lowest zoom with a
+// resolution as fine as res; if no zoom is fine enough the highest zoom of the grid is
+// returned. ok will be false if res or tileSize are not greater than 0.
+func ZoomForResolution(g Grid, res, tileSize float64) (zoom uint, ok bool) {
+	if res <= 0 || tileSize <= 0 {
+		return 0, false
+	}
+	// allow for floating point noise in resolutions that are powers of two apart
+	const tolerance = 1e-9
+
+	for z := uint(0); ; z++ {
+		zres, ok := Resolution(g, z, tileSize)
+		if !ok {
+			if z == 0 {
+				return 0, false
+			}
+			return z - 1, true
+		}
+		if zres <= res*(1+tolerance) {
+			return z, true
+		}
+	}
+}
+
+// ZoomForScaleDenominator returns the best zoom for displaying data at the scale denominator,
+// using tiles of tileSize pixels displayed at dpi. See ZoomForResolution.
+func ZoomForScaleDenominator(g Grid, scale, tileSize, dpi float64) (uint, bool) {
+	if scale <= 0 || dpi <= 0 {
+		return 0, false
+	}
+	res := scale * 0.0254 / dpi / metersPerUnit(g.SRID())
+	return ZoomForResolution(g, res, tileSize)
+}
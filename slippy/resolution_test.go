@@ -0,0 +1,149 @@
+package slippy_test
+
+import (
+	"testing"
+
+	"github.com/hahaking119/geom/cmp"
+	"github.com/hahaking119/geom/slippy"
+)
+
+func TestResolution(t *testing.T) {
+	type tcase struct {
+		srid     uint
+		zoom     uint
+		tileSize float64
+		dpi      float64
+		res      float64
+		ground   float64
+		scale    float64
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			grid, err := slippy.NewGrid(tc.srid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cmpr := cmp.NewForNumPrecision(4)
+
+			res, ok := slippy.Resolution(grid, tc.zoom, tc.tileSize)
+			if !ok {
+				t.Fatal("resolution, expected ok")
+			}
+			if !cmpr.Float(res, tc.res) {
+				t.Errorf("resolution, expected %v got %v", tc.res, res)
+			}
+			ground, _ := slippy.GroundResolution(grid, tc.zoom, tc.tileSize)
+			if !cmpr.Float(ground, tc.ground) {
+				t.Errorf("ground resolution, expected %v got %v", tc.ground, ground)
+			}
+			scale, _ := slippy.ScaleDenominator(grid, tc.zoom, tc.tileSize, tc.dpi)
+			if !cmpr.Float(scale, tc.scale) {
+				t.Errorf("scale denominator, expected %v got %v", tc.scale, scale)
+			}
+
+			zoom, ok := slippy.ZoomForResolution(grid, res, tc.tileSize)
+			if !ok || zoom != tc.zoom {
+				t.Errorf("zoom for resolution, expected %v got %v", tc.zoom, zoom)
+			}
+			zoom, ok = slippy.ZoomForScaleDenominator(grid, scale, tc.tileSize, tc.dpi)
+			if !ok || zoom != tc.zoom {
+				t.Errorf("zoom for scale denominator, expected %v got %v", tc.zoom, zoom)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"3857 256 zoom 0": {
+			srid:     3857,
+			zoom:     0,
+			tileSize: slippy.TileSize256,
+			dpi:      slippy.StandardDPI,
+			res:      156543.0339,
+			ground:   156543.0339,
+			scale:    559082264.0287,
+		},
+		"3857 512 zoom 1": {
+			srid:     3857,
+			zoom:     1,
+			tileSize: slippy.TileSize512,
+			dpi:      slippy.StandardDPI,
+			res:      39135.7585,
+			ground:   39135.7585,
+			scale:    139770566.0072,
+		},
+		"3857 256 zoom 5 96dpi": {
+			srid:     3857,
+			zoom:     5,
+			tileSize: slippy.TileSize256,
+			dpi:      96,
+			res:      4891.9698,
+			ground:   4891.9698,
+			scale:    18489297.7375,
+		},
+		"4326 256 zoom 1": {
+			srid:     4326,
+			zoom:     1,
+			tileSize: slippy.TileSize256,
+			dpi:      slippy.StandardDPI,
+			res:      0.3515625,
+			ground:   39135.7585,
+			scale:    139770566.0072,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestZoomForResolution(t *testing.T) {
+	grid, _ := slippy.NewGrid(3857)
+
+	// between zoom 4 (9783.94m) and zoom 5 (4891.97m)
+	if zoom, _ := slippy.ZoomForResolution(grid, 5000, slippy.TileSize256); zoom != 5 {
+		t.Errorf("zoom, expected 5 got %v", zoom)
+	}
+	// finer than the max zoom
+	if zoom, _ := slippy.ZoomForResolution(grid, 0.001, slippy.TileSize256); zoom != slippy.MaxZoom {
+		t.Errorf("zoom, expected %v got %v", slippy.MaxZoom, zoom)
+	}
+	if _, ok := slippy.ZoomForResolution(grid, 0, slippy.TileSize256); ok {
+		t.Errorf("ok, expected false for a resolution of 0")
+	}
+}
+
+func TestPixelsToNative(t *testing.T) {
+	type tcase struct {
+		zoom     uint
+		pixels   uint
+		expected float64
+	}
+
+	grid, err := slippy.NewGrid(3857)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			native := slippy.PixelsToNative(grid, tc.zoom, tc.pixels)
+			if !cmp.Float(native, tc.expected) {
+				t.Errorf("native, expected %v got %v", tc.expected, native)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"no pixels": {zoom: 0, pixels: 0, expected: 0},
+		"one pixel": {zoom: 0, pixels: 1, expected: 2 * slippy.WebMercatorMax / slippy.MvtTileDim},
+		// scales by the number of pixels
+		"64 pixels":        {zoom: 0, pixels: 64, expected: 64 * 2 * slippy.WebMercatorMax / slippy.MvtTileDim},
+		"64 pixels zoom 3": {zoom: 3, pixels: 64, expected: 64 * 2 * slippy.WebMercatorMax / slippy.MvtTileDim / 8},
+		"4096 pixels":      {zoom: 1, pixels: 4096, expected: slippy.WebMercatorMax},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
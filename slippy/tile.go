@@ -3,6 +3,8 @@ package slippy
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/hahaking119/geom"
 )
@@ -98,10 +100,158 @@ func FromBounds(g Grid, bounds *geom.Extent, z uint) []Tile {
 // ZXY returns back the z,x,y of the tile
 func (t Tile) ZXY() (uint, uint, uint) { return t.Z, t.X, t.Y }
 
+// String returns the tile as z/x/y
+func (t Tile) String() string { return fmt.Sprintf("%v/%v/%v", t.Z, t.X, t.Y) }
+
+// ParseTile parses a tile in the z/x/y form, as used in tile urls. A leading slash
+// and a file extension on the y, such as 3/4/2.pbf, are ignored. Use IsValid to
+// check the tile is in a grid.
+func ParseTile(s string) (*Tile, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "/"), "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid tile %q, expected z/x/y", s)
+	}
+	if i := strings.IndexByte(parts[2], '.'); i != -1 {
+		parts[2] = parts[2][:i]
+	}
+
+	var zxy [3]uint
+	for i := range parts {
+		v, err := strconv.ParseUint(parts[i], 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tile %q: %v", s, err)
+		}
+		zxy[i] = uint(v)
+	}
+	if zxy[0] > MaxZoom {
+		return nil, fmt.Errorf("invalid tile %q, zoom larger than %v", s, MaxZoom)
+	}
+	return NewTile(zxy[0], zxy[1], zxy[2]), nil
+}
+
+// IsValid reports whether the tile is in the grid.
+func (t Tile) IsValid(g Grid) bool {
+	size, ok := g.Size(t.Z)
+	return ok && t.X < size.X && t.Y < size.Y
+}
+
+// Parent returns the tile at the previous zoom that contains t. ok is false
+// for tiles at zoom 0 or that are not in the grid.
+func (t Tile) Parent(g Grid) (parent *Tile, ok bool) {
+	if t.Z == 0 || !t.IsValid(g) {
+		return nil, false
+	}
+	// the parent is the tile that contains the center of t
+	ext, ok := Extent(g, &t)
+	if !ok {
+		return nil, false
+	}
+	return g.FromNative(t.Z-1, geom.Point{
+		ext.MinX() + ext.XSpan()/2,
+		ext.MinY() + ext.YSpan()/2,
+	})
+}
+
+// Children returns the tiles at the next zoom that are contained by t.
+func (t Tile) Children(g Grid) ([]Tile, bool) {
+	if !t.IsValid(g) {
+		return nil, false
+	}
+	if _, ok := g.Size(t.Z + 1); !ok {
+		return nil, false
+	}
+
+	var children []Tile
+	err := RangeFamilyAt(g, &t, t.Z+1, func(c *Tile) error {
+		children = append(children, *c)
+		return nil
+	})
+	return children, err == nil
+}
+
+// Siblings returns the other tiles that have the same parent as t. At zoom 0
+// these are the other tiles at zoom 0.
+func (t Tile) Siblings(g Grid) ([]Tile, bool) {
+	if !t.IsValid(g) {
+		return nil, false
+	}
+
+	var tiles []Tile
+	if t.Z == 0 {
+		size, _ := g.Size(0)
+		for x := uint(0); x < size.X; x++ {
+			for y := uint(0); y < size.Y; y++ {
+				tiles = append(tiles, Tile{0, x, y})
+			}
+		}
+	} else {
+		parent, ok := t.Parent(g)
+		if !ok {
+			return nil, false
+		}
+		if tiles, ok = parent.Children(g); !ok {
+			return nil, false
+		}
+	}
+
+	siblings := tiles[:0]
+	for i := range tiles {
+		if tiles[i] != t {
+			siblings = append(siblings, tiles[i])
+		}
+	}
+	return siblings, true
+}
+
+// Neighbors returns the tiles, at the same zoom, that touch t; starting at the
+// top left going across and then down. When wrap is true tiles past the
+// left and right edges of the grid wrap around to the other side, as they do
+// across the antimeridian. Tiles never wrap across the top and bottom.
+func (t Tile) Neighbors(g Grid, wrap bool) ([]Tile, bool) {
+	if !t.IsValid(g) {
+		return nil, false
+	}
+	size, _ := g.Size(t.Z)
+
+	var neighbors []Tile
+	seen := map[Tile]bool{t: true}
+	for dy := -1; dy <= 1; dy++ {
+		y := int(t.Y) + dy
+		if y < 0 || y >= int(size.Y) {
+			continue
+		}
+		for dx := -1; dx <= 1; dx++ {
+			x := int(t.X) + dx
+			if wrap {
+				x = (x + int(size.X)) % int(size.X)
+			}
+			if x < 0 || x >= int(size.X) {
+				continue
+			}
+			n := Tile{t.Z, uint(x), uint(y)}
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			neighbors = append(neighbors, n)
+		}
+	}
+	return neighbors, true
+}
+
+// FlipY returns the tile with the y counted from the bottom of the grid instead of
+// the top; this converts between the XYZ and TMS tile schemes, in both directions.
+func (t Tile) FlipY(g Grid) (*Tile, bool) {
+	if !t.IsValid(g) {
+		return nil, false
+	}
+	size, _ := g.Size(t.Z)
+	return NewTile(t.Z, t.X, size.Y-1-t.Y), true
+}
+
 type Iterator func(*Tile) error
 
 // RangeFamilyAt calls f on every tile vertically related to t at the specified zoom
-// TODO (ear7h): sibling support
 func RangeFamilyAt(g Grid, t *Tile, zoom uint, f Iterator) error {
 	tl, ok := g.ToNative(t)
 	if !ok {
@@ -113,18 +263,30 @@ func RangeFamilyAt(g Grid, t *Tile, zoom uint, f Iterator) error {
 		return fmt.Errorf("tile %v not valid for grid", t)
 	}
 
-	tlt, ok := g.FromNative(zoom, tl)
+	// Move the corners half a tile, of the larger of the two zooms, into the
+	// tile so they don't land on the tiles next to t.
+	z := zoom
+	if t.Z > z {
+		z = t.Z
+	}
+	ext, ok := Extent(g, NewTile(z, 0, 0))
+	if !ok {
+		return fmt.Errorf("zoom %v not valid for grid", zoom)
+	}
+	dx, dy := ext.XSpan()/2, ext.YSpan()/2
+
+	tlt, ok := g.FromNative(zoom, geom.Point{tl.X() + dx, tl.Y() - dy})
 	if !ok {
 		return fmt.Errorf("tile %v not valid for grid", t)
 	}
 
-	brt, ok := g.FromNative(zoom, br)
+	brt, ok := g.FromNative(zoom, geom.Point{br.X() - dx, br.Y() + dy})
 	if !ok {
 		return fmt.Errorf("tile %v not valid for grid", t)
 	}
 
-	for x := tlt.X; x < brt.X; x++ {
-		for y := tlt.Y; y < brt.Y; y++ {
+	for x := tlt.X; x <= brt.X; x++ {
+		for y := tlt.Y; y <= brt.Y; y++ {
 			err := f(NewTile(zoom, x, y))
 			if err != nil {
 				return err
@@ -0,0 +1,272 @@
+package slippy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hahaking119/geom"
+)
+
+// StandardPixelSize is the size, in meters, of a pixel as defined by OGC
+// for use in scale denominators (0.28mm)
+const StandardPixelSize = 0.00028
+
+// metersPerDegree is the number of meters in a degree at the equator of the WGS 84 ellipsoid.
+const metersPerDegree = 2 * math.Pi * 6378137 / 360
+
+// TileMatrix describes the tiles of a single zoom of a TileMatrixSet
+type TileMatrix struct {
+	// ID is the identifier of the tile matrix, usually the zoom
+	ID string
+	// CellSize is the size of a pixel in the units of the tile matrix set's coordinate system
+	CellSize float64
+	// Origin is the top left corner of the tile matrix
+	Origin geom.Point
+	// TileWidth and TileHeight are the size of a tile in pixels
+	TileWidth, TileHeight uint
+	// MatrixWidth and MatrixHeight are the number of columns and rows of tiles
+	MatrixWidth, MatrixHeight uint
+}
+
+// tileSpan returns the width and height of a tile in native units
+func (tm TileMatrix) tileSpan() (float64, float64) {
+	return tm.CellSize * float64(tm.TileWidth), tm.CellSize * float64(tm.TileHeight)
+}
+
+// TileMatrixSet is a Grid described by a set of tile matrices, one for each zoom.
+// The zoom of a tile is the index of its tile matrix.
+type TileMatrixSet struct {
+	// ID is the identifier of the tile matrix set
+	ID string
+	// Srid is the SRID of the coordinate system of the tile matrices
+	Srid uint
+	// Matrices are the tile matrices ordered from the zoomed out (largest cell size) to the zoomed in
+	Matrices []TileMatrix
+}
+
+// NewTileMatrixSet returns a TileMatrixSet with a tile matrix for each of the resolutions, the size of a
+// pixel in native units, given. The tile matrices have square tiles of tileSize pixels starting at the given
+// top left origin and are large enough to cover the extent.
+func NewTileMatrixSet(srid uint, origin geom.Point, extent *geom.Extent, tileSize uint, resolutions []float64) (*TileMatrixSet, error) {
+	if extent == nil {
+		return nil, errors.New("extent is required")
+	}
+	if tileSize == 0 {
+		return nil, errors.New("tile size must be greater than 0")
+	}
+
+	tms := &TileMatrixSet{
+		Srid:     srid,
+		Matrices: make([]TileMatrix, len(resolutions)),
+	}
+	for i, res := range resolutions {
+		if res <= 0 {
+			return nil, fmt.Errorf("resolution %v for zoom %v must be greater than 0", res, i)
+		}
+		span := res * float64(tileSize)
+		tms.Matrices[i] = TileMatrix{
+			ID:           strconv.Itoa(i),
+			CellSize:     res,
+			Origin:       origin,
+			TileWidth:    tileSize,
+			TileHeight:   tileSize,
+			MatrixWidth:  uint(math.Ceil((extent.MaxX() - origin.X()) / span)),
+			MatrixHeight: uint(math.Ceil((origin.Y() - extent.MinY()) / span)),
+		}
+	}
+	return tms, nil
+}
+
+// SRID returns the SRID of the tile matrix set
+func (tms *TileMatrixSet) SRID() uint { return tms.Srid }
+
+// Size returns the number of columns and rows of tiles at the zoom
+func (tms *TileMatrixSet) Size(z uint) (*Tile, bool) {
+	if int(z) >= len(tms.Matrices) {
+		return nil, false
+	}
+	tm := tms.Matrices[z]
+	return NewTile(z, tm.MatrixWidth, tm.MatrixHeight), true
+}
+
+// FromNative returns the tile at zoom z that contains the point. Points on the right or bottom
+// edges of the matrix belong to the last column or row.
+func (tms *TileMatrixSet) FromNative(z uint, pt geom.Point) (*Tile, bool) {
+	if int(z) >= len(tms.Matrices) {
+		return nil, false
+	}
+	tm := tms.Matrices[z]
+	w, h := tm.tileSpan()
+
+	x := (pt.X() - tm.Origin.X()) / w
+	y := (tm.Origin.Y() - pt.Y()) / h
+	if x == float64(tm.MatrixWidth) {
+		x--
+	}
+	if y == float64(tm.MatrixHeight) {
+		y--
+	}
+	x, y = math.Floor(x), math.Floor(y)
+	if x < 0 || y < 0 || x >= float64(tm.MatrixWidth) || y >= float64(tm.MatrixHeight) {
+		return nil, false
+	}
+	return NewTile(z, uint(x), uint(y)), true
+}
+
+// ToNative returns the top left point of the tile. The x and y of the
+// tile may be one more than the max, to get the bottom right corner of the
+// matrix.
+func (tms *TileMatrixSet) ToNative(tile *Tile) (geom.Point, bool) {
+	if tile == nil || int(tile.Z) >= len(tms.Matrices) {
+		return geom.Point{}, false
+	}
+	tm := tms.Matrices[tile.Z]
+	if tile.X > tm.MatrixWidth || tile.Y > tm.MatrixHeight {
+		return geom.Point{}, false
+	}
+	w, h := tm.tileSpan()
+	return geom.Point{
+		tm.Origin.X() + float64(tile.X)*w,
+		tm.Origin.Y() - float64(tile.Y)*h,
+	}, true
+}
+
+// tmsJSON is the json encoding of a 2D TileMatrixSet; it accepts both
+// the OGC TileMatrixSet 1.0 and 2.0 names.
+type tmsJSON struct {
+	ID           string          `json:"id"`
+	Identifier   string          `json:"identifier"`
+	CRS          json.RawMessage `json:"crs"`
+	SupportedCRS string          `json:"supportedCRS"`
+	OrderedAxes  []string        `json:"orderedAxes"`
+
+	TileMatrices []tmJSON `json:"tileMatrices"`
+	TileMatrix   []tmJSON `json:"tileMatrix"`
+}
+
+type tmJSON struct {
+	ID               string      `json:"id"`
+	Identifier       string      `json:"identifier"`
+	ScaleDenominator float64     `json:"scaleDenominator"`
+	CellSize         float64     `json:"cellSize"`
+	CornerOfOrigin   string      `json:"cornerOfOrigin"`
+	PointOfOrigin    [2]float64  `json:"pointOfOrigin"`
+	TopLeftCorner    *[2]float64 `json:"topLeftCorner"`
+	TileWidth        uint        `json:"tileWidth"`
+	TileHeight       uint        `json:"tileHeight"`
+	MatrixWidth      uint        `json:"matrixWidth"`
+	MatrixHeight     uint        `json:"matrixHeight"`
+}
+
+// sridFromCRS returns the EPSG code from a crs URI or URN. CRS84 is
+// returned as 4326. The returned bool is true if the axis order of the CRS is
+// lat, lng.
+func sridFromCRS(crs string) (uint, bool, error) {
+	upper := strings.ToUpper(crs)
+	if strings.HasSuffix(upper, "CRS84") {
+		return 4326, false, nil
+	}
+	if !strings.Contains(upper, "EPSG") {
+		return 0, false, fmt.Errorf("unsupported crs: %v", crs)
+	}
+	i := strings.LastIndexAny(crs, "/:")
+	srid, err := strconv.ParseUint(crs[i+1:], 10, 32)
+	if err != nil {
+		return 0, false, fmt.Errorf("unsupported crs: %v", crs)
+	}
+	return uint(srid), srid == 4326, nil
+}
+
+// metersPerUnit returns the meters per unit of the coordinate system
+func metersPerUnit(srid uint) float64 {
+	if srid == 4326 {
+		return metersPerDegree
+	}
+	return 1
+}
+
+// ParseTileMatrixSet reads an OGC 2D TileMatrixSet JSON document and returns the TileMatrixSet
+// it describes. Both the 1.0 and 2.0 versions of the encoding are supported. Only tile matrices
+// with their origin in the top left corner are supported.
+func ParseTileMatrixSet(r io.Reader) (*TileMatrixSet, error) {
+	var doc tmsJSON
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	crs := doc.SupportedCRS
+	if len(doc.CRS) != 0 {
+		// the crs is either a uri or an object with a uri
+		var obj struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(doc.CRS, &crs); err != nil {
+			if err := json.Unmarshal(doc.CRS, &obj); err != nil {
+				return nil, fmt.Errorf("invalid crs: %v", err)
+			}
+			crs = obj.URI
+		}
+	}
+	srid, latFirst, err := sridFromCRS(crs)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.OrderedAxes) > 0 {
+		axis := strings.ToUpper(doc.OrderedAxes[0])
+		latFirst = axis == "LAT" || axis == "N"
+	}
+
+	matrices := doc.TileMatrices
+	if len(matrices) == 0 {
+		matrices = doc.TileMatrix
+	}
+
+	tms := &TileMatrixSet{
+		ID:       doc.ID,
+		Srid:     srid,
+		Matrices: make([]TileMatrix, len(matrices)),
+	}
+	if tms.ID == "" {
+		tms.ID = doc.Identifier
+	}
+
+	for i, m := range matrices {
+		if m.CornerOfOrigin != "" && !strings.EqualFold(m.CornerOfOrigin, "topLeft") {
+			return nil, fmt.Errorf("tile matrix %v: unsupported corner of origin %v", i, m.CornerOfOrigin)
+		}
+		origin := m.PointOfOrigin
+		if m.TopLeftCorner != nil {
+			origin = *m.TopLeftCorner
+		}
+		if latFirst {
+			origin[0], origin[1] = origin[1], origin[0]
+		}
+		cellSize := m.CellSize
+		if cellSize == 0 {
+			cellSize = m.ScaleDenominator * StandardPixelSize / metersPerUnit(srid)
+		}
+		if cellSize <= 0 || m.TileWidth == 0 || m.TileHeight == 0 {
+			return nil, fmt.Errorf("tile matrix %v: invalid cell size or tile size", i)
+		}
+		id := m.ID
+		if id == "" {
+			id = m.Identifier
+		}
+		tms.Matrices[i] = TileMatrix{
+			ID:           id,
+			CellSize:     cellSize,
+			Origin:       geom.Point(origin),
+			TileWidth:    m.TileWidth,
+			TileHeight:   m.TileHeight,
+			MatrixWidth:  m.MatrixWidth,
+			MatrixHeight: m.MatrixHeight,
+		}
+	}
+
+	return tms, nil
+}
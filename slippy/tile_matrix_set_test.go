@@ -0,0 +1,203 @@
+package slippy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/cmp"
+)
+
+const webMercatorQuadJSON = `{
+  "id": "WebMercatorQuad",
+  "title": "Google Maps Compatible for the World",
+  "uri": "http://www.opengis.net/def/tilematrixset/OGC/1.0/WebMercatorQuad",
+  "crs": "http://www.opengis.net/def/crs/EPSG/0/3857",
+  "orderedAxes": ["X", "Y"],
+  "tileMatrices": [
+    {
+      "id": "0",
+      "scaleDenominator": 559082264.028717,
+      "cellSize": 156543.033928041,
+      "cornerOfOrigin": "topLeft",
+      "pointOfOrigin": [-20037508.3427892, 20037508.3427892],
+      "matrixWidth": 1, "matrixHeight": 1,
+      "tileWidth": 256, "tileHeight": 256
+    },
+    {
+      "id": "1",
+      "scaleDenominator": 279541132.014358,
+      "cellSize": 78271.5169640204,
+      "cornerOfOrigin": "topLeft",
+      "pointOfOrigin": [-20037508.3427892, 20037508.3427892],
+      "matrixWidth": 2, "matrixHeight": 2,
+      "tileWidth": 256, "tileHeight": 256
+    },
+    {
+      "id": "2",
+      "scaleDenominator": 139770566.007179,
+      "cellSize": 39135.7584820102,
+      "cornerOfOrigin": "topLeft",
+      "pointOfOrigin": [-20037508.3427892, 20037508.3427892],
+      "matrixWidth": 4, "matrixHeight": 4,
+      "tileWidth": 256, "tileHeight": 256
+    }
+  ]
+}`
+
+// 1.0 encoding, with the lat/lng axis order of EPSG:4326 and no cell size
+const worldCRS84QuadJSON = `{
+  "type": "TileMatrixSetType",
+  "identifier": "WorldCRS84Quad",
+  "supportedCRS": "http://www.opengis.net/def/crs/EPSG/0/4326",
+  "tileMatrix": [
+    {
+      "type": "TileMatrixType",
+      "identifier": "0",
+      "scaleDenominator": 279541132.0143588675418869,
+      "topLeftCorner": [90, -180],
+      "tileWidth": 256, "tileHeight": 256,
+      "matrixWidth": 2, "matrixHeight": 1
+    },
+    {
+      "type": "TileMatrixType",
+      "identifier": "1",
+      "scaleDenominator": 139770566.0071794337709434,
+      "topLeftCorner": [90, -180],
+      "tileWidth": 256, "tileHeight": 256,
+      "matrixWidth": 4, "matrixHeight": 2
+    }
+  ]
+}`
+
+func TestParseTileMatrixSet(t *testing.T) {
+	type tcase struct {
+		doc     string
+		id      string
+		srid    uint
+		tile    Tile
+		extent  geom.Extent
+		size    Tile
+		wantErr bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			tms, err := ParseTileMatrixSet(strings.NewReader(tc.doc))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("error, expected error got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil got %v", err)
+			}
+			if tms.ID != tc.id {
+				t.Errorf("id, expected %v got %v", tc.id, tms.ID)
+			}
+			if tms.SRID() != tc.srid {
+				t.Errorf("srid, expected %v got %v", tc.srid, tms.SRID())
+			}
+			size, ok := tms.Size(tc.tile.Z)
+			if !ok {
+				t.Fatal("size, expected ok")
+			}
+			if *size != tc.size {
+				t.Errorf("size, expected %v got %v", tc.size, *size)
+			}
+			ext, ok := Extent(tms, &tc.tile)
+			if !ok {
+				t.Fatal("extent, expected ok")
+			}
+			if !cmp.NewForNumPrecision(4).GeomExtent(&tc.extent, ext) {
+				t.Errorf("extent, expected %v got %v", tc.extent, ext)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"web mercator quad": {
+			doc:    webMercatorQuadJSON,
+			id:     "WebMercatorQuad",
+			srid:   3857,
+			tile:   Tile{Z: 1, X: 1, Y: 0},
+			size:   Tile{Z: 1, X: 2, Y: 2},
+			extent: geom.Extent{0, 0, 20037508.3427892, 20037508.3427892},
+		},
+		"world crs84 quad": {
+			doc:    worldCRS84QuadJSON,
+			id:     "WorldCRS84Quad",
+			srid:   4326,
+			tile:   Tile{Z: 1, X: 3, Y: 1},
+			size:   Tile{Z: 1, X: 4, Y: 2},
+			extent: geom.Extent{90, -90, 180, 0},
+		},
+		"bottom left": {
+			doc:     `{"crs":"http://www.opengis.net/def/crs/EPSG/0/3857","tileMatrices":[{"cornerOfOrigin":"bottomLeft","cellSize":1,"tileWidth":256,"tileHeight":256}]}`,
+			wantErr: true,
+		},
+		"unknown crs": {
+			doc:     `{"crs":"http://example.com/crs/42","tileMatrices":[]}`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestNewTileMatrixSet(t *testing.T) {
+	// EPSG:3857 with 512 pixel tiles
+	res := make([]float64, 4)
+	for i := range res {
+		res[i] = WebMercatorMax * 2 / 512 / float64(uint(1)<<uint(i))
+	}
+	tms, err := NewTileMatrixSet(
+		3857,
+		geom.Point{-WebMercatorMax, WebMercatorMax},
+		&geom.Extent{-WebMercatorMax, -WebMercatorMax, WebMercatorMax, WebMercatorMax},
+		512,
+		res,
+	)
+	if err != nil {
+		t.Fatalf("error, expected nil got %v", err)
+	}
+
+	grid, _ := NewGrid(3857)
+	for z := uint(0); z < uint(len(res)); z++ {
+		size, _ := tms.Size(z)
+		gsize, _ := grid.Size(z)
+		if *size != *gsize {
+			t.Errorf("size %v, expected %v got %v", z, *gsize, *size)
+		}
+		tile := NewTile(z, size.X-1, 0)
+		ext, _ := Extent(tms, tile)
+		gext, _ := Extent(grid, tile)
+		if !cmp.GeomExtent(ext, gext) {
+			t.Errorf("extent %v, expected %v got %v", tile, gext, ext)
+		}
+	}
+
+	if _, ok := tms.FromNative(4, geom.Point{0, 0}); ok {
+		t.Errorf("from native, expected zoom 4 to not be ok")
+	}
+	if _, ok := tms.FromNative(1, geom.Point{WebMercatorMax + 1, 0}); ok {
+		t.Errorf("from native, expected point outside of matrix to not be ok")
+	}
+
+	tiles := FromBounds(tms, &geom.Extent{-1, -1, 1, 1}, 2)
+	if len(tiles) != 4 {
+		t.Errorf("from bounds, expected 4 tiles got %v", tiles)
+	}
+
+	var count int
+	RangeFamilyAt(tms, NewTile(1, 0, 0), 3, func(*Tile) error {
+		count++
+		return nil
+	})
+	if count != 16 {
+		t.Errorf("range family, expected 16 tiles got %v", count)
+	}
+}
@@ -1,9 +1,13 @@
 package slippy_test
 
 import (
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/go-spatial/proj"
+
 	"github.com/hahaking119/geom/slippy"
 )
 
@@ -53,7 +57,6 @@ func TestNewTile(t *testing.T) {
 
 }
 
-/*
 func TestRangeFamilyAt(t *testing.T) {
 	type coord struct {
 		z, x, y uint
@@ -79,8 +82,13 @@ func TestRangeFamilyAt(t *testing.T) {
 	fn := func(tc tcase) func(t *testing.T) {
 		return func(t *testing.T) {
 
+			grid, err := slippy.NewGrid(tc.tileSRID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
 			coordList := make([]coord, 0, len(tc.expected))
-			tc.tile.RangeFamilyAt(tc.zoomAt, tc.tileSRID, func(tile *slippy.Tile, srid uint) error {
+			err = slippy.RangeFamilyAt(grid, tc.tile, tc.zoomAt, func(tile *slippy.Tile) error {
 				z, x, y := tile.ZXY()
 				c := coord{z, x, y}
 
@@ -88,6 +96,9 @@ func TestRangeFamilyAt(t *testing.T) {
 
 				return nil
 			})
+			if err != nil {
+				t.Fatalf("error, expected nil, got %v", err)
+			}
 
 			if len(coordList) != len(tc.expected) {
 				t.Fatalf("coordinate list length, expected %d, got %d", len(tc.expected), len(coordList))
@@ -191,7 +202,197 @@ func TestRangeFamilyAt(t *testing.T) {
 	}
 }
 
+func TestTileFamily(t *testing.T) {
+	type tcase struct {
+		tile      slippy.Tile
+		srid      uint
+		parent    *slippy.Tile
+		children  []slippy.Tile
+		siblings  []slippy.Tile
+		neighbors []slippy.Tile
+		wrapped   []slippy.Tile
+	}
+
+	fn := func(tc tcase) func(t *testing.T) {
+		return func(t *testing.T) {
+			grid, err := slippy.NewGrid(tc.srid)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parent, _ := tc.tile.Parent(grid)
+			if !reflect.DeepEqual(parent, tc.parent) {
+				t.Errorf("parent, expected %v, got %v", tc.parent, parent)
+			}
+			children, _ := tc.tile.Children(grid)
+			if !reflect.DeepEqual(children, tc.children) {
+				t.Errorf("children, expected %v, got %v", tc.children, children)
+			}
+			siblings, _ := tc.tile.Siblings(grid)
+			if !reflect.DeepEqual(siblings, tc.siblings) {
+				t.Errorf("siblings, expected %v, got %v", tc.siblings, siblings)
+			}
+			neighbors, _ := tc.tile.Neighbors(grid, false)
+			if !reflect.DeepEqual(neighbors, tc.neighbors) {
+				t.Errorf("neighbors, expected %v, got %v", tc.neighbors, neighbors)
+			}
+			wrapped, _ := tc.tile.Neighbors(grid, true)
+			if !reflect.DeepEqual(wrapped, tc.wrapped) {
+				t.Errorf("wrapped neighbors, expected %v, got %v", tc.wrapped, wrapped)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"zoom 0": {
+			tile: slippy.Tile{},
+			srid: 3857,
+			children: []slippy.Tile{
+				{Z: 1, X: 0, Y: 0}, {Z: 1, X: 0, Y: 1}, {Z: 1, X: 1, Y: 0}, {Z: 1, X: 1, Y: 1},
+			},
+			siblings: []slippy.Tile{},
+		},
+		"left edge": {
+			tile:   slippy.Tile{Z: 2, X: 0, Y: 1},
+			srid:   3857,
+			parent: slippy.NewTile(1, 0, 0),
+			children: []slippy.Tile{
+				{Z: 3, X: 0, Y: 2}, {Z: 3, X: 0, Y: 3}, {Z: 3, X: 1, Y: 2}, {Z: 3, X: 1, Y: 3},
+			},
+			siblings: []slippy.Tile{
+				{Z: 2, X: 0, Y: 0}, {Z: 2, X: 1, Y: 0}, {Z: 2, X: 1, Y: 1},
+			},
+			neighbors: []slippy.Tile{
+				{Z: 2, X: 0, Y: 0}, {Z: 2, X: 1, Y: 0},
+				{Z: 2, X: 1, Y: 1},
+				{Z: 2, X: 0, Y: 2}, {Z: 2, X: 1, Y: 2},
+			},
+			wrapped: []slippy.Tile{
+				{Z: 2, X: 3, Y: 0}, {Z: 2, X: 0, Y: 0}, {Z: 2, X: 1, Y: 0},
+				{Z: 2, X: 3, Y: 1}, {Z: 2, X: 1, Y: 1},
+				{Z: 2, X: 3, Y: 2}, {Z: 2, X: 0, Y: 2}, {Z: 2, X: 1, Y: 2},
+			},
+		},
+		"4326 zoom 0": {
+			tile: slippy.Tile{X: 1},
+			srid: 4326,
+			children: []slippy.Tile{
+				{Z: 1, X: 2, Y: 0}, {Z: 1, X: 2, Y: 1}, {Z: 1, X: 3, Y: 0}, {Z: 1, X: 3, Y: 1},
+			},
+			siblings:  []slippy.Tile{{Z: 0, X: 0, Y: 0}},
+			neighbors: []slippy.Tile{{Z: 0, X: 0, Y: 0}},
+			wrapped:   []slippy.Tile{{Z: 0, X: 0, Y: 0}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestTileQuadkey(t *testing.T) {
+	type tcase struct {
+		tile    slippy.Tile
+		quadkey string
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			qk := tc.tile.Quadkey()
+			if qk != tc.quadkey {
+				t.Errorf("quadkey, expected %v, got %v", tc.quadkey, qk)
+			}
+			tile, err := slippy.NewTileFromQuadkey(tc.quadkey)
+			if err != nil {
+				t.Fatalf("error, expected nil, got %v", err)
+			}
+			if *tile != tc.tile {
+				t.Errorf("tile, expected %v, got %v", tc.tile, *tile)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"zoom 0": {
+			tile:    slippy.Tile{},
+			quadkey: "",
+		},
+		"bing example": {
+			tile:    slippy.Tile{Z: 3, X: 3, Y: 5},
+			quadkey: "213",
+		},
+		"zoom 16": {
+			tile:    slippy.Tile{Z: 16, X: 11436, Y: 26461},
+			quadkey: "0230132212123302",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+
+	if _, err := slippy.NewTileFromQuadkey("0124"); err == nil {
+		t.Errorf("error, expected error for invalid quadkey, got nil")
+	}
+}
+
+func TestParseTile(t *testing.T) {
+	type tcase struct {
+		str  string
+		tile *slippy.Tile
+	}
 
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			tile, err := slippy.ParseTile(tc.str)
+			if tc.tile == nil {
+				if err == nil {
+					t.Errorf("error, expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error, expected nil, got %v", err)
+			}
+			if *tile != *tc.tile {
+				t.Errorf("tile, expected %v, got %v", tc.tile, tile)
+			}
+			if tc.tile.String() != strings.TrimSuffix(strings.TrimPrefix(tc.str, "/"), ".pbf") {
+				t.Errorf("string, expected %v, got %v", tc.str, tc.tile.String())
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"zxy":      {str: "16/11436/26461", tile: slippy.NewTile(16, 11436, 26461)},
+		"url":      {str: "/2/1/3.pbf", tile: slippy.NewTile(2, 1, 3)},
+		"short":    {str: "2/1"},
+		"not num":  {str: "2/a/1"},
+		"big zoom": {str: "23/1/1"},
+		"negative": {str: "2/-1/1"},
+		"extra":    {str: "2/1/1/1"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestTileFlipY(t *testing.T) {
+	grid, _ := slippy.NewGrid(3857)
+	tile, ok := slippy.NewTile(3, 1, 1).FlipY(grid)
+	if !ok {
+		t.Fatal("ok, expected true")
+	}
+	if *tile != *slippy.NewTile(3, 1, 6) {
+		t.Errorf("tile, expected 3/1/6, got %v", tile)
+	}
+	if _, ok := slippy.NewTile(3, 1, 8).FlipY(grid); ok {
+		t.Errorf("ok, expected false for a tile outside of the grid")
+	}
+}
+
+/*
 func TestNewTileMinMaxer(t *testing.T) {
 	type tcase struct {
 		mm       geom.MinMaxer
@@ -0,0 +1,573 @@
+package spherical
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/planar/coord"
+)
+
+// Antimeridian is the longitude of the antimeridian in decimal degrees.
+const Antimeridian = 180.0
+
+// ErrEnclosesPole is returned when a ring wraps all the way around a pole, and
+// so can not be split at the antimeridian into separate rings.
+var ErrEnclosesPole = errors.New("spherical: ring encloses a pole")
+
+// NormalizeLng returns the given lng/lat point with the longitude normalized
+// to be greater than -180 and at most 180.
+func NormalizeLng(pt [2]float64) [2]float64 {
+	ll := coord.LngLat{Lng: pt[0], Lat: pt[1]}.NormalizeLng()
+	return [2]float64{ll.Lng, ll.Lat}
+}
+
+// Normalize returns a copy of the given lng/lat geometry with all of the longitudes
+// normalized to be greater than -180 and at most 180. Edges are not split, see SplitAntimeridian for that.
+func Normalize(g geom.Geometry) (geom.Geometry, error) {
+	if c, ok := g.(geom.Collection); ok {
+		geos := make(geom.Collection, 0, len(c))
+		for i := range c {
+			ng, err := Normalize(c[i])
+			if err != nil {
+				return nil, err
+			}
+			geos = append(geos, ng)
+		}
+		return geos, nil
+	}
+
+	return geom.ApplyToPoints(g, func(coords ...float64) ([]float64, error) {
+		pt := NormalizeLng([2]float64{coords[0], coords[1]})
+		return pt[:], nil
+	})
+}
+
+// crosses reports whether the shortest path between a and b crosses the antimeridian.
+// a and b should have normalized longitudes.
+func crosses(a, b [2]float64) bool { return math.Abs(b[0]-a[0]) > Antimeridian }
+
+// CrossesAntimeridian reports whether any edge of the given lng/lat geometry crosses the
+// antimeridian. Edges are assumed to take the shortest path between their vertices, so
+// an edge spanning more than 180° of longitude is taken to cross the antimeridian.
+func CrossesAntimeridian(g geom.Geometry) (bool, error) {
+	switch gg := g.(type) {
+	default:
+		return false, geom.ErrUnknownGeometry{Geom: g}
+
+	case geom.Pointer, geom.MultiPointer:
+		return false, nil
+
+	case geom.LineStringer:
+		return lineCrosses(gg.Vertices(), false), nil
+
+	case geom.MultiLineStringer:
+		for _, ls := range gg.LineStrings() {
+			if lineCrosses(ls, false) {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case geom.Polygoner:
+		for _, r := range gg.LinearRings() {
+			if lineCrosses(r, true) {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case geom.MultiPolygoner:
+		for _, p := range gg.Polygons() {
+			if ok, _ := CrossesAntimeridian(geom.Polygon(p)); ok {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case geom.Collectioner:
+		for _, child := range gg.Geometries() {
+			ok, err := CrossesAntimeridian(child)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// sided returns the points with normalized longitudes, where the points on the
+// antimeridian are put on the side of the point before them; leading points on
+// it are put on the side of the first point that isn't.
+func sided(pts [][2]float64) [][2]float64 {
+	out := make([][2]float64, len(pts))
+	side := Antimeridian
+	for i := len(pts) - 1; i >= 0; i-- {
+		out[i] = NormalizeLng(pts[i])
+		if out[i][0] != Antimeridian {
+			side = out[i][0]
+		}
+	}
+	for i := range out {
+		if out[i][0] == Antimeridian {
+			out[i][0] = math.Copysign(Antimeridian, side)
+		}
+		side = out[i][0]
+	}
+	return out
+}
+
+func lineCrosses(pts [][2]float64, closed bool) bool {
+	if len(pts) < 2 {
+		return false
+	}
+	pts = sided(pts)
+	for i := 1; i < len(pts); i++ {
+		if crosses(pts[i-1], pts[i]) {
+			return true
+		}
+	}
+	return closed && crosses(pts[len(pts)-1], pts[0])
+}
+
+// SplitAntimeridian returns a copy of the given lng/lat geometry with the longitudes normalized
+// and any edges that cross the antimeridian split at it. A LineString or Polygon that crosses
+// the antimeridian is returned as a MultiLineString or MultiPolygon whose parts touch ±180°;
+// geometries that don't cross are returned normalized in their original type.
+//
+// The latitude where an edge crosses is linearly interpolated in lng/lat space.
+func SplitAntimeridian(g geom.Geometry) (geom.Geometry, error) {
+	switch gg := g.(type) {
+	default:
+		return nil, geom.ErrUnknownGeometry{Geom: g}
+
+	case geom.Pointer:
+		return geom.Point(NormalizeLng(gg.XY())), nil
+
+	case geom.MultiPointer:
+		pts := gg.Points()
+		mp := make(geom.MultiPoint, len(pts))
+		for i := range pts {
+			mp[i] = NormalizeLng(pts[i])
+		}
+		return mp, nil
+
+	case geom.LineStringer:
+		mls := splitLine(gg.Vertices())
+		if len(mls) == 1 {
+			return geom.LineString(mls[0]), nil
+		}
+		return mls, nil
+
+	case geom.MultiLineStringer:
+		var mls geom.MultiLineString
+		for _, ls := range gg.LineStrings() {
+			mls = append(mls, splitLine(ls)...)
+		}
+		return mls, nil
+
+	case geom.Polygoner:
+		mp, err := splitPolygon(gg.LinearRings())
+		if err != nil {
+			return nil, err
+		}
+		if len(mp) == 1 {
+			return geom.Polygon(mp[0]), nil
+		}
+		return mp, nil
+
+	case geom.MultiPolygoner:
+		var mp geom.MultiPolygon
+		for _, p := range gg.Polygons() {
+			parts, err := splitPolygon(p)
+			if err != nil {
+				return nil, err
+			}
+			mp = append(mp, parts...)
+		}
+		return mp, nil
+
+	case geom.Collectioner:
+		geos := gg.Geometries()
+		col := make(geom.Collection, 0, len(geos))
+		for _, child := range geos {
+			ng, err := SplitAntimeridian(child)
+			if err != nil {
+				return nil, err
+			}
+			col = append(col, ng)
+		}
+		return col, nil
+	}
+}
+
+// crossingLat returns the latitude where the edge a,b crosses the antimeridian.
+// a and b are expected to have normalized longitudes on opposite sides.
+func crossingLat(a, b [2]float64) float64 {
+	// move b next to a so the edge does not wrap around the globe.
+	bx := b[0] - 360
+	if a[0] > 0 {
+		bx = b[0] + 360
+	}
+	m := math.Copysign(Antimeridian, a[0])
+	return a[1] + (m-a[0])*(b[1]-a[1])/(bx-a[0])
+}
+
+func splitLine(pts [][2]float64) geom.MultiLineString {
+	if len(pts) == 0 {
+		return nil
+	}
+	pts = sided(pts)
+	var (
+		mls  geom.MultiLineString
+		prev = pts[0]
+		cur  = [][2]float64{prev}
+	)
+	for _, pt := range pts[1:] {
+		if crosses(prev, pt) {
+			lat := crossingLat(prev, pt)
+			m := math.Copysign(Antimeridian, prev[0])
+			// prev may already be on the antimeridian
+			if prev != [2]float64{m, lat} {
+				cur = append(cur, [2]float64{m, lat})
+			}
+			mls = append(mls, cur)
+			cur = [][2]float64{{-m, lat}}
+		}
+		cur = append(cur, pt)
+		prev = pt
+	}
+	return append(mls, cur)
+}
+
+// unwrapRing returns the ring with the longitudes adjusted so no consecutive
+// vertices are more than 180° apart. The first vertex is normalized.
+func unwrapRing(ring [][2]float64) ([][2]float64, error) {
+	if len(ring) == 0 {
+		return nil, nil
+	}
+	// drop the closing vertex, if there is one
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+	out := make([][2]float64, len(ring))
+	out[0] = NormalizeLng(ring[0])
+	for i := 1; i < len(ring); i++ {
+		pt := NormalizeLng(ring[i])
+		for pt[0]-out[i-1][0] > Antimeridian {
+			pt[0] -= 360
+		}
+		for out[i-1][0]-pt[0] > Antimeridian {
+			pt[0] += 360
+		}
+		out[i] = pt
+	}
+	// when walking back to the start we should end up where we began, otherwise
+	// the ring has gone around the globe.
+	if math.Abs(out[0][0]-out[len(out)-1][0]) > Antimeridian {
+		return nil, ErrEnclosesPole
+	}
+	return out, nil
+}
+
+// signedArea returns twice the signed area of the ring, positive for counterclockwise
+// rings in a y-up coordinate system.
+func signedArea(ring [][2]float64) (a float64) {
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		a += ring[i][0]*ring[j][1] - ring[j][0]*ring[i][1]
+	}
+	return a
+}
+
+func reverse(ring [][2]float64) {
+	for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+		ring[i], ring[j] = ring[j], ring[i]
+	}
+}
+
+func shift(ring [][2]float64, dx float64) [][2]float64 {
+	out := make([][2]float64, len(ring))
+	for i := range ring {
+		out[i] = [2]float64{ring[i][0] + dx, ring[i][1]}
+	}
+	return out
+}
+
+// splitPolygon splits the polygon into one polygon per side of the antimeridian.
+func splitPolygon(rings [][][2]float64) (geom.MultiPolygon, error) {
+	if len(rings) == 0 {
+		return nil, nil
+	}
+
+	urings := make([][][2]float64, 0, len(rings))
+	for i := range rings {
+		r, err := unwrapRing(rings[i])
+		if err != nil {
+			return nil, err
+		}
+		if len(r) < 3 {
+			continue
+		}
+		urings = append(urings, r)
+	}
+	if len(urings) == 0 {
+		return nil, nil
+	}
+
+	// move the holes next to the exterior.
+	ext := geom.NewExtent(urings[0]...)
+	center := (ext.MinX() + ext.MaxX()) / 2
+	for i := 1; i < len(urings); i++ {
+		he := geom.NewExtent(urings[i]...)
+		if dx := 360 * math.Round((center-(he.MinX()+he.MaxX())/2)/360); dx != 0 {
+			urings[i] = shift(urings[i], dx)
+		}
+		ext.AddPoints(urings[i]...)
+	}
+
+	// all the meridians that cut the unwrapped polygon.
+	var cuts []float64
+	for m := Antimeridian + 360*math.Floor((ext.MinX()-Antimeridian)/360) + 360; m < ext.MaxX(); m += 360 {
+		cuts = append(cuts, m)
+	}
+
+	// the cutting needs the winding of the holes to be the opposite of the exterior.
+	ccw := signedArea(urings[0]) > 0
+	if len(cuts) > 0 {
+		for i := 1; i < len(urings); i++ {
+			if (signedArea(urings[i]) > 0) == ccw {
+				reverse(urings[i])
+			}
+		}
+	}
+
+	polys := [][][][2]float64{urings}
+	for _, m := range cuts {
+		var next [][][][2]float64
+		for _, p := range polys {
+			next = append(next, cutPolygon(p, m, ccw, false)...)
+			next = append(next, cutPolygon(p, m, ccw, true)...)
+		}
+		polys = next
+	}
+
+	mp := make(geom.MultiPolygon, 0, len(polys))
+	for _, p := range polys {
+		pe := geom.NewExtent(p[0]...)
+		dx := -360 * math.Floor((pe.MinX()+Antimeridian)/360)
+		plyg := make([][][2]float64, len(p))
+		for i := range p {
+			plyg[i] = shift(p[i], dx)
+		}
+		mp = append(mp, plyg)
+	}
+	return mp, nil
+}
+
+type chain struct {
+	pts  [][2]float64
+	used bool
+}
+
+func (c *chain) start() float64 { return c.pts[0][1] }
+func (c *chain) end() float64   { return c.pts[len(c.pts)-1][1] }
+
+// cutPolygon returns the parts of the polygon that lie on the east (x >= m) or
+// west (x <= m) of the meridian m. ccw is the winding of the exterior ring; holes
+// are expected to have the opposite winding.
+func cutPolygon(rings [][][2]float64, m float64, ccw, east bool) [][][][2]float64 {
+	side := func(pt [2]float64) float64 {
+		if east {
+			return pt[0] - m
+		}
+		return m - pt[0]
+	}
+	intersect := func(a, b [2]float64) [2]float64 {
+		t := (m - a[0]) / (b[0] - a[0])
+		return [2]float64{m, a[1] + t*(b[1]-a[1])}
+	}
+	appendPt := func(pts [][2]float64, pt [2]float64) [][2]float64 {
+		if len(pts) > 0 && pts[len(pts)-1] == pt {
+			return pts
+		}
+		return append(pts, pt)
+	}
+
+	var (
+		whole  [][][2]float64
+		chains []*chain
+	)
+
+	for _, ring := range rings {
+		start := -1
+		allIn := true
+		for i := range ring {
+			if side(ring[i]) < 0 {
+				allIn = false
+				start = i
+				break
+			}
+		}
+		if allIn {
+			whole = append(whole, ring)
+			continue
+		}
+
+		var cur [][2]float64
+		n := len(ring)
+		for k := 0; k < n; k++ {
+			a, b := ring[(start+k)%n], ring[(start+k+1)%n]
+			aIn, bIn := side(a) >= 0, side(b) >= 0
+			switch {
+			case !aIn && bIn:
+				cur = appendPt(nil, intersect(a, b))
+				cur = appendPt(cur, b)
+			case aIn && bIn:
+				cur = appendPt(cur, b)
+			case aIn && !bIn:
+				cur = appendPt(cur, intersect(a, b))
+				if len(cur) > 1 {
+					chains = append(chains, &chain{pts: cur})
+				}
+				cur = nil
+			}
+		}
+	}
+
+	// Walking along the cut, the interior should stay on the same side as it does
+	// while walking the rings; which way that is depends on the winding and side.
+	north := ccw != east
+
+	var rs [][][2]float64
+	for _, c := range chains {
+		if c.used {
+			continue
+		}
+		var ring [][2]float64
+		for cc := c; cc != nil && !cc.used; {
+			cc.used = true
+			ring = append(ring, cc.pts...)
+
+			var best *chain
+			for _, o := range chains {
+				if o.used && o != c {
+					continue
+				}
+				if north && o.start() < cc.end() || !north && o.start() > cc.end() {
+					continue
+				}
+				if best == nil || math.Abs(o.start()-cc.end()) < math.Abs(best.start()-cc.end()) {
+					best = o
+				}
+			}
+			cc = best
+		}
+		if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+			ring = ring[:len(ring)-1]
+		}
+		if len(ring) >= 3 {
+			rs = append(rs, ring)
+		}
+	}
+	rs = append(rs, whole...)
+
+	// separate the shells from the holes
+	var shells, holes [][][2]float64
+	for _, r := range rs {
+		if a := signedArea(r); a == 0 {
+			continue
+		} else if (a > 0) == ccw {
+			shells = append(shells, r)
+		} else {
+			holes = append(holes, r)
+		}
+	}
+	sort.SliceStable(shells, func(i, j int) bool {
+		return math.Abs(signedArea(shells[i])) < math.Abs(signedArea(shells[j]))
+	})
+
+	polys := make([][][][2]float64, len(shells))
+	for i := range shells {
+		polys[i] = [][][2]float64{shells[i]}
+	}
+	for _, h := range holes {
+		pt := h[0]
+		for _, v := range h {
+			if v[0] != m {
+				pt = v
+				break
+			}
+		}
+		// shells are sorted smallest first, so the first one that contains the
+		// hole is the tightest one.
+		for i := range shells {
+			if ringContains(shells[i], pt) {
+				polys[i] = append(polys[i], h)
+				break
+			}
+		}
+	}
+	return polys
+}
+
+// ringContains reports whether the point is inside the ring, using the even-odd rule.
+func ringContains(ring [][2]float64, pt [2]float64) bool {
+	in := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > pt[1]) != (b[1] > pt[1]) &&
+			pt[0] < (b[0]-a[0])*(pt[1]-a[1])/(b[1]-a[1])+a[0] {
+			in = !in
+		}
+	}
+	return in
+}
+
+// lngGap describes the gap between two sorted longitudes.
+type lngGap struct {
+	west, east float64
+	size       float64
+}
+
+// GeometryHull returns the smallest extent, taking into account the antimeridian, that
+// contains all of the vertices of the given lng/lat geometry. Like Hull, the extent is
+// ordered [4]float64{West, South, East, North}; when the extent crosses the antimeridian
+// West will be greater than East.
+func GeometryHull(g geom.Geometry) (*geom.Extent, error) {
+	pts, err := geom.GetCoordinates(g)
+	if err != nil {
+		return nil, err
+	}
+	if len(pts) == 0 {
+		return nil, nil
+	}
+
+	lngs := make([]float64, len(pts))
+	south, north := pts[0][1], pts[0][1]
+	for i := range pts {
+		lngs[i] = NormalizeLng(pts[i])[0]
+		south = math.Min(south, pts[i][1])
+		north = math.Max(north, pts[i][1])
+	}
+	sort.Float64s(lngs)
+
+	// The largest gap between consecutive longitudes is the part of the globe that
+	// is not covered; by default that's the one that wraps around the antimeridian.
+	largest := lngGap{
+		west: lngs[len(lngs)-1],
+		east: lngs[0],
+		size: lngs[0] + 360 - lngs[len(lngs)-1],
+	}
+	for i := 1; i < len(lngs); i++ {
+		if s := lngs[i] - lngs[i-1]; s > largest.size {
+			largest = lngGap{west: lngs[i-1], east: lngs[i], size: s}
+		}
+	}
+
+	return &geom.Extent{largest.east, south, largest.west, north}, nil
+}
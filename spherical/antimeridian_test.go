@@ -0,0 +1,206 @@
+package spherical_test
+
+import (
+	"testing"
+
+	"github.com/hahaking119/geom"
+	"github.com/hahaking119/geom/cmp"
+	"github.com/hahaking119/geom/spherical"
+)
+
+func TestCrossesAntimeridian(t *testing.T) {
+	type tcase struct {
+		geom     geom.Geometry
+		expected bool
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := spherical.CrossesAntimeridian(tc.geom)
+			if err != nil {
+				t.Fatalf("error, expected nil, got %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("crosses, expected %v, got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			geom: geom.Point{179, 0},
+		},
+		"line": {
+			geom: geom.LineString{{-10, 0}, {10, 0}},
+		},
+		"line crossing": {
+			geom:     geom.LineString{{170, 0}, {-170, 0}},
+			expected: true,
+		},
+		"polygon crossing on closing edge": {
+			geom:     geom.Polygon{{{-175, 0}, {-175, 10}, {175, 10}, {175, 0}}},
+			expected: true,
+		},
+		"polygon": {
+			geom: geom.Polygon{{{0, 0}, {0, 10}, {10, 10}, {10, 0}}},
+		},
+		"line ending on the antimeridian": {
+			geom: geom.LineString{{-170, 0}, {-180, 10}},
+		},
+		"line through the antimeridian vertex": {
+			geom:     geom.LineString{{-170, 0}, {180, 10}, {170, 20}},
+			expected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestSplitAntimeridian(t *testing.T) {
+	type tcase struct {
+		geom     geom.Geometry
+		expected geom.Geometry
+		err      error
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := spherical.SplitAntimeridian(tc.geom)
+			if err != tc.err {
+				t.Fatalf("error, expected %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+			if !cmp.GeometryEqual(tc.expected, got) {
+				t.Errorf("geometry, expected %v, got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			geom:     geom.Point{190, 10},
+			expected: geom.Point{-170, 10},
+		},
+		"line not crossing": {
+			geom:     geom.LineString{{10, 0}, {20, 10}},
+			expected: geom.LineString{{10, 0}, {20, 10}},
+		},
+		"line ending on the antimeridian": {
+			geom:     geom.LineString{{-170, 0}, {-180, 10}},
+			expected: geom.LineString{{-170, 0}, {-180, 10}},
+		},
+		"line starting on the antimeridian": {
+			geom:     geom.LineString{{180, 0}, {-170, 10}},
+			expected: geom.LineString{{-180, 0}, {-170, 10}},
+		},
+		"line through the antimeridian vertex": {
+			geom: geom.LineString{{-170, 0}, {180, 10}, {170, 20}},
+			expected: geom.MultiLineString{
+				{{-170, 0}, {-180, 10}},
+				{{180, 10}, {170, 20}},
+			},
+		},
+		"line": {
+			geom: geom.LineString{{170, 0}, {-170, 10}, {-160, 10}},
+			expected: geom.MultiLineString{
+				{{170, 0}, {180, 5}},
+				{{-180, 5}, {-170, 10}, {-160, 10}},
+			},
+		},
+		"line unnormalized": {
+			geom: geom.LineString{{170, 0}, {190, 10}},
+			expected: geom.MultiLineString{
+				{{170, 0}, {180, 5}},
+				{{-180, 5}, {-170, 10}},
+			},
+		},
+		"polygon": {
+			geom: geom.Polygon{{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}}},
+			expected: geom.MultiPolygon{
+				{{{180, -10}, {180, 10}, {170, 10}, {170, -10}}},
+				{{{-180, 10}, {-180, -10}, {-170, -10}, {-170, 10}}},
+			},
+		},
+		"polygon with hole": {
+			geom: geom.Polygon{
+				{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}},
+				{{175, -5}, {175, 5}, {-175, 5}, {-175, -5}},
+			},
+			expected: geom.MultiPolygon{
+				{{{180, -10}, {180, -5}, {175, -5}, {175, 5}, {180, 5}, {180, 10}, {170, 10}, {170, -10}}},
+				{{{-180, 10}, {-180, 5}, {-175, 5}, {-175, -5}, {-180, -5}, {-180, -10}, {-170, -10}, {-170, 10}}},
+			},
+		},
+		"polygon with hole on one side": {
+			geom: geom.Polygon{
+				{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}},
+				{{172, -5}, {172, 5}, {175, 5}, {175, -5}},
+			},
+			expected: geom.MultiPolygon{
+				{
+					{{180, -10}, {180, 10}, {170, 10}, {170, -10}},
+					{{172, -5}, {172, 5}, {175, 5}, {175, -5}},
+				},
+				{{{-180, 10}, {-180, -10}, {-170, -10}, {-170, 10}}},
+			},
+		},
+		"concave polygon": {
+			// a U shape opening to the south, which crosses the antimeridian twice
+			geom: geom.Polygon{{{175, 0}, {175, 10}, {-175, 10}, {-175, 0}, {-178, 0}, {-178, 5}, {178, 5}, {178, 0}}},
+			expected: geom.MultiPolygon{
+				{{{180, 5}, {178, 5}, {178, 0}, {175, 0}, {175, 10}, {180, 10}}},
+				{{{-180, 10}, {-175, 10}, {-175, 0}, {-178, 0}, {-178, 5}, {-180, 5}}},
+			},
+		},
+		"polygon around the pole": {
+			geom: geom.Polygon{{{-120, 80}, {0, 80}, {120, 80}}},
+			err:  spherical.ErrEnclosesPole,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestGeometryHull(t *testing.T) {
+	type tcase struct {
+		geom     geom.Geometry
+		expected *geom.Extent
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			got, err := spherical.GeometryHull(tc.geom)
+			if err != nil {
+				t.Fatalf("error, expected nil, got %v", err)
+			}
+			if !cmp.GeomExtent(tc.expected, got) {
+				t.Errorf("extent, expected %v, got %v", tc.expected, got)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"line": {
+			geom:     geom.LineString{{-10, 0}, {10, 5}},
+			expected: &geom.Extent{-10, 0, 10, 5},
+		},
+		"fiji": {
+			geom:     geom.MultiPoint{{177, -17}, {-179, -16}, {178, -18}},
+			expected: &geom.Extent{177, -18, -179, -16},
+		},
+		"unnormalized": {
+			geom:     geom.LineString{{170, 0}, {190, 10}},
+			expected: &geom.Extent{170, 0, -170, 10},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -3,6 +3,8 @@ package geom
 import "fmt"
 
 // ApplyToPoints applys the given function to each point in the geometry and any sub geometries, return a new transformed geometry.
+// For the Z, M and ZM geometries f is called with all of the coordinates of the point; any trailing coordinates f
+// does not return are kept as they were. The SRID of the S geometries is kept as is.
 func ApplyToPoints(geometry Geometry, f func(coords ...float64) ([]float64, error)) (Geometry, error) {
 	switch geo := geometry.(type) {
 	default:
@@ -106,7 +108,327 @@ func ApplyToPoints(geometry Geometry, f func(coords ...float64) ([]float64, erro
 			mpoly[i] = polyv
 		}
 		return mpoly, nil
+
+	case PointZ:
+		c, err := applyToCoords(f, geo[:])
+		if err != nil {
+			return nil, err
+		}
+		return PointZ{c[0], c[1], c[2]}, nil
+
+	case PointM:
+		c, err := applyToCoords(f, geo[:])
+		if err != nil {
+			return nil, err
+		}
+		return PointM{c[0], c[1], c[2]}, nil
+
+	case PointZM:
+		c, err := applyToCoords(f, geo[:])
+		if err != nil {
+			return nil, err
+		}
+		return PointZM{c[0], c[1], c[2], c[3]}, nil
+
+	case MultiPointZ:
+		pts, err := applyToPoints3(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return MultiPointZ(pts), nil
+
+	case MultiPointM:
+		pts, err := applyToPoints3(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return MultiPointM(pts), nil
+
+	case MultiPointZM:
+		pts, err := applyToPoints4(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return MultiPointZM(pts), nil
+
+	case LineStringZ:
+		pts, err := applyToPoints3(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return LineStringZ(pts), nil
+
+	case LineStringM:
+		pts, err := applyToPoints3(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return LineStringM(pts), nil
+
+	case LineStringZM:
+		pts, err := applyToPoints4(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return LineStringZM(pts), nil
+
+	case MultiLineStringZ:
+		lines, err := applyToLines3(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return MultiLineStringZ(lines), nil
+
+	case MultiLineStringM:
+		lines, err := applyToLines3(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return MultiLineStringM(lines), nil
+
+	case MultiLineStringZM:
+		lines, err := applyToLines4(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return MultiLineStringZM(lines), nil
+
+	case PolygonZ:
+		rings, err := applyToLines3(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return PolygonZ(rings), nil
+
+	case PolygonM:
+		rings, err := applyToLines3(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return PolygonM(rings), nil
+
+	case PolygonZM:
+		rings, err := applyToLines4(f, geo)
+		if err != nil {
+			return nil, err
+		}
+		return PolygonZM(rings), nil
+
+	case PointS:
+		pt, err := ApplyToPoints(geo.Xy, f)
+		if err != nil {
+			return nil, err
+		}
+		return PointS{Srid: geo.Srid, Xy: pt.(Point)}, nil
+
+	case PointZS:
+		pt, err := ApplyToPoints(geo.Xyz, f)
+		if err != nil {
+			return nil, err
+		}
+		return PointZS{Srid: geo.Srid, Xyz: pt.(PointZ)}, nil
+
+	case PointMS:
+		pt, err := ApplyToPoints(geo.Xym, f)
+		if err != nil {
+			return nil, err
+		}
+		return PointMS{Srid: geo.Srid, Xym: pt.(PointM)}, nil
+
+	case PointZMS:
+		pt, err := ApplyToPoints(geo.Xyzm, f)
+		if err != nil {
+			return nil, err
+		}
+		return PointZMS{Srid: geo.Srid, Xyzm: pt.(PointZM)}, nil
+
+	case MultiPointS:
+		mp, err := ApplyToPoints(geo.Mp, f)
+		if err != nil {
+			return nil, err
+		}
+		return MultiPointS{Srid: geo.Srid, Mp: mp.(MultiPoint)}, nil
+
+	case MultiPointZS:
+		mp, err := ApplyToPoints(geo.Mpz, f)
+		if err != nil {
+			return nil, err
+		}
+		return MultiPointZS{Srid: geo.Srid, Mpz: mp.(MultiPointZ)}, nil
+
+	case MultiPointMS:
+		mp, err := ApplyToPoints(geo.Mpm, f)
+		if err != nil {
+			return nil, err
+		}
+		return MultiPointMS{Srid: geo.Srid, Mpm: mp.(MultiPointM)}, nil
+
+	case MultiPointZMS:
+		mp, err := ApplyToPoints(geo.Mpzm, f)
+		if err != nil {
+			return nil, err
+		}
+		return MultiPointZMS{Srid: geo.Srid, Mpzm: mp.(MultiPointZM)}, nil
+
+	case LineStringS:
+		ls, err := ApplyToPoints(geo.Ls, f)
+		if err != nil {
+			return nil, err
+		}
+		return LineStringS{Srid: geo.Srid, Ls: ls.(LineString)}, nil
+
+	case LineStringZS:
+		ls, err := ApplyToPoints(geo.Lsz, f)
+		if err != nil {
+			return nil, err
+		}
+		return LineStringZS{Srid: geo.Srid, Lsz: ls.(LineStringZ)}, nil
+
+	case LineStringMS:
+		ls, err := ApplyToPoints(geo.Lsm, f)
+		if err != nil {
+			return nil, err
+		}
+		return LineStringMS{Srid: geo.Srid, Lsm: ls.(LineStringM)}, nil
+
+	case LineStringZMS:
+		ls, err := ApplyToPoints(geo.Lszm, f)
+		if err != nil {
+			return nil, err
+		}
+		return LineStringZMS{Srid: geo.Srid, Lszm: ls.(LineStringZM)}, nil
+
+	case MultiLineStringS:
+		mls, err := ApplyToPoints(geo.Mls, f)
+		if err != nil {
+			return nil, err
+		}
+		return MultiLineStringS{Srid: geo.Srid, Mls: mls.(MultiLineString)}, nil
+
+	case MultiLineStringZS:
+		mls, err := ApplyToPoints(geo.Mlsz, f)
+		if err != nil {
+			return nil, err
+		}
+		return MultiLineStringZS{Srid: geo.Srid, Mlsz: mls.(MultiLineStringZ)}, nil
+
+	case MultiLineStringMS:
+		mls, err := ApplyToPoints(geo.Mlsm, f)
+		if err != nil {
+			return nil, err
+		}
+		return MultiLineStringMS{Srid: geo.Srid, Mlsm: mls.(MultiLineStringM)}, nil
+
+	case MultiLineStringZMS:
+		mls, err := ApplyToPoints(geo.Mlszm, f)
+		if err != nil {
+			return nil, err
+		}
+		return MultiLineStringZMS{Srid: geo.Srid, Mlszm: mls.(MultiLineStringZM)}, nil
+
+	case PolygonS:
+		poly, err := ApplyToPoints(geo.Pol, f)
+		if err != nil {
+			return nil, err
+		}
+		return PolygonS{Srid: geo.Srid, Pol: poly.(Polygon)}, nil
+
+	case PolygonZS:
+		poly, err := ApplyToPoints(geo.Polz, f)
+		if err != nil {
+			return nil, err
+		}
+		return PolygonZS{Srid: geo.Srid, Polz: poly.(PolygonZ)}, nil
+
+	case PolygonMS:
+		poly, err := ApplyToPoints(geo.Polm, f)
+		if err != nil {
+			return nil, err
+		}
+		return PolygonMS{Srid: geo.Srid, Polm: poly.(PolygonM)}, nil
+
+	case PolygonZMS:
+		poly, err := ApplyToPoints(geo.Polzm, f)
+		if err != nil {
+			return nil, err
+		}
+		return PolygonZMS{Srid: geo.Srid, Polzm: poly.(PolygonZM)}, nil
+
+	case Collection:
+		col := make(Collection, len(geo))
+		for i := range geo {
+			g, err := ApplyToPoints(geo[i], f)
+			if err != nil {
+				return nil, fmt.Errorf("got error converting geometry(%v) of collection: %v", i, err)
+			}
+			col[i] = g
+		}
+		return col, nil
+	}
+}
+
+// applyToCoords calls f with the given coordinates. Any trailing
+// dimensions (z, m) f does not return are copied from the input.
+func applyToCoords(f func(coords ...float64) ([]float64, error), coords []float64) ([]float64, error) {
+	c, err := f(append([]float64(nil), coords...)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(c) < 2 {
+		return nil, fmt.Errorf("function did not return minimum number of coordinates got %v expected 2", len(c))
+	}
+	if len(c) < len(coords) {
+		c = append(c[:len(c):len(c)], coords[len(c):]...)
+	}
+	return c, nil
+}
+
+func applyToPoints3(f func(coords ...float64) ([]float64, error), pts [][3]float64) ([][3]float64, error) {
+	npts := make([][3]float64, len(pts))
+	for i, pt := range pts {
+		c, err := applyToCoords(f, pt[:])
+		if err != nil {
+			return nil, err
+		}
+		copy(npts[i][:], c)
+	}
+	return npts, nil
+}
+
+func applyToPoints4(f func(coords ...float64) ([]float64, error), pts [][4]float64) ([][4]float64, error) {
+	npts := make([][4]float64, len(pts))
+	for i, pt := range pts {
+		c, err := applyToCoords(f, pt[:])
+		if err != nil {
+			return nil, err
+		}
+		copy(npts[i][:], c)
+	}
+	return npts, nil
+}
+
+func applyToLines3(f func(coords ...float64) ([]float64, error), lines [][][3]float64) ([][][3]float64, error) {
+	nlines := make([][][3]float64, len(lines))
+	for i := range lines {
+		line, err := applyToPoints3(f, lines[i])
+		if err != nil {
+			return nil, fmt.Errorf("got error converting line(%v): %v", i, err)
+		}
+		nlines[i] = line
+	}
+	return nlines, nil
+}
+
+func applyToLines4(f func(coords ...float64) ([]float64, error), lines [][][4]float64) ([][][4]float64, error) {
+	nlines := make([][][4]float64, len(lines))
+	for i := range lines {
+		line, err := applyToPoints4(f, lines[i])
+		if err != nil {
+			return nil, fmt.Errorf("got error converting line(%v): %v", i, err)
+		}
+		nlines[i] = line
 	}
+	return nlines, nil
 }
 
 // Clone returns a deep clone of the Geometry.
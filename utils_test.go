@@ -95,6 +95,31 @@ func TestApply(t *testing.T) {
 				return p, nil
 			},
 		},
+		"keep z": {
+			a: PointZ{1, 2, 3},
+			b: PointZ{2, 4, 3},
+			f: func(p ...float64) ([]float64, error) {
+				return []float64{p[0] * 2, p[1] * 2}, nil
+			},
+		},
+		"line stringzs": {
+			a: LineStringZS{Srid: 4326, Lsz: LineStringZ{{1, 1, 5}, {2, 2, 6}}},
+			b: LineStringZS{Srid: 4326, Lsz: LineStringZ{{2, 2, 6}, {3, 3, 7}}},
+			f: func(p ...float64) ([]float64, error) {
+				for i, v := range p {
+					p[i] = v + 1
+				}
+
+				return p, nil
+			},
+		},
+		"collection": {
+			a: Collection{Point{1, 1}, MultiPointM{{1, 1, 1}}},
+			b: Collection{Point{2, 2}, MultiPointM{{2, 2, 1}}},
+			f: func(p ...float64) ([]float64, error) {
+				return []float64{p[0] + 1, p[1] + 1}, nil
+			},
+		},
 	}
 
 	for k, v := range tcases {